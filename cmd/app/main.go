@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
+	_ "time/tzdata"
 
 	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
 	"insider-messaging/internal/infrastructure/cache"
 	db "insider-messaging/internal/infrastructure/db"
 	"insider-messaging/internal/infrastructure/scheduler"
@@ -39,34 +44,208 @@ func main() {
 		log.Fatalf("config load failed: %v", err)
 	}
 
+	// sağlık endpoint'leri veritabanı bağlantısı ve migration'lar sürerken de
+	// cevap verir; açılış bitene kadar /readyz 503 döner
+	readiness := application.NewReadiness()
+	health := application.NewHealthChecker(readiness, time.Duration(cfg.HealthCheckTimeoutSeconds)*time.Second)
+	startup := api.NewStartupHandler(health)
+	srv := api.NewServer(cfg, startup)
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil {
+			log.Printf("http server stopped: %v", err)
+		}
+	}()
+	log.Printf("server started on :%s", cfg.Port)
+
 	gormDB, err := db.NewMySQL(cfg)
 	if err != nil {
 		log.Fatalf("db init: %v", err)
 	}
+	health.Register(application.HealthComponent{Name: "database", Check: db.HealthCheck(gormDB), Critical: true})
 
 	redisClient := cache.NewRedis(cfg)
+	if cfg.RedisAddr != "" {
+		// Redis'siz de çalışılabildiği için kritik değildir
+		health.Register(application.HealthComponent{Name: "redis", Check: cache.HealthCheck(redisClient)})
+	}
 
-	msgRepo := db.NewMySQLMessageRepository(gormDB)
-	webSender := sender.NewWebhookSender(cfg)
-	sendBatchUC := application.NewSendBatchUseCase(msgRepo, webSender, redisClient, cfg)
-	sched := scheduler.NewScheduler(sendBatchUC, cfg)
-
-	router := api.NewRouter(sched, msgRepo, cfg)
-	srv := api.NewServer(cfg, router)
+	readiness.SetNotReady("migrating")
+	// outbox açıksa mesaj olayları değişiklikle aynı transaction'da yazılır
+	var repoOpts []db.RepositoryOption
+	if len(cfg.OutboxSinks) > 0 {
+		repoOpts = append(repoOpts, db.WithOutbox())
+	}
+	msgRepo := db.NewMySQLMessageRepository(gormDB, repoOpts...)
+	templateRepo := db.NewMySQLTemplateRepository(gormDB)
+	campaignRepo := db.NewMySQLCampaignRepository(gormDB, repoOpts...)
+	routerOpts := []api.HandlerOption{api.WithTemplates(templateRepo), api.WithCampaigns(campaignRepo)}
+	var trustedHosts []string
+	if cfg.LinkBaseURL != "" {
+		shortener := application.NewLinkShortener(cfg.LinkBaseURL, cfg.LinkCodeLength)
+		trustedHosts = append(trustedHosts, shortener.Host())
+		routerOpts = append(routerOpts, api.WithLinkTracking(shortener, db.NewMySQLLinkRepository(gormDB)))
+	}
+	contentFilter, err := application.NewContentFilter(db.NewMySQLContentPolicyRepository(gormDB), trustedHosts...)
+	if err != nil {
+		log.Fatalf("content policy init: %v", err)
+	}
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	go contentFilter.Run(reloadCtx, time.Duration(cfg.ContentPolicyReloadSeconds)*time.Second)
+	dispatchSettings, err := application.NewDispatchSettingsStore(db.NewMySQLDispatchSettingsRepository(gormDB), cfg)
+	if err != nil {
+		log.Fatalf("dispatch settings init: %v", err)
+	}
+	go dispatchSettings.Run(reloadCtx, time.Duration(cfg.DispatchSettingsReloadSeconds)*time.Second)
+	webhookSender, err := sender.NewWebhookSender(cfg)
+	if err != nil {
+		log.Fatalf("webhook sender init: %v", err)
+	}
+	var webSender application.SenderPort = webhookSender
+	routerOpts = append(routerOpts, api.WithContentPolicy(contentFilter), api.WithDispatchSettings(dispatchSettings))
+	if cfg.CircuitEnabled {
+		breaker := sender.NewCircuitBreakerSender(webSender, cfg)
+		webSender = breaker
+		routerOpts = append(routerOpts, api.WithCircuitBreaker(breaker))
+	}
+	webhookMonitor := application.NewWebhookMonitor(webSender, time.Duration(cfg.WebhookHealthWindowSeconds)*time.Second)
+	webSender = webhookMonitor
+	health.Register(application.HealthComponent{Name: "webhook", Check: webhookMonitor.Health})
+	quietHours, err := application.NewQuietHours(cfg)
+	if err != nil {
+		log.Fatalf("quiet hours init: %v", err)
+	}
+	ucOpts := []application.SendBatchOption{
+		application.WithQuietHours(quietHours),
+		application.WithContentPolicy(contentFilter),
+		application.WithDispatchSettings(dispatchSettings),
+	}
+	if redisClient != nil {
+		throttle := cache.NewRecipientThrottle(redisClient, cfg.RecipientLimits)
+		ucOpts = append(ucOpts, application.WithRecipientLimiter(throttle))
+		routerOpts = append(routerOpts, api.WithRecipientLimiter(throttle))
+	}
+	if cfg.BatchSizing == config.BatchSizingAdaptive {
+		ucOpts = append(ucOpts, application.WithAdaptiveBatchSizing(application.NewAdaptiveBatchSizer(msgRepo, cfg)))
+	}
+	if cfg.QueueBackend == config.QueueBackendRedis {
+		if redisClient == nil {
+			log.Printf("QUEUE_BACKEND=%s requires Redis, falling back to %s polling", config.QueueBackendRedis, config.QueueBackendMySQL)
+		} else {
+			queue, err := cache.NewRedisStreamQueue(context.Background(), redisClient, msgRepo, cfg.QueueStream,
+				cfg.QueueGroup, queueConsumer(), time.Duration(cfg.QueueClaimIdleSeconds)*time.Second)
+			if err != nil {
+				log.Fatalf("redis queue init: %v", err)
+			}
+			ucOpts = append(ucOpts, application.WithQueue(queue))
+		}
+	}
+	sendBatchUC := application.NewSendBatchUseCase(msgRepo, webSender, redisClient, cfg, ucOpts...)
+	schedOpts := []scheduler.Option{
+		scheduler.WithRunHistory(db.NewMySQLSchedulerRunRepository(gormDB), cfg.SchedulerRunHistory),
+		scheduler.WithJob(scheduler.JobRetention, 10*time.Minute, application.NewRetentionUseCase(msgRepo, cfg).Execute),
+	}
+	if redisClient != nil {
+		reconcile := application.NewCacheReconcileUseCase(msgRepo, redisClient, cfg)
+		schedOpts = append(schedOpts, scheduler.WithJob(scheduler.JobCacheReconcile, 5*time.Minute, reconcile.Execute))
+	}
+	if cfg.DispatchMode == config.DispatchModeStream {
+		// stream modunda yeni mesajlar dispatcher'ı hemen uyandırır, Redis varsa
+		// bildirim diğer instance'lara da yayılır
+		dispatchSignal := application.NewDispatchSignal()
+		var notifier application.MessageNotifier = dispatchSignal
+		if redisClient != nil {
+			redisNotifier := cache.NewRedisDispatchNotifier(redisClient, cfg.DispatchNotifyChannel, dispatchSignal)
+			go redisNotifier.Run(reloadCtx)
+			notifier = redisNotifier
+		}
+		schedOpts = append(schedOpts, scheduler.WithStream(dispatchSignal.C()))
+		routerOpts = append(routerOpts, api.WithNotifier(notifier))
+	}
+	sched, err := scheduler.NewScheduler(sendBatchUC, cfg, schedOpts...)
+	if err != nil {
+		log.Fatalf("scheduler init: %v", err)
+	}
+	// intervalSeconds sadece SCHEDULE_SECONDS'tan oluşan send-batch zamanlamasına
+	// uygulanır; SCHEDULES verildiyse cron ifadeleri korunur ve API aralığı reddeder
+	applyInterval := func(s entity.DispatchSettings) {
+		if err := sched.SetInterval(s.IntervalSeconds); err != nil && !errors.Is(err, scheduler.ErrNoIntervalSchedule) {
+			log.Printf("send interval not applied: %v", err)
+		}
+	}
+	applyInterval(dispatchSettings.Current())
+	dispatchSettings.OnChange(applyInterval)
 
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, os.Interrupt)
-	go func() {
-		if err := srv.ListenAndServe(); err != nil {
-			log.Printf("http server stopped: %v", err)
+	// scheduler durumu veritabanında tutulur ve restart sonrası geri yüklenir;
+	// lider seçimi kapalıyken bu instance her zaman liderdir
+	var lock application.LeaderLock
+	if cfg.LeaderElection {
+		lock = db.NewMySQLLeaderLock(gormDB, cfg.LeaderLockName)
+		if redisClient != nil {
+			lock = cache.NewRedisLeaderLock(redisClient, cfg.LeaderLockName)
 		}
+	}
+	schedulerState := db.NewMySQLSchedulerStateRepository(gormDB)
+	cluster := scheduler.NewCluster(sched, lock, schedulerState, time.Duration(cfg.LeaderLockTTLSeconds)*time.Second)
+	if err := cluster.Bootstrap(cfg.AutoStart); err != nil {
+		log.Fatalf("scheduler state init: %v", err)
+	}
+	clusterCtx, stopCluster := context.WithCancel(context.Background())
+	clusterDone := make(chan struct{})
+	go func() {
+		defer close(clusterDone)
+		cluster.Run(clusterCtx)
 	}()
-	log.Printf("server started on :%s", cfg.Port)
-	<-stop
-	log.Println("shutdown signal received")
-	sched.Stop()
+	health.Register(application.HealthComponent{Name: "scheduler", Check: cluster.Health, Liveness: true})
+	routerOpts = append(routerOpts, api.WithSchedulerAudit(schedulerState), api.WithHealth(health))
+
+	if len(cfg.OutboxSinks) > 0 {
+		var sinks []application.OutboxSink
+		for _, name := range cfg.OutboxSinks {
+			switch name {
+			case config.OutboxSinkLog:
+				sinks = append(sinks, application.LogOutboxSink{})
+			case config.OutboxSinkRedis:
+				if redisClient == nil {
+					log.Fatalf("OUTBOX_SINKS=%s requires Redis", config.OutboxSinkRedis)
+				}
+				sinks = append(sinks, cache.NewRedisStreamSink(redisClient, cfg.OutboxStream))
+			case config.OutboxSinkWebhook:
+				sinks = append(sinks, sender.NewWebhookOutboxSink(cfg))
+			}
+		}
+		// lider seçimi açıkken olayları sadece lider yayınlar
+		relay := application.NewOutboxRelay(db.NewMySQLOutboxRepository(gormDB), sinks, cfg, cluster.IsLeader)
+		health.Register(application.HealthComponent{Name: "outbox", Check: relay.Health})
+		go relay.Run(reloadCtx, time.Duration(cfg.OutboxPollSeconds)*time.Second)
+	}
+
+	startup.Serve(api.NewRouter(cluster, msgRepo, cfg, routerOpts...))
+	readiness.SetReady()
+	log.Println("startup complete, ready to serve")
+	sig := <-stop
+	log.Printf("shutdown signal received: %v", sig)
+	// önce readiness düşer ki load balancer yeni istek göndermesin; API boşaltma
+	// boyunca cevap vermeye devam eder
+	readiness.SetNotReady("draining")
+	// yeni batch başlatılmaz, süren batch DRAIN_TIMEOUT_SECONDS içinde bitirilir,
+	// sonra lider kilidi bırakılır ve diğer instance'lar ttl dolmadan devralır
+	stopCluster()
+	<-clusterDone
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	_ = srv.Shutdown(ctx)
 	log.Println("exited cleanly")
 }
+
+// queueConsumer Redis consumer group'unda bu süreci temsil eden isim; aynı
+// host'ta çalışan süreçler pid ile ayrışır
+func queueConsumer() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
@@ -0,0 +1,123 @@
+package application
+
+import (
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+)
+
+// BatchSizingManual batch boyutu elle tetiklenen çalışmada istekle verildiğinde kullanılır
+const BatchSizingManual = "manual"
+
+// AdaptiveBatchSizer her çalışmanın batch boyutunu gönderilmeye hazır mesaj sayısı,
+// son webhook gecikmeleri ve hata oranından belirler. Hedef, batch'in gönderim
+// aralığının AdaptiveBatchTarget kadarında bitmesidir: sağlayıcı yavaşladıkça veya
+// hata verdikçe batch küçülür, kuyruk büyüdükçe sınırlar içinde büyür.
+type AdaptiveBatchSizer struct {
+	repo   repository.MessageRepository
+	min    int
+	max    int
+	target float64
+
+	mu sync.Mutex
+	// window son gönderimlerin halkası, next sıradaki yazılacak indeks
+	window []sendSample
+	next   int
+	filled bool
+}
+
+type sendSample struct {
+	latency time.Duration
+	failed  bool
+}
+
+// BatchSizing bir çalışmanın batch boyutu ve hesaplamada kullanılan değerler
+type BatchSizing struct {
+	Size    int
+	Backlog int64
+	// LatencyMs ve ErrorRate son gönderimlerin ortalaması, örnek yoksa sıfırdır
+	LatencyMs int64
+	ErrorRate float64
+}
+
+// NewAdaptiveBatchSizer config'teki sınırlarla yeni bir sizer oluşturur
+func NewAdaptiveBatchSizer(repo repository.MessageRepository, cfg *config.Config) *AdaptiveBatchSizer {
+	return &AdaptiveBatchSizer{
+		repo:   repo,
+		min:    cfg.AdaptiveBatchMin,
+		max:    cfg.AdaptiveBatchMax,
+		target: cfg.AdaptiveBatchTarget,
+		window: make([]sendSample, cfg.AdaptiveBatchWindow),
+	}
+}
+
+// Observe bir webhook isteğinin süresini ve başarısız olup olmadığını kaydeder
+func (a *AdaptiveBatchSizer) Observe(latency time.Duration, failed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.window[a.next] = sendSample{latency: latency, failed: failed}
+	a.next = (a.next + 1) % len(a.window)
+	if a.next == 0 {
+		a.filled = true
+	}
+}
+
+// averages penceredeki ortalama gecikmeyi, hata oranını ve örnek sayısını döner
+func (a *AdaptiveBatchSizer) averages() (time.Duration, float64, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	n := a.next
+	if a.filled {
+		n = len(a.window)
+	}
+	if n == 0 {
+		return 0, 0, 0
+	}
+	var total time.Duration
+	failed := 0
+	for _, s := range a.window[:n] {
+		total += s.latency
+		if s.failed {
+			failed++
+		}
+	}
+	return total / time.Duration(n), float64(failed) / float64(n), n
+}
+
+// Size ayarlardaki aralık ve eşzamanlılığa göre batch boyutunu hesaplar. Henüz
+// gönderim örneği yoksa ayarlardaki batch boyutundan başlar; kuyruk sayısı
+// okunamazsa sadece kapasiteye göre karar verir.
+func (a *AdaptiveBatchSizer) Size(s entity.DispatchSettings) BatchSizing {
+	latency, errRate, samples := a.averages()
+	out := BatchSizing{LatencyMs: latency.Milliseconds(), ErrorRate: errRate}
+
+	capacity := float64(s.BatchSize)
+	if samples > 0 {
+		if latency < time.Millisecond {
+			latency = time.Millisecond
+		}
+		budget := time.Duration(float64(s.IntervalSeconds) * a.target * float64(time.Second))
+		capacity = float64(budget) / float64(latency) * float64(s.Concurrency) * (1 - errRate)
+	}
+	size := int(math.Min(capacity, float64(a.max)))
+
+	q, err := a.repo.QueueStats()
+	if err != nil {
+		log.Printf("adaptive batch: queue stats failed, sizing by capacity only: %v", err)
+	} else {
+		out.Backlog = q.Due
+		if q.Due < int64(size) {
+			size = int(q.Due)
+		}
+	}
+	if size < a.min {
+		size = a.min
+	}
+	out.Size = size
+	return out
+}
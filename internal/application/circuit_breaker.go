@@ -0,0 +1,36 @@
+package application
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrCircuitOpen circuit breaker açıkken gönderim denenmeden döner
+var ErrCircuitOpen = errors.New("webhook circuit breaker is open")
+
+// CircuitState circuit breaker'ın durumunu temsil eder
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"
+	CircuitOpen     CircuitState = "open"
+	CircuitHalfOpen CircuitState = "half-open"
+	CircuitDisabled CircuitState = "disabled"
+)
+
+// CircuitBreakerStatus circuit breaker'ın anlık durumu
+// @Description Webhook circuit breaker state
+type CircuitBreakerStatus struct {
+	State        CircuitState `json:"state" example:"closed"`
+	Requests     int          `json:"requests" example:"12"`
+	Failures     int          `json:"failures" example:"3"`
+	FailureRatio float64      `json:"failureRatio" example:"0.25"`
+	Threshold    float64      `json:"threshold" example:"0.5"`
+	OpenedAt     *time.Time   `json:"openedAt,omitempty" example:"2024-01-01T12:00:00Z"`
+	RetryAt      *time.Time   `json:"retryAt,omitempty" example:"2024-01-01T12:01:00Z"`
+}
+
+// CircuitBreakerInspector circuit breaker durumunu okumak için interface
+type CircuitBreakerInspector interface {
+	Status() CircuitBreakerStatus
+}
@@ -0,0 +1,260 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+)
+
+// ErrInvalidContentRules kurallar derlenemediğinde veya geçersiz olduğunda döner
+var ErrInvalidContentRules = errors.New("invalid content rules")
+
+// urlRegex mesaj içindeki http(s) ve www ile başlayan linkleri bulur
+var urlRegex = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"]+`)
+
+// PolicyViolation mesajın takıldığı içerik kuralı
+type PolicyViolation struct {
+	Rule   string
+	Reason string
+	Action entity.PolicyAction
+}
+
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("content policy %s: %s", v.Rule, v.Reason)
+}
+
+// ContentPolicy mesaj içeriğini kurallara göre kontrol eder, ihlal yoksa nil döner
+type ContentPolicy interface {
+	Check(m *entity.Message) *PolicyViolation
+}
+
+// ContentFilter veritabanında tutulan kuralları uygular. Kurallar API'den
+// güncellendiğinde hemen, diğer instance'larda Run ile periyodik olarak yenilenir.
+type ContentFilter struct {
+	repo repository.ContentPolicyRepository
+	// trusted bu servisin kısa link host'ları; bu linklerin yerine hedefleri kontrol edilir
+	trusted []string
+
+	mu       sync.RWMutex
+	rules    entity.ContentRules
+	compiled *compiledRules
+}
+
+type compiledRules struct {
+	keywords []string
+	patterns []*regexp.Regexp
+	domains  []string
+	maxURLs  int
+	optOut   string
+	action   entity.PolicyAction
+}
+
+var _ ContentPolicy = (*ContentFilter)(nil)
+
+// NewContentFilter kayıtlı kuralları yükler, kayıt yoksa boş kurallarla başlar.
+// trusted host'lara giden linkler kısa link kabul edilir, domain kontrolü
+// mesajın Links alanındaki hedef URL'lere uygulanır.
+func NewContentFilter(repo repository.ContentPolicyRepository, trusted ...string) (*ContentFilter, error) {
+	f := &ContentFilter{repo: repo}
+	for _, h := range trusted {
+		if h = strings.ToLower(strings.TrimSpace(h)); h != "" {
+			f.trusted = append(f.trusted, h)
+		}
+	}
+	rules, err := repo.Load()
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = &entity.ContentRules{Action: entity.PolicyReject}
+	}
+	c, err := compileRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	f.rules, f.compiled = *rules, c
+	return f, nil
+}
+
+// Rules geçerli kuralları döndürür
+func (f *ContentFilter) Rules() entity.ContentRules {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.rules
+}
+
+// Update kuralları doğrular, kaydeder ve hemen uygulamaya alır
+func (f *ContentFilter) Update(rules entity.ContentRules) (entity.ContentRules, error) {
+	if rules.Action == "" {
+		rules.Action = entity.PolicyReject
+	}
+	c, err := compileRules(&rules)
+	if err != nil {
+		return entity.ContentRules{}, err
+	}
+	if err := f.repo.Save(&rules); err != nil {
+		return entity.ContentRules{}, err
+	}
+	f.mu.Lock()
+	f.rules, f.compiled = rules, c
+	f.mu.Unlock()
+	return rules, nil
+}
+
+// Reload kayıtlı kuralların versiyonu değiştiyse onları uygular
+func (f *ContentFilter) Reload() error {
+	rules, err := f.repo.Load()
+	if err != nil || rules == nil {
+		return err
+	}
+	if rules.Version == f.Rules().Version {
+		return nil
+	}
+	c, err := compileRules(rules)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	f.rules, f.compiled = *rules, c
+	f.mu.Unlock()
+	log.Printf("content policy reloaded, version=%d", rules.Version)
+	return nil
+}
+
+// Run context kapanana kadar kuralları every aralıkla yeniden yükler
+func (f *ContentFilter) Run(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		return
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := f.Reload(); err != nil {
+				log.Printf("content policy reload failed: %v", err)
+			}
+		}
+	}
+}
+
+// Check mesajı sırasıyla yasaklı kelime, desen, link sayısı, link domain'i
+// ve opt-out metni kurallarına göre kontrol eder
+func (f *ContentFilter) Check(m *entity.Message) *PolicyViolation {
+	f.mu.RLock()
+	c := f.compiled
+	f.mu.RUnlock()
+
+	violation := func(rule, reason string) *PolicyViolation {
+		return &PolicyViolation{Rule: rule, Reason: reason, Action: c.action}
+	}
+	lower := strings.ToLower(m.Content)
+	for _, k := range c.keywords {
+		if strings.Contains(lower, k) {
+			return violation("blocked_keyword", fmt.Sprintf("contains blocked keyword %q", k))
+		}
+	}
+	for _, p := range c.patterns {
+		if p.MatchString(m.Content) {
+			return violation("blocked_pattern", fmt.Sprintf("matches blocked pattern %q", p.String()))
+		}
+	}
+	urls := urlRegex.FindAllString(m.Content, -1)
+	if c.maxURLs > 0 && len(urls) > c.maxURLs {
+		return violation("max_urls", fmt.Sprintf("has %d links, limit is %d", len(urls), c.maxURLs))
+	}
+	if len(c.domains) > 0 {
+		hosts := make([]string, 0, len(urls)+len(m.Links))
+		for _, u := range urls {
+			if host := urlHost(u); !domainAllowed(host, f.trusted) {
+				hosts = append(hosts, host)
+			}
+		}
+		for _, l := range m.Links {
+			hosts = append(hosts, urlHost(l.TargetURL))
+		}
+		for _, host := range hosts {
+			if !domainAllowed(host, c.domains) {
+				return violation("url_domain", fmt.Sprintf("link domain %q is not allowed", host))
+			}
+		}
+	}
+	if c.optOut != "" && m.Category == entity.CategoryMarketing && !strings.Contains(lower, c.optOut) {
+		return violation("opt_out", "marketing message is missing the opt-out text")
+	}
+	return nil
+}
+
+// compileRules kuralları normalize edip derler
+func compileRules(r *entity.ContentRules) (*compiledRules, error) {
+	switch r.Action {
+	case entity.PolicyReject, entity.PolicyQuarantine:
+	default:
+		return nil, fmt.Errorf("%w: action must be reject or quarantine", ErrInvalidContentRules)
+	}
+	if r.MaxURLs < 0 {
+		return nil, fmt.Errorf("%w: maxUrls cannot be negative", ErrInvalidContentRules)
+	}
+	c := &compiledRules{maxURLs: r.MaxURLs, action: r.Action, optOut: strings.ToLower(strings.TrimSpace(r.OptOutText))}
+	for _, k := range r.BlockedKeywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			c.keywords = append(c.keywords, k)
+		}
+	}
+	for _, p := range r.BlockedPatterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("%w: pattern %q: %v", ErrInvalidContentRules, p, err)
+		}
+		c.patterns = append(c.patterns, re)
+	}
+	for _, d := range r.AllowedDomains {
+		if d = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(d)), "."); d != "" {
+			c.domains = append(c.domains, d)
+		}
+	}
+	return c, nil
+}
+
+// urlHost linkin host kısmını küçük harfle döndürür
+func urlHost(raw string) string {
+	raw = strings.TrimRight(raw, ".,;:!?)")
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// domainAllowed host'un izinli domain'lerden biri veya alt domain'i olup olmadığını döndürür
+func domainAllowed(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentPolicyManager kuralların API üzerinden okunup değiştirilebildiği politika
+type ContentPolicyManager interface {
+	ContentPolicy
+	Rules() entity.ContentRules
+	Update(rules entity.ContentRules) (entity.ContentRules, error)
+}
+
+var _ ContentPolicyManager = (*ContentFilter)(nil)
@@ -0,0 +1,150 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+)
+
+// ErrInvalidDispatchSettings ayarlar sınırların dışındaysa döner
+var ErrInvalidDispatchSettings = errors.New("invalid dispatch settings")
+
+// DispatchSettingsPatch ayarlarda değiştirilecek alanlar, nil alanlar korunur
+type DispatchSettingsPatch struct {
+	IntervalSeconds *int `json:"intervalSeconds,omitempty" example:"60"`
+	BatchSize       *int `json:"batchSize,omitempty" example:"50"`
+	Concurrency     *int `json:"concurrency,omitempty" example:"4"`
+}
+
+// DispatchSettingsManager gönderim ayarlarının API üzerinden okunup değiştirilebildiği kaynak
+type DispatchSettingsManager interface {
+	Current() entity.DispatchSettings
+	Update(p DispatchSettingsPatch) (entity.DispatchSettings, error)
+}
+
+// DispatchSettingsStore veritabanında tutulan gönderim ayarlarını sağlar. Ayarlar
+// API'den güncellendiğinde hemen, diğer instance'larda Run ile periyodik olarak
+// yenilenir; her değişiklikte OnChange ile eklenen fonksiyonlar çağrılır.
+type DispatchSettingsStore struct {
+	repo repository.DispatchSettingsRepository
+
+	mu        sync.RWMutex
+	current   entity.DispatchSettings
+	listeners []func(entity.DispatchSettings)
+	// updateMu güncellemelerin kayıt ve uygulama sırasını korur
+	updateMu sync.Mutex
+}
+
+var _ DispatchSettingsManager = (*DispatchSettingsStore)(nil)
+
+// NewDispatchSettingsStore kayıtlı ayarları yükler. Kayıt yoksa SCHEDULE_SECONDS,
+// MSG_PER_TICK ve SEND_CONCURRENCY değerleriyle başlar; kayıtlı ayarlar env'e göre önceliklidir.
+func NewDispatchSettingsStore(repo repository.DispatchSettingsRepository, cfg *config.Config) (*DispatchSettingsStore, error) {
+	s, err := repo.Load()
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = &entity.DispatchSettings{IntervalSeconds: cfg.ScheduleSec, BatchSize: cfg.MsgPerTick, Concurrency: cfg.SendConcurrency}
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDispatchSettings, err)
+	}
+	return &DispatchSettingsStore{repo: repo, current: *s}, nil
+}
+
+// Current geçerli ayarları döndürür
+func (st *DispatchSettingsStore) Current() entity.DispatchSettings {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	return st.current
+}
+
+// OnChange ayarlar değiştiğinde çağrılacak fonksiyonu ekler
+func (st *DispatchSettingsStore) OnChange(fn func(entity.DispatchSettings)) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.listeners = append(st.listeners, fn)
+}
+
+// Update verilen alanları geçerli ayarların üzerine yazar, doğrular, kaydeder ve uygular
+func (st *DispatchSettingsStore) Update(p DispatchSettingsPatch) (entity.DispatchSettings, error) {
+	st.updateMu.Lock()
+	defer st.updateMu.Unlock()
+
+	next := st.Current()
+	if p.IntervalSeconds != nil {
+		next.IntervalSeconds = *p.IntervalSeconds
+	}
+	if p.BatchSize != nil {
+		next.BatchSize = *p.BatchSize
+	}
+	if p.Concurrency != nil {
+		next.Concurrency = *p.Concurrency
+	}
+	if err := next.Validate(); err != nil {
+		return entity.DispatchSettings{}, fmt.Errorf("%w: %v", ErrInvalidDispatchSettings, err)
+	}
+	if err := st.repo.Save(&next); err != nil {
+		return entity.DispatchSettings{}, err
+	}
+	st.apply(next)
+	return next, nil
+}
+
+// Reload kayıtlı ayarların versiyonu değiştiyse onları uygular
+func (st *DispatchSettingsStore) Reload() error {
+	st.updateMu.Lock()
+	defer st.updateMu.Unlock()
+
+	s, err := st.repo.Load()
+	if err != nil || s == nil {
+		return err
+	}
+	if s.Version == st.Current().Version {
+		return nil
+	}
+	if err := s.Validate(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDispatchSettings, err)
+	}
+	st.apply(*s)
+	log.Printf("dispatch settings reloaded, version=%d", s.Version)
+	return nil
+}
+
+// apply ayarları tek seferde değiştirir ve dinleyicilere bildirir
+func (st *DispatchSettingsStore) apply(s entity.DispatchSettings) {
+	st.mu.Lock()
+	st.current = s
+	listeners := append([]func(entity.DispatchSettings){}, st.listeners...)
+	st.mu.Unlock()
+	for _, fn := range listeners {
+		fn(s)
+	}
+}
+
+// Run context kapanana kadar ayarları every aralıkla yeniden yükler
+func (st *DispatchSettingsStore) Run(ctx context.Context, every time.Duration) {
+	if every <= 0 {
+		return
+	}
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := st.Reload(); err != nil {
+				log.Printf("dispatch settings reload failed: %v", err)
+			}
+		}
+	}
+}
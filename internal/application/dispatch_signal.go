@@ -0,0 +1,32 @@
+package application
+
+// MessageNotifier gönderilmeye hazır yeni mesajlar olduğunu dispatcher'a haber verir
+type MessageNotifier interface {
+	Notify()
+}
+
+// DispatchSignal aynı süreçteki dispatcher'ı uyandırır. Dispatcher meşgulken gelen
+// bildirimler tek bir uyandırmada birleşir, Notify hiç bloklamaz.
+type DispatchSignal struct {
+	ch chan struct{}
+}
+
+var _ MessageNotifier = (*DispatchSignal)(nil)
+
+// NewDispatchSignal yeni bir sinyal oluşturur
+func NewDispatchSignal() *DispatchSignal {
+	return &DispatchSignal{ch: make(chan struct{}, 1)}
+}
+
+// Notify dispatcher'ı uyandırır
+func (s *DispatchSignal) Notify() {
+	select {
+	case s.ch <- struct{}{}:
+	default:
+	}
+}
+
+// C dispatcher'ın beklediği kanal
+func (s *DispatchSignal) C() <-chan struct{} {
+	return s.ch
+}
@@ -0,0 +1,148 @@
+package application
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// HealthState bir bileşenin veya instance'ın sağlık durumu
+type HealthState string
+
+const (
+	HealthUp       HealthState = "up"
+	HealthDegraded HealthState = "degraded"
+	HealthDown     HealthState = "down"
+)
+
+// ComponentHealth bir bağımlılığın kontrol sonucu
+// @Description Health of a single dependency
+type ComponentHealth struct {
+	Status HealthState `json:"status" example:"up"`
+	// Critical down olursa instance hazır sayılmaz, değilse sadece degraded olur
+	Critical  bool   `json:"critical" example:"true"`
+	Message   string `json:"message,omitempty" example:"dial tcp 10.0.0.5:3306: connect: connection refused"`
+	LatencyMs int64  `json:"latencyMs" example:"3"`
+}
+
+// HealthReport /livez ve /readyz cevabı
+// @Description Overall health with a per-component breakdown
+type HealthReport struct {
+	Status HealthState `json:"status" example:"degraded"`
+	// Reason instance açılışta veya kapanışta olduğu için hazır değilse sebebi
+	Reason     string                     `json:"reason,omitempty" example:"draining"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// HealthCheck bir bileşeni kontrol eder, ctx kontrol zaman aşımıyla sınırlıdır
+type HealthCheck func(ctx context.Context) ComponentHealth
+
+// HealthComponent kontrol edilecek bir bağımlılık
+type HealthComponent struct {
+	Name  string
+	Check HealthCheck
+	// Critical down olduğunda readiness başarısız olur
+	Critical bool
+	// Liveness true ise /livez'de de kontrol edilir; down olursa süreç yeniden başlatılmalıdır
+	Liveness bool
+}
+
+// HealthChecker bileşen kontrollerini paralel çalıştırıp liveness ve readiness
+// raporu üretir. Bileşenler açılış sırasında hazır oldukça eklenir.
+type HealthChecker struct {
+	readiness *Readiness
+	timeout   time.Duration
+
+	mu         sync.RWMutex
+	components []HealthComponent
+}
+
+// NewHealthChecker her kontrolü timeout ile sınırlayan bir checker oluşturur
+func NewHealthChecker(readiness *Readiness, timeout time.Duration) *HealthChecker {
+	return &HealthChecker{readiness: readiness, timeout: timeout}
+}
+
+// Register kontrol edilecek bir bileşen ekler
+func (c *HealthChecker) Register(comp HealthComponent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.components = append(c.components, comp)
+}
+
+// Live sadece liveness bileşenlerini kontrol eder. Veritabanı gibi dış
+// bağımlılıkların kesintisi sürecin yeniden başlatılmasını gerektirmez.
+func (c *HealthChecker) Live(ctx context.Context) HealthReport {
+	report := c.run(ctx, true)
+	for _, h := range report.Components {
+		if h.Status == HealthDown {
+			report.Status = HealthDown
+		}
+	}
+	return report
+}
+
+// Ready bütün bileşenleri kontrol eder. Instance açılışta veya kapanışta ise
+// ya da kritik bir bileşen down ise down, kritik olmayan bir bileşen sorunluysa
+// degraded döner.
+func (c *HealthChecker) Ready(ctx context.Context) HealthReport {
+	report := c.run(ctx, false)
+	for _, h := range report.Components {
+		switch {
+		case h.Status == HealthDown && h.Critical:
+			report.Status = HealthDown
+		case h.Status != HealthUp && report.Status == HealthUp:
+			report.Status = HealthDegraded
+		}
+	}
+	if ready, reason := c.readiness.State(); !ready {
+		report.Status, report.Reason = HealthDown, reason
+	}
+	return report
+}
+
+// run bileşenleri paralel kontrol eder; zaman aşımını geçen kontrol down sayılır
+func (c *HealthChecker) run(ctx context.Context, livenessOnly bool) HealthReport {
+	c.mu.RLock()
+	components := make([]HealthComponent, 0, len(c.components))
+	for _, comp := range c.components {
+		if !livenessOnly || comp.Liveness {
+			components = append(components, comp)
+		}
+	}
+	c.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	results := make([]ComponentHealth, len(components))
+	var wg sync.WaitGroup
+	for i, comp := range components {
+		wg.Add(1)
+		go func(i int, comp HealthComponent) {
+			defer wg.Done()
+			results[i] = c.check(ctx, comp)
+		}(i, comp)
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: HealthUp, Components: make(map[string]ComponentHealth, len(components))}
+	for i, comp := range components {
+		report.Components[comp.Name] = results[i]
+	}
+	return report
+}
+
+// check tek bir kontrolü zaman aşımıyla çalıştırır
+func (c *HealthChecker) check(ctx context.Context, comp HealthComponent) ComponentHealth {
+	start := time.Now()
+	done := make(chan ComponentHealth, 1)
+	go func() { done <- comp.Check(ctx) }()
+	var h ComponentHealth
+	select {
+	case h = <-done:
+	case <-ctx.Done():
+		h = ComponentHealth{Status: HealthDown, Message: "check timed out after " + c.timeout.String()}
+	}
+	h.Critical = comp.Critical
+	h.LatencyMs = time.Since(start).Milliseconds()
+	return h
+}
@@ -0,0 +1,20 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotLeader lider seçimi açıkken sadece liderin yapabileceği bir işlem
+// başka bir instance'a geldiğinde döner
+var ErrNotLeader = errors.New("this instance is not the scheduler leader")
+
+// LeaderLock instance'lar arasından scheduler'ı çalıştıracak tek bir lider seçer
+type LeaderLock interface {
+	// Acquire kilit boştaysa owner adına ttl süreyle alır, zaten owner'daysa süresini
+	// uzatır. Kilit başkasındaysa false döner.
+	Acquire(ctx context.Context, owner string, ttl time.Duration) (bool, error)
+	// Release kilit owner'daysa bırakır
+	Release(ctx context.Context, owner string) error
+}
@@ -0,0 +1,80 @@
+package application
+
+import (
+	"crypto/rand"
+	"math/big"
+	"net/url"
+	"strings"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+const linkCodeAlphabet = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// LinkShortener mesajdaki linkleri bu servisin takipli kısa linkleriyle değiştirir
+type LinkShortener struct {
+	baseURL string
+	codeLen int
+}
+
+// NewLinkShortener kısa linkleri baseURL/l/{code} formatında üreten shortener oluşturur
+func NewLinkShortener(baseURL string, codeLen int) *LinkShortener {
+	if codeLen <= 0 {
+		codeLen = 7
+	}
+	return &LinkShortener{baseURL: strings.TrimRight(baseURL, "/"), codeLen: codeLen}
+}
+
+// Host kısa linklerin host'unu döndürür, içerik politikasında güvenilir kabul edilir
+func (s *LinkShortener) Host() string {
+	u, err := url.Parse(s.baseURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// Shorten içerikteki her linki yeni bir kısa linkle değiştirir. Mesaj ID'si
+// kayıt sırasında atanır, dönen linkler mesajın Links alanına konmalıdır.
+func (s *LinkShortener) Shorten(content string) (string, []entity.Link, error) {
+	var links []entity.Link
+	var genErr error
+	out := urlRegex.ReplaceAllStringFunc(content, func(match string) string {
+		if genErr != nil {
+			return match
+		}
+		target := strings.TrimRight(match, ".,;:!?)")
+		suffix := match[len(target):]
+		if urlHost(target) == s.Host() {
+			return match
+		}
+		code, err := s.code()
+		if err != nil {
+			genErr = err
+			return match
+		}
+		if !strings.Contains(target, "://") {
+			target = "https://" + target
+		}
+		links = append(links, entity.Link{Code: code, TargetURL: target})
+		return s.baseURL + "/l/" + code + suffix
+	})
+	if genErr != nil {
+		return "", nil, genErr
+	}
+	return out, links, nil
+}
+
+// code rastgele, karışması zor karakterlerden oluşan bir kod üretir
+func (s *LinkShortener) code() (string, error) {
+	b := make([]byte, s.codeLen)
+	max := big.NewInt(int64(len(linkCodeAlphabet)))
+	for i := range b {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = linkCodeAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
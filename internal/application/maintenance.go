@@ -0,0 +1,96 @@
+package application
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RetentionUseCase saklama süresi dolan tamamlanmış mesajları siler
+type RetentionUseCase struct {
+	repo repository.MessageRepository
+	keep time.Duration
+}
+
+// NewRetentionUseCase MESSAGE_RETENTION_DAYS süresiyle yeni bir retention işi oluşturur
+func NewRetentionUseCase(r repository.MessageRepository, cfg *config.Config) *RetentionUseCase {
+	return &RetentionUseCase{repo: r, keep: time.Duration(cfg.RetentionDays) * 24 * time.Hour}
+}
+
+// Execute saklama süresinden eski mesajları siler
+func (uc *RetentionUseCase) Execute(ctx context.Context) error {
+	n, err := uc.repo.PurgeBefore(time.Now().Add(-uc.keep))
+	if n > 0 {
+		log.Printf("retention purged %d messages", n)
+	}
+	return err
+}
+
+// CacheReconcileUseCase son gönderilen mesajların Redis kayıtlarını veritabanıyla
+// eşitler. Gönderim sırasında Redis yazımı hata verirse kayıt burada tamamlanır.
+type CacheReconcileUseCase struct {
+	repo   repository.MessageRepository
+	redis  *redis.Client
+	window time.Duration
+}
+
+// NewCacheReconcileUseCase CACHE_RECONCILE_WINDOW_HOURS penceresiyle yeni bir iş oluşturur
+func NewCacheReconcileUseCase(r repository.MessageRepository, rdb *redis.Client, cfg *config.Config) *CacheReconcileUseCase {
+	return &CacheReconcileUseCase{repo: r, redis: rdb, window: time.Duration(cfg.CacheReconcileHours) * time.Hour}
+}
+
+// Execute pencere içinde gönderilmiş ama Redis'te kaydı olmayan mesajları yazar
+func (uc *CacheReconcileUseCase) Execute(ctx context.Context) error {
+	msgs, err := uc.repo.SentSince(time.Now().Add(-uc.window))
+	if err != nil || len(msgs) == 0 {
+		return err
+	}
+
+	pipe := uc.redis.Pipeline()
+	exists := make([]*redis.IntCmd, len(msgs))
+	for i, m := range msgs {
+		exists[i] = pipe.Exists(ctx, messageCacheKey(m.ID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	fixed := 0
+	for i, m := range msgs {
+		if exists[i].Val() > 0 {
+			continue
+		}
+		sentAt := time.Now()
+		if m.SentAt != nil {
+			sentAt = *m.SentAt
+		}
+		if err := cacheSent(ctx, uc.redis, m.ID, m.WebhookMsgID, m.WebhookMsgIDSource, sentAt); err != nil {
+			return err
+		}
+		fixed++
+	}
+	if fixed > 0 {
+		log.Printf("cache reconcile restored %d message entries", fixed)
+	}
+	return nil
+}
+
+func messageCacheKey(id uint) string {
+	return "message:" + strconv.FormatUint(uint64(id), 10)
+}
+
+// cacheSent gönderilen mesajın webhook kimliğini Redis'e yazar
+func cacheSent(ctx context.Context, rdb *redis.Client, id uint, webhookID string, source entity.MessageIDSource, sentAt time.Time) error {
+	return rdb.HSet(ctx, messageCacheKey(id), map[string]interface{}{
+		"webhook_id":        webhookID,
+		"webhook_id_source": string(source),
+		"sent_at":           sentAt.UTC().Format(time.RFC3339),
+	}).Err()
+}
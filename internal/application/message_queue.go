@@ -0,0 +1,40 @@
+package application
+
+import (
+	"context"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+)
+
+// MessageQueue batch'lerin gönderilecek mesajları aldığı iş kuyruğu. Mesajın
+// durumu her zaman veritabanındadır; kuyruk sadece hangi mesajın kim tarafından
+// işlendiğini belirler.
+type MessageQueue interface {
+	// Claim gönderilmeye hazır en fazla limit mesajı işlenmek üzere alır
+	Claim(ctx context.Context, limit int) ([]*entity.Message, error)
+	// Ack mesajın işlenmesinin bittiğini bildirir. Mesaj gönderilmediyse
+	// (ertelendi, batch yarıda kesildi) veritabanındaki durumuna göre tekrar alınır.
+	Ack(ctx context.Context, id uint) error
+}
+
+// PollingQueue her Claim'de veritabanını sorgular. Mesaj gönderilene veya
+// ertelenene kadar sonraki sorgularda tekrar döndüğü için Ack bir şey yapmaz.
+type PollingQueue struct {
+	repo repository.MessageRepository
+}
+
+// NewPollingQueue repository'yi sorgulayan bir kuyruk oluşturur
+func NewPollingQueue(repo repository.MessageRepository) *PollingQueue {
+	return &PollingQueue{repo: repo}
+}
+
+// Claim gönderilmemiş ve zamanı gelmiş mesajları döner
+func (q *PollingQueue) Claim(ctx context.Context, limit int) ([]*entity.Message, error) {
+	return q.repo.GetUnsent(limit)
+}
+
+// Ack veritabanı tek kaynak olduğu için bir şey yapmaz
+func (q *PollingQueue) Ack(ctx context.Context, id uint) error {
+	return nil
+}
@@ -0,0 +1,175 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+)
+
+// outboxPurgeEvery yayınlanmış olayların temizlenme aralığı
+const outboxPurgeEvery = 10 * time.Minute
+
+// defaultOutboxMaxAttempts OUTBOX_MAX_ATTEMPTS verilmediğinde kullanılır
+const defaultOutboxMaxAttempts = 10
+
+// OutboxSink outbox olaylarının yayınlandığı hedef
+type OutboxSink interface {
+	Name() string
+	Publish(ctx context.Context, e *entity.OutboxEvent) error
+}
+
+// OutboxRelay outbox'taki olayları yazılma sırasıyla sink'lere yayınlar.
+// Olay bütün sink'lere yayınlandıktan sonra işaretlenir; arada çökme veya bir
+// sink'in hatası olayın tekrar yayınlanmasına yol açabilir (en az bir kez).
+// OUTBOX_MAX_ATTEMPTS denemede yayınlanamayan olay dead-letter'a ayrılır.
+type OutboxRelay struct {
+	repo        repository.OutboxRepository
+	sinks       []OutboxSink
+	batch       int
+	keep        time.Duration
+	maxAttempts int
+	// active false dönerse bu instance olay yayınlamaz; lider seçimi açıkken
+	// sadece lider yayınlar
+	active    func() bool
+	lastPurge time.Time
+}
+
+// NewOutboxRelay OUTBOX_* ayarlarıyla yeni bir relay oluşturur. active nil ise
+// relay her zaman çalışır.
+func NewOutboxRelay(repo repository.OutboxRepository, sinks []OutboxSink, cfg *config.Config, active func() bool) *OutboxRelay {
+	if active == nil {
+		active = func() bool { return true }
+	}
+	maxAttempts := cfg.OutboxMaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultOutboxMaxAttempts
+	}
+	return &OutboxRelay{
+		repo: repo, sinks: sinks, batch: cfg.OutboxBatchSize,
+		keep: time.Duration(cfg.OutboxRetentionHours) * time.Hour, maxAttempts: maxAttempts, active: active,
+	}
+}
+
+// Run ctx kapanana kadar every aralıkla bekleyen olayları yayınlar ve eski
+// yayınlanmış olayları siler
+func (r *OutboxRelay) Run(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if !r.active() {
+				continue
+			}
+			r.drain(ctx)
+			r.purge()
+		}
+	}
+}
+
+// drain dolu batch'ler döndükçe yayına devam eder
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.Relay(ctx)
+		if err != nil {
+			log.Printf("outbox relay: %v", err)
+			return
+		}
+		if n < r.batch {
+			return
+		}
+	}
+}
+
+// purge saklama süresi dolan yayınlanmış olayları siler
+func (r *OutboxRelay) purge() {
+	if time.Since(r.lastPurge) < outboxPurgeEvery {
+		return
+	}
+	r.lastPurge = time.Now()
+	n, err := r.repo.PurgePublished(time.Now().Add(-r.keep))
+	if err != nil {
+		log.Printf("outbox purge failed: %v", err)
+	}
+	if n > 0 {
+		log.Printf("outbox purged %d published events", n)
+	}
+}
+
+// Relay bekleyen olayları yayınlar ve yayınlanan olay sayısını döner. Bir olay
+// herhangi bir sink'e yayınlanamazsa sıra bozulmasın diye durulur; olay bir
+// sonraki turda bütün sink'lere tekrar gönderilir. Deneme hakkı biten olay
+// sonraki olayları sonsuza kadar bekletmesin diye dead-letter'a ayrılıp atlanır.
+func (r *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	events, err := r.repo.Pending(r.batch)
+	if err != nil {
+		return 0, err
+	}
+	published := make([]uint, 0, len(events))
+	var failure error
+	for _, e := range events {
+		if err := r.publish(ctx, e); err != nil {
+			attempt := e.Attempts + 1
+			// kapanışta yarıda kalan yayın olayın hatası değildir
+			if attempt >= r.maxAttempts && ctx.Err() == nil {
+				if err := r.repo.MarkDead(e.ID, err.Error()); err != nil {
+					failure = fmt.Errorf("event id=%d dead-letter: %w", e.ID, err)
+					break
+				}
+				log.Printf("outbox event dead-lettered id=%d type=%s attempts=%d err=%v", e.ID, e.Type, attempt, err)
+				continue
+			}
+			failure = fmt.Errorf("event id=%d attempt=%d: %w", e.ID, attempt, err)
+			if err := r.repo.MarkFailed(e.ID, err.Error()); err != nil {
+				log.Printf("outbox mark failed failed id=%d err=%v", e.ID, err)
+			}
+			break
+		}
+		published = append(published, e.ID)
+	}
+	if err := r.repo.MarkPublished(published); err != nil {
+		return 0, err
+	}
+	return len(published), failure
+}
+
+// Health dead-letter'a ayrılmış olay varsa degraded döner
+func (r *OutboxRelay) Health(ctx context.Context) ComponentHealth {
+	n, err := r.repo.DeadCount()
+	switch {
+	case err != nil:
+		return ComponentHealth{Status: HealthDegraded, Message: "dead-letter count failed: " + err.Error()}
+	case n > 0:
+		return ComponentHealth{Status: HealthDegraded, Message: fmt.Sprintf("%d outbox events dead-lettered after %d attempts", n, r.maxAttempts)}
+	}
+	return ComponentHealth{Status: HealthUp}
+}
+
+// publish olayı bütün sink'lere sırayla yayınlar
+func (r *OutboxRelay) publish(ctx context.Context, e *entity.OutboxEvent) error {
+	for _, s := range r.sinks {
+		if err := s.Publish(ctx, e); err != nil {
+			return fmt.Errorf("%s sink: %w", s.Name(), err)
+		}
+	}
+	return nil
+}
+
+// LogOutboxSink olayları uygulama loguna yazar
+type LogOutboxSink struct{}
+
+// Name sink adı
+func (LogOutboxSink) Name() string { return config.OutboxSinkLog }
+
+// Publish olayı loglar
+func (LogOutboxSink) Publish(ctx context.Context, e *entity.OutboxEvent) error {
+	log.Printf("outbox event id=%d type=%s message=%d payload=%s", e.ID, e.Type, e.MessageID, e.Payload)
+	return nil
+}
@@ -0,0 +1,132 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/phone"
+)
+
+// QuietHours sessiz saat kurallarını uygular. Kural önceliği: mesaj kategorisi,
+// sonra mesaj önceliği, sonra global kural. High priority ve transactional
+// mesajlar kurallardan muaftır.
+type QuietHours struct {
+	global     *config.QuietWindow
+	byPriority map[string]config.QuietWindow
+	byCategory map[string]config.QuietWindow
+	defaultLoc []*time.Location
+	// zones bölge koduna göre alıcının saat dilimleri. Birden fazla dilime
+	// yayılan ülkelerde sessiz saat tüm dilimlerde bitene kadar beklenir.
+	zones map[string][]*time.Location
+}
+
+// NewQuietHours config'ten sessiz saat kurallarını oluşturur
+func NewQuietHours(cfg *config.Config) (*QuietHours, error) {
+	def, err := time.LoadLocation(cfg.QuietHoursDefaultTZ)
+	if err != nil {
+		return nil, fmt.Errorf("quiet hours default time zone: %w", err)
+	}
+	q := &QuietHours{
+		global:     cfg.QuietHours,
+		byPriority: cfg.QuietHoursByPriority,
+		byCategory: cfg.QuietHoursByCategory,
+		defaultLoc: []*time.Location{def},
+		zones:      make(map[string][]*time.Location),
+	}
+	for _, region := range phone.Regions() {
+		for _, name := range phone.TimeZones(region) {
+			loc, err := time.LoadLocation(name)
+			if err != nil {
+				return nil, fmt.Errorf("quiet hours time zone %s: %w", name, err)
+			}
+			q.zones[region] = append(q.zones[region], loc)
+		}
+	}
+	return q, nil
+}
+
+// Check mesajın now anında gönderilip gönderilemeyeceğini döndürür.
+// Gönderilemiyorsa sessiz saatin alıcı için bittiği zamanı da döndürür.
+func (q *QuietHours) Check(m *entity.Message, now time.Time) (bool, time.Time) {
+	w := q.window(m)
+	if w == nil || w.Start == w.End {
+		return true, time.Time{}
+	}
+	locs := q.locations(m)
+	if !quietInAny(*w, locs, now) {
+		return true, time.Time{}
+	}
+
+	// sessiz saati en geç biten dilimden başlayarak tüm dilimlerde açık olan ilk anı ara
+	next := now
+	for _, loc := range locs {
+		local := now.In(loc)
+		if !inWindow(*w, local) {
+			continue
+		}
+		if end := windowEnd(*w, local); end.After(next) {
+			next = end
+		}
+	}
+	for i := 0; i < 4*48 && quietInAny(*w, locs, next); i++ {
+		next = next.Add(15 * time.Minute)
+	}
+	return false, next
+}
+
+// window mesaja uygulanacak sessiz saat aralığını seçer, muafsa nil döner
+func (q *QuietHours) window(m *entity.Message) *config.QuietWindow {
+	if m.Priority == entity.PriorityHigh || m.Category == entity.CategoryTransactional {
+		return nil
+	}
+	if w, ok := q.byCategory[string(m.Category)]; ok {
+		return &w
+	}
+	if w, ok := q.byPriority[string(m.Priority)]; ok {
+		return &w
+	}
+	return q.global
+}
+
+// locations mesajın ülkesinden saat dilimlerini bulur. Ülkesi kaydedilmemiş eski
+// mesajlarda ülke numaranın ülke kodundan çıkarılır, bulunamazsa varsayılan döner.
+func (q *QuietHours) locations(m *entity.Message) []*time.Location {
+	region := m.Country
+	if region == "" {
+		region = phone.RegionOf(m.To)
+	}
+	if locs, ok := q.zones[region]; ok {
+		return locs
+	}
+	return q.defaultLoc
+}
+
+// quietInAny t anında dilimlerden herhangi birinde sessiz saat olup olmadığını döndürür
+func quietInAny(w config.QuietWindow, locs []*time.Location, t time.Time) bool {
+	for _, loc := range locs {
+		if inWindow(w, t.In(loc)) {
+			return true
+		}
+	}
+	return false
+}
+
+// inWindow yerel saatin aralık içinde olup olmadığını döndürür
+func inWindow(w config.QuietWindow, local time.Time) bool {
+	mins := local.Hour()*60 + local.Minute()
+	if w.Start < w.End {
+		return mins >= w.Start && mins < w.End
+	}
+	return mins >= w.Start || mins < w.End
+}
+
+// windowEnd yerel saate göre aralığın bir sonraki bitişini döndürür
+func windowEnd(w config.QuietWindow, local time.Time) time.Time {
+	end := time.Date(local.Year(), local.Month(), local.Day(), w.End/60, w.End%60, 0, 0, local.Location())
+	if !end.After(local) {
+		end = end.AddDate(0, 0, 1)
+	}
+	return end
+}
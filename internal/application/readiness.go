@@ -0,0 +1,37 @@
+package application
+
+import "sync"
+
+// Readiness instance'ın trafik almaya hazır olup olmadığını tutar. Açılış
+// tamamlanana kadar ve kapanışta batch boşaltılırken hazır değildir.
+type Readiness struct {
+	mu     sync.RWMutex
+	ready  bool
+	reason string
+}
+
+// NewReadiness açılışta hazır olmayan bir durum oluşturur
+func NewReadiness() *Readiness {
+	return &Readiness{reason: "starting"}
+}
+
+// SetReady instance'ı hazır işaretler
+func (r *Readiness) SetReady() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ready, r.reason = true, ""
+}
+
+// SetNotReady instance'ı reason ile hazır değil işaretler
+func (r *Readiness) SetNotReady(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ready, r.reason = false, reason
+}
+
+// State hazır olup olmadığını ve değilse sebebini döner
+func (r *Readiness) State() (bool, string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ready, r.reason
+}
@@ -0,0 +1,31 @@
+package application
+
+import (
+	"context"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+// RecipientCounter bir numaranın tek bir penceredeki gönderim sayacı
+// @Description Per-recipient send counter for one window
+type RecipientCounter struct {
+	Window   string                  `json:"window" example:"1h0m0s"`
+	Count    int64                   `json:"count" example:"3"`
+	Limits   map[entity.Priority]int `json:"limits"`
+	ResetsAt time.Time               `json:"resetsAt" example:"2024-01-01T13:00:00Z"`
+}
+
+// RecipientLimiter numara başına gönderim limitini uygular
+type RecipientLimiter interface {
+	// Allow limit dolmamışsa gönderim için sayaçlarda yer ayırır; gönderilemiyorsa
+	// en erken ne zaman tekrar denenebileceğini döndürür. Ayrılan yer gönderim
+	// başarılıysa Record ile onaylanır, değilse Release ile geri verilir.
+	Allow(ctx context.Context, m *entity.Message) (bool, time.Time, error)
+	// Record Allow ile ayrılan yeri başarılı gönderim olarak onaylar
+	Record(ctx context.Context, m *entity.Message) error
+	// Release gönderim yapılmadığında Allow ile ayrılan yeri geri verir
+	Release(ctx context.Context, m *entity.Message) error
+	// Counters numaranın güncel sayaçlarını döndürür
+	Counters(ctx context.Context, to string) ([]RecipientCounter, error)
+}
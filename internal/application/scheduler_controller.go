@@ -1,8 +1,113 @@
 package application
 
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+)
+
+// ErrShuttingDown instance kapanırken yeni bir batch istendiğinde döner
+var ErrShuttingDown = errors.New("instance is shutting down")
+
+// ScheduleInfo bir zamanlamanın yapılandırması ve çalışma bilgileri
+// @Description Configured schedule with its next fire time
+type ScheduleInfo struct {
+	Name string `json:"name" example:"send-batch"`
+	Job  string `json:"job" example:"send-batch"`
+	Spec string `json:"spec" example:"@every 120s"`
+	// NextRunAt scheduler durmuşsa boştur
+	NextRunAt *time.Time `json:"nextRunAt,omitempty" example:"2024-01-01T12:02:00Z"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty" example:"2024-01-01T12:00:00Z"`
+	LastError string     `json:"lastError,omitempty"`
+}
+
+// SchedulerStatus scheduler'ın durumu, son batch çalışmaları ve kuyruk derinliği
+// @Description Scheduler state with run history and queue depth
+type SchedulerStatus struct {
+	Running bool `json:"running" example:"true"`
+	// Instance cevabı veren instance, Leader lider seçimi açıkken scheduler'ı çalıştıran instance
+	Instance string `json:"instance,omitempty" example:"messaging-7d9f-abcde/3f9a1c"`
+	Leader   string `json:"leader,omitempty" example:"messaging-7d9f-abcde/3f9a1c"`
+	// DispatchMode tick (sadece zamanlamalar) veya stream (yeni mesajda hemen gönderim)
+	DispatchMode string     `json:"dispatchMode" example:"tick"`
+	StartedBy    string     `json:"startedBy,omitempty" example:"ops@example.com (api-key:1a2b3c4d)"`
+	StartedAt    *time.Time `json:"startedAt,omitempty" example:"2024-01-01T09:00:00Z"`
+	StoppedBy    string     `json:"stoppedBy,omitempty" example:"shutdown"`
+	StoppedAt    *time.Time `json:"stoppedAt,omitempty" example:"2024-01-01T08:59:00Z"`
+	// NextRunAt send-batch işinin sıradaki çalışma zamanı, scheduler durmuşsa boştur
+	NextRunAt *time.Time           `json:"nextRunAt,omitempty" example:"2024-01-01T12:02:00Z"`
+	LastRun   *entity.SchedulerRun `json:"lastRun,omitempty"`
+	// LastError hata ile biten en son çalışmanın hatası
+	LastError   string     `json:"lastError,omitempty" example:"dial tcp: i/o timeout"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty" example:"2024-01-01T11:58:00Z"`
+	// Queue veritabanına ulaşılamazsa boştur
+	Queue      *repository.QueueStats `json:"queue,omitempty"`
+	RecentRuns []*entity.SchedulerRun `json:"recentRuns"`
+}
+
+// Elle tetiklenen çalışmanın durumları
+const (
+	ManualRunQueued    = "queued"
+	ManualRunRunning   = "running"
+	ManualRunSucceeded = "succeeded"
+	ManualRunFailed    = "failed"
+)
+
+// ManualRun elle tetiklenen bir batch çalışmasının takip kaydı
+// @Description Manually triggered batch run
+type ManualRun struct {
+	ID string `json:"id" example:"9f2c4e1a7b3d5f60"`
+	// Status queued (başka bir batch bitene kadar bekliyor), running, succeeded veya failed
+	Status      string    `json:"status" example:"succeeded"`
+	RequestedBy string    `json:"requestedBy" example:"ops@example.com (api-key:1a2b3c4d)"`
+	RequestedAt time.Time `json:"requestedAt" example:"2024-01-01T12:00:00Z"`
+	// BatchSize 0 ise ayarlardaki batch boyutu kullanılır
+	BatchSize int                  `json:"batchSize,omitempty" example:"500"`
+	Run       *entity.SchedulerRun `json:"run,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// Actor scheduler durumunu değiştiren. APIKey anahtarın kendisi değil kısa özetidir;
+// shutdown gibi sistem işlemlerinde boştur.
+// @Description Who performed a scheduler action
+type Actor struct {
+	Name   string `json:"name,omitempty" example:"ops@example.com"`
+	APIKey string `json:"apiKey,omitempty" example:"api-key:1a2b3c4d"`
+}
+
+// String durum bilgisinde gösterilen "isim (api-key:xxxx)" biçimi
+func (a Actor) String() string {
+	switch {
+	case a.Name != "" && a.APIKey != "":
+		return fmt.Sprintf("%s (%s)", a.Name, a.APIKey)
+	case a.Name != "":
+		return a.Name
+	case a.APIKey != "":
+		return a.APIKey
+	}
+	return "anonymous"
+}
+
 // SchedulerController scheduler kontrolü için interface
 type SchedulerController interface {
-	Start()
-	Stop()
+	// Start ve Stop işlemi yapanı (actor) durum bilgisinde saklar. Lider seçimi
+	// açıkken istenen durumu kaydeder, scheduler'ı lider instance çalıştırır.
+	Start(actor Actor) error
+	Stop(actor Actor) error
 	IsRunning() bool
+	// Schedules yapılandırılmış zamanlamaları tanım sırasıyla döner
+	Schedules() []ScheduleInfo
+	// Status çalışma durumunu ve geçmişini döner, Queue alanı doldurulmaz
+	Status() SchedulerStatus
+	// TriggerRun scheduler çalışmasa da send-batch'i bir kez çalıştırır. Başka bir
+	// batch çalışıyorsa onun bitmesini bekler, iki batch üst üste çalışmaz. Lider
+	// seçimi açıkken lider olmayan instance'ta ErrNotLeader, kapanış sırasında
+	// ErrShuttingDown döner.
+	TriggerRun(actor string, batchSize int) (ManualRun, error)
+	// ManualRun takip kaydını döner; wait true ise çalışma bitene veya ctx kapanana kadar bekler
+	ManualRun(ctx context.Context, id string, wait bool) (ManualRun, bool)
 }
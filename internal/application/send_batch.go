@@ -2,8 +2,10 @@ package application
 
 import (
 	"context"
+	"errors"
 	"log"
-	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"insider-messaging/internal/config"
@@ -13,54 +15,376 @@ import (
 	"github.com/go-redis/redis/v8"
 )
 
+// SendResult webhook'un kabul ettiği bir gönderimin sonucu
+type SendResult struct {
+	MessageID       string
+	MessageIDSource entity.MessageIDSource
+}
+
 // SenderPort mesaj gönderme işlemlerini yapan interface
 type SenderPort interface {
-	Send(ctx context.Context, m *entity.Message) (string, error)
+	Send(ctx context.Context, m *entity.Message) (SendResult, error)
 }
 
 // SendBatchUseCase mesaj gönderme işlemlerini yönetir
 type SendBatchUseCase struct {
-	repo   repository.MessageRepository
-	sender SenderPort
-	redis  *redis.Client
-	cfg    *config.Config
+	repo    repository.MessageRepository
+	sender  SenderPort
+	redis   *redis.Client
+	cfg     *config.Config
+	limiter RecipientLimiter
+	quiet   *QuietHours
+	policy  ContentPolicy
+	// settings varsa batch boyutu ve eşzamanlılık her çalışmada buradan okunur
+	settings DispatchSettingsManager
+	// sizer varsa batch boyutu her çalışmada kuyruk ve webhook durumuna göre belirlenir
+	sizer *AdaptiveBatchSizer
+	// queue mesajların alındığı kuyruk, varsayılan olarak veritabanı sorgulanır
+	queue MessageQueue
+}
+
+// SendBatchOption use case'e opsiyonel bağımlılık ekler
+type SendBatchOption func(*SendBatchUseCase)
+
+// WithRecipientLimiter numara başına gönderim limitini etkinleştirir
+func WithRecipientLimiter(l RecipientLimiter) SendBatchOption {
+	return func(uc *SendBatchUseCase) { uc.limiter = l }
+}
+
+// WithQuietHours sessiz saatlerde mesajları alıcının saat dilimine göre erteler
+func WithQuietHours(q *QuietHours) SendBatchOption {
+	return func(uc *SendBatchUseCase) { uc.quiet = q }
+}
+
+// WithContentPolicy gönderimden hemen önce içerik kurallarını tekrar uygular,
+// kurallar mesaj oluşturulduktan sonra değişmiş olabilir
+func WithContentPolicy(p ContentPolicy) SendBatchOption {
+	return func(uc *SendBatchUseCase) { uc.policy = p }
+}
+
+// WithDispatchSettings batch boyutu ve eşzamanlılığı çalışma zamanında
+// değiştirilebilen ayarlardan okur
+func WithDispatchSettings(s DispatchSettingsManager) SendBatchOption {
+	return func(uc *SendBatchUseCase) { uc.settings = s }
+}
+
+// WithAdaptiveBatchSizing ayarlardaki sabit batch boyutu yerine her çalışmada
+// kuyruk derinliği, webhook gecikmesi ve hata oranına göre boyut belirler
+func WithAdaptiveBatchSizing(a *AdaptiveBatchSizer) SendBatchOption {
+	return func(uc *SendBatchUseCase) { uc.sizer = a }
+}
+
+// WithQueue batch'lerin mesajları veritabanını sorgulamak yerine verilen
+// kuyruktan almasını sağlar
+func WithQueue(q MessageQueue) SendBatchOption {
+	return func(uc *SendBatchUseCase) { uc.queue = q }
 }
 
 // NewSendBatchUseCase yeni bir batch use case oluşturur
-func NewSendBatchUseCase(r repository.MessageRepository, s SenderPort, rdb *redis.Client, cfg *config.Config) *SendBatchUseCase {
-	return &SendBatchUseCase{repo: r, sender: s, redis: rdb, cfg: cfg}
+func NewSendBatchUseCase(r repository.MessageRepository, s SenderPort, rdb *redis.Client, cfg *config.Config, opts ...SendBatchOption) *SendBatchUseCase {
+	uc := &SendBatchUseCase{repo: r, sender: s, redis: rdb, cfg: cfg}
+	for _, opt := range opts {
+		opt(uc)
+	}
+	if uc.queue == nil {
+		uc.queue = NewPollingQueue(r)
+	}
+	return uc
+}
+
+// BatchResult bir batch çalışmasında mesajların akıbeti
+type BatchResult struct {
+	BatchSize int
+	// Sizing batch boyutunun nasıl belirlendiği: fixed, adaptive veya manual
+	Sizing string
+	// Backlog adaptive modda boyut hesaplanırken gönderilmeye hazır mesaj sayısı
+	Backlog int64
+	Fetched int
+	Sent    int
+	// Failed kalıcı başarısız olan, Retried tekrar denenmek üzere ertelenen mesajlar
+	Failed  int
+	Retried int
+	// Skipped içerik, sessiz saat veya numara limiti nedeniyle gönderilmeyen mesajlar
+	Skipped int
+}
+
+// batchCounters worker'ların paralel güncellediği sayaçlar
+type batchCounters struct {
+	sent, failed, retried, skipped atomic.Int32
 }
 
 // Execute gönderilmemiş mesajları alıp webhook'a gönderir
 func (uc *SendBatchUseCase) Execute(ctx context.Context) error {
-	msgs, err := uc.repo.GetUnsent(uc.cfg.MsgPerTick)
+	_, err := uc.Run(ctx, 0)
+	return err
+}
+
+// Run tek bir batch çalıştırır ve sonucunu döner. limit 0 ise ayarlardaki batch
+// boyutu veya adaptive modda hesaplanan boyut kullanılır. Eşzamanlılık 1'den büyükse mesajlar o kadar worker ile paralel
+// gönderilir; circuit açılırsa, webhook rate limit dönerse veya ctx iptal
+// edilirse (kapanışta boşaltma süresi doldu) kalan mesajlar gönderilmeden bırakılır.
+func (uc *SendBatchUseCase) Run(ctx context.Context, limit int) (BatchResult, error) {
+	s := entity.DispatchSettings{IntervalSeconds: uc.cfg.ScheduleSec, BatchSize: uc.cfg.MsgPerTick, Concurrency: 1}
+	if uc.settings != nil {
+		s = uc.settings.Current()
+	}
+	res := BatchResult{BatchSize: s.BatchSize, Sizing: config.BatchSizingFixed}
+	switch {
+	case limit > 0:
+		res.BatchSize, res.Sizing = limit, BatchSizingManual
+	case uc.sizer != nil:
+		sz := uc.sizer.Size(s)
+		res.BatchSize, res.Sizing, res.Backlog = sz.Size, config.BatchSizingAdaptive, sz.Backlog
+		log.Printf("adaptive batch size=%d backlog=%d latency=%dms errorRate=%.2f", sz.Size, sz.Backlog, sz.LatencyMs, sz.ErrorRate)
+	}
+	concurrency := s.Concurrency
+	msgs, err := uc.queue.Claim(ctx, res.BatchSize)
 	if err != nil {
-		return err
+		return res, err
 	}
+	res.Fetched = len(msgs)
 
+	var counters batchCounters
+
+	var halted atomic.Bool
+	work := make(chan *entity.Message)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency && i < len(msgs); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for m := range work {
+				if !halted.Load() && ctx.Err() == nil && !uc.sendOne(ctx, m, &counters) {
+					halted.Store(true)
+				}
+				// sendOne mesajın durumunu yazdıktan sonra döner, ack ondan sonra yapılır
+				uc.ack(ctx, m.ID)
+			}
+		}()
+	}
+	queued := 0
 	for _, m := range msgs {
-		if len(m.Content) > uc.cfg.MsgCharLimit {
-			m.Content = m.Content[:uc.cfg.MsgCharLimit]
+		if halted.Load() || ctx.Err() != nil {
+			break
+		}
+		work <- m
+		queued++
+	}
+	close(work)
+	wg.Wait()
+	// gönderilmeden bırakılan mesajlar kuyruğa geri döner
+	for _, m := range msgs[queued:] {
+		uc.ack(ctx, m.ID)
+	}
+
+	res.Sent = int(counters.sent.Load())
+	res.Failed = int(counters.failed.Load())
+	res.Retried = int(counters.retried.Load())
+	res.Skipped = int(counters.skipped.Load())
+	return res, nil
+}
+
+// sendOne tek bir mesajı kurallardan geçirip gönderir. Batch'in geri kalanının
+// gönderilmemesi gerekiyorsa false döner.
+func (uc *SendBatchUseCase) sendOne(ctx context.Context, m *entity.Message, c *batchCounters) bool {
+	if len(m.Content) > uc.cfg.MsgCharLimit {
+		m.Content = m.Content[:uc.cfg.MsgCharLimit]
+	}
+
+	if !uc.passesPolicy(m) || !uc.inDeliveryWindow(m) || !uc.allowRecipient(ctx, m) {
+		c.skipped.Add(1)
+		return true
+	}
+
+	start := time.Now()
+	res, err := uc.sender.Send(ctx, m)
+	if err != nil {
+		uc.releaseRecipient(ctx, m)
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		log.Printf("webhook circuit open, leaving remaining messages pending")
+		return false
+	}
+	if err != nil && ctx.Err() != nil {
+		// batch iptal edildi, webhook'un cevabı bilinmediği için deneme sayılmaz
+		log.Printf("batch cancelled while sending id=%d, leaving it pending", m.ID)
+		return false
+	}
+	latency := time.Since(start)
+	if uc.sizer != nil {
+		uc.sizer.Observe(latency, err != nil)
+	}
+	attempt := &entity.Attempt{
+		MessageID: m.ID,
+		Number:    m.Attempts + 1,
+		LatencyMs: latency.Milliseconds(),
+	}
+	if err != nil {
+		if uc.handleFailure(m, attempt, err) {
+			c.failed.Add(1)
+		} else {
+			c.retried.Add(1)
 		}
+		if isRateLimited(err) {
+			log.Printf("webhook rate limited, backing off for the rest of the batch")
+			return false
+		}
+		return true
+	}
 
-		msgID, err := uc.sender.Send(ctx, m)
-		if err != nil {
-			log.Printf("send failed id=%d err=%v", m.ID, err)
-			continue
+	attempt.Outcome = entity.AttemptSent
+	uc.recordAttempt(attempt)
+	c.sent.Add(1)
+	if err := uc.repo.MarkSent(m.ID, res.MessageID, res.MessageIDSource); err != nil {
+		log.Printf("mark sent failed id=%d err=%v", m.ID, err)
+	}
+	// webhook mesajı kabul etti; batch iptal edilmiş olsa da kayıtlar tamamlanır
+	ctx = context.WithoutCancel(ctx)
+	if uc.limiter != nil {
+		if err := uc.limiter.Record(ctx, m); err != nil {
+			log.Printf("recipient counter update failed id=%d err=%v", m.ID, err)
 		}
+	}
 
-		if err := uc.repo.MarkSent(m.ID, msgID); err != nil {
-			log.Printf("mark sent failed id=%d err=%v", m.ID, err)
+	if uc.redis != nil {
+		if err := cacheSent(ctx, uc.redis, m.ID, res.MessageID, res.MessageIDSource, time.Now()); err != nil {
+			log.Printf("cache write failed id=%d err=%v", m.ID, err)
 		}
+	}
+	return true
+}
+
+// ack mesajı kuyruktan düşürür. Hata gönderimi durdurmaz; ack edilmeyen mesaj
+// bekleme süresi dolunca tekrar alınır ve veritabanındaki durumuna göre atlanır.
+// Batch iptal edilmiş olsa da mesajın kuyruğa dönmesi için ack yapılır.
+func (uc *SendBatchUseCase) ack(ctx context.Context, id uint) {
+	if err := uc.queue.Ack(context.WithoutCancel(ctx), id); err != nil {
+		log.Printf("queue ack failed id=%d err=%v", id, err)
+	}
+}
+
+// passesPolicy içerik kuralına takılan mesajı gönderim öncesi karantinaya alır.
+// Mesaj zaten kabul edildiği için reject kuralında da reddedilmez, karantinaya alınır.
+func (uc *SendBatchUseCase) passesPolicy(m *entity.Message) bool {
+	if uc.policy == nil {
+		return true
+	}
+	v := uc.policy.Check(m)
+	if v == nil {
+		return true
+	}
+	log.Printf("message quarantined id=%d: %v", m.ID, v)
+	if err := uc.repo.Quarantine(m.ID, v.Error()); err != nil {
+		log.Printf("quarantine failed id=%d err=%v", m.ID, err)
+	}
+	return false
+}
+
+// inDeliveryWindow alıcı için sessiz saat varsa mesajı deneme saymadan
+// sessiz saatin bittiği zamana erteler
+func (uc *SendBatchUseCase) inDeliveryWindow(m *entity.Message) bool {
+	if uc.quiet == nil {
+		return true
+	}
+	ok, until := uc.quiet.Check(m, time.Now())
+	if ok {
+		return true
+	}
+	log.Printf("quiet hours for recipient id=%d, deferred until %s", m.ID, until.UTC().Format(time.RFC3339))
+	if err := uc.repo.Defer(m.ID, until); err != nil {
+		log.Printf("defer failed id=%d err=%v", m.ID, err)
+	}
+	return false
+}
+
+// allowRecipient numara başına limiti kontrol edip gönderim için yer ayırır,
+// limit dolmuşsa mesajı deneme saymadan pencere bitimine erteler. Redis
+// hatasında gönderime izin verilir.
+func (uc *SendBatchUseCase) allowRecipient(ctx context.Context, m *entity.Message) bool {
+	if uc.limiter == nil {
+		return true
+	}
+	ok, until, err := uc.limiter.Allow(ctx, m)
+	if err != nil {
+		log.Printf("recipient limit check failed id=%d err=%v", m.ID, err)
+		return true
+	}
+	if ok {
+		return true
+	}
+	log.Printf("recipient limit reached id=%d priority=%s, deferred until %s", m.ID, m.Priority, until.Format(time.RFC3339))
+	if err := uc.repo.Defer(m.ID, until); err != nil {
+		log.Printf("defer failed id=%d err=%v", m.ID, err)
+	}
+	return false
+}
 
-		if uc.redis != nil {
-			key := "message:" + strconv.FormatUint(uint64(m.ID), 10)
-			now := time.Now().UTC().Format(time.RFC3339)
-			uc.redis.HSet(ctx, key, map[string]interface{}{
-				"webhook_id": msgID,
-				"sent_at":    now,
-			})
+// releaseRecipient gönderim başarısız olduğunda numara limitinde ayrılan yeri
+// geri verir; sayaçlar sadece başarılı gönderimleri sayar
+func (uc *SendBatchUseCase) releaseRecipient(ctx context.Context, m *entity.Message) {
+	if uc.limiter == nil {
+		return
+	}
+	if err := uc.limiter.Release(context.WithoutCancel(ctx), m); err != nil {
+		log.Printf("recipient counter release failed id=%d err=%v", m.ID, err)
+	}
+}
+
+// handleFailure hatanın türüne göre mesajı kalıcı olarak başarısız işaretler
+// veya backoff ile ileri bir zamana erteler. Mesaj kalıcı başarısızsa true döner.
+func (uc *SendBatchUseCase) handleFailure(m *entity.Message, attempt *entity.Attempt, err error) bool {
+	attempt.Error = err.Error()
+	attempt.StatusCode, attempt.ResponseBody = sendErrorDetails(err)
+
+	maxAttempts := uc.cfg.MaxSendAttempts
+	if IsPermanent(err) || (maxAttempts > 0 && attempt.Number >= maxAttempts) {
+		attempt.Outcome = entity.AttemptFailed
+		uc.recordAttempt(attempt)
+		log.Printf("send failed permanently id=%d attempt=%d err=%v", m.ID, attempt.Number, err)
+		if err := uc.repo.MarkFailed(m.ID, attempt.Error); err != nil {
+			log.Printf("mark failed failed id=%d err=%v", m.ID, err)
 		}
+		return true
+	}
+
+	attempt.Outcome = entity.AttemptRetry
+	uc.recordAttempt(attempt)
+	delay := uc.backoff(attempt.Number, retryAfter(err))
+	log.Printf("send failed id=%d attempt=%d retry in %v err=%v", m.ID, attempt.Number, delay, err)
+	if err := uc.repo.Defer(m.ID, time.Now().Add(delay)); err != nil {
+		log.Printf("defer failed id=%d err=%v", m.ID, err)
+	}
+	return false
+}
+
+// recordAttempt deneme kaydını yazar, hata gönderimi durdurmaz
+func (uc *SendBatchUseCase) recordAttempt(a *entity.Attempt) {
+	if err := uc.repo.RecordAttempt(a); err != nil {
+		log.Printf("record attempt failed id=%d err=%v", a.MessageID, err)
+	}
+}
+
+// backoff deneme sayısına göre üstel bekleme süresini hesaplar,
+// webhook daha uzun bir Retry-After istediyse onu kullanır
+func (uc *SendBatchUseCase) backoff(attempt int, hint time.Duration) time.Duration {
+	base := time.Duration(uc.cfg.RetryBaseSeconds) * time.Second
+	if base <= 0 {
+		base = 30 * time.Second
+	}
+	max := time.Duration(uc.cfg.RetryMaxSeconds) * time.Second
+	if max < base {
+		max = base
+	}
+
+	delay := base
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if hint > delay {
+		delay = hint
 	}
-	return nil
+	return delay
 }
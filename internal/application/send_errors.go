@@ -0,0 +1,85 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PermanentError webhook'un mesajı kesin olarak reddettiğini belirtir (örn. 400),
+// mesaj tekrar denenmez
+type PermanentError struct {
+	StatusCode int
+	Body       string
+	Err        error
+}
+
+func (e *PermanentError) Error() string {
+	return fmt.Sprintf("permanent webhook error (status %d): %v", e.StatusCode, e.Err)
+}
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// RetryableError geçici bir webhook hatasıdır (örn. 503, 429), mesaj daha sonra tekrar denenir.
+// RetryAfter sıfırdan büyükse webhook'un istediği bekleme süresidir.
+type RetryableError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RetryableError) Error() string {
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("retryable webhook error: %v", e.Err)
+	}
+	return fmt.Sprintf("retryable webhook error (status %d): %v", e.StatusCode, e.Err)
+}
+
+func (e *RetryableError) Unwrap() error { return e.Err }
+
+// TimeoutError webhook zamanında cevap vermediğinde döner. Mesajın webhook
+// tarafından alınıp alınmadığı bilinmez, tekrar denenebilir.
+type TimeoutError struct {
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("webhook timeout: %v", e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// IsPermanent hatanın kalıcı olup olmadığını döndürür
+func IsPermanent(err error) bool {
+	var pe *PermanentError
+	return errors.As(err, &pe)
+}
+
+// sendErrorDetails attempt kaydı için HTTP status ve cevap gövdesini çıkarır
+func sendErrorDetails(err error) (int, string) {
+	var pe *PermanentError
+	if errors.As(err, &pe) {
+		return pe.StatusCode, pe.Body
+	}
+	var re *RetryableError
+	if errors.As(err, &re) {
+		return re.StatusCode, re.Body
+	}
+	return 0, ""
+}
+
+// retryAfter webhook'un önerdiği bekleme süresini döndürür
+func retryAfter(err error) time.Duration {
+	var re *RetryableError
+	if errors.As(err, &re) {
+		return re.RetryAfter
+	}
+	return 0
+}
+
+// isRateLimited webhook'un 429 ile istekleri yavaşlatmamızı isteyip istemediğini döndürür
+func isRateLimited(err error) bool {
+	var re *RetryableError
+	return errors.As(err, &re) && re.StatusCode == 429
+}
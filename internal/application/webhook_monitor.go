@@ -0,0 +1,74 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+var _ SenderPort = (*WebhookMonitor)(nil)
+
+// WebhookMonitor gönderimlerin sonucunu izleyerek webhook'a son zamanlarda
+// ulaşılıp ulaşılamadığını raporlar. Kalıcı hatalar (4xx) webhook'un cevap
+// verdiğini gösterdiği için ulaşılabilir sayılır.
+type WebhookMonitor struct {
+	next   SenderPort
+	window time.Duration
+
+	mu          sync.Mutex
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastErr     string
+}
+
+// NewWebhookMonitor next'i saran ve son window içindeki gönderimlere bakan bir monitor oluşturur
+func NewWebhookMonitor(next SenderPort, window time.Duration) *WebhookMonitor {
+	return &WebhookMonitor{next: next, window: window}
+}
+
+// Send gönderimi next'e iletir ve sonucunu kaydeder
+func (m *WebhookMonitor) Send(ctx context.Context, msg *entity.Message) (SendResult, error) {
+	res, err := m.next.Send(ctx, msg)
+	switch {
+	case errors.Is(err, ErrCircuitOpen), errors.Is(err, context.Canceled):
+		// webhook denenmedi veya gönderim kapanışta yarıda kesildi
+	case err == nil || IsPermanent(err):
+		m.mu.Lock()
+		m.lastSuccess = time.Now()
+		m.mu.Unlock()
+	default:
+		m.mu.Lock()
+		m.lastFailure, m.lastErr = time.Now(), err.Error()
+		m.mu.Unlock()
+	}
+	return res, err
+}
+
+// Health circuit breaker açıksa veya son gönderim başarısız olduysa down, son
+// window içinde başarılı gönderim de varsa degraded döner. Son window içinde
+// gönderim yoksa webhook'un durumu bilinmediği için up sayılır.
+func (m *WebhookMonitor) Health(ctx context.Context) ComponentHealth {
+	if inspector, ok := m.next.(CircuitBreakerInspector); ok {
+		if st := inspector.Status(); st.State == CircuitOpen {
+			return ComponentHealth{Status: HealthDown, Message: fmt.Sprintf("circuit breaker is open (failure ratio %.2f)", st.FailureRatio)}
+		}
+	}
+	m.mu.Lock()
+	success, failure, lastErr := m.lastSuccess, m.lastFailure, m.lastErr
+	m.mu.Unlock()
+
+	since := time.Now().Add(-m.window)
+	switch {
+	case failure.Before(since) && success.Before(since):
+		return ComponentHealth{Status: HealthUp, Message: "no sends in the last " + m.window.String()}
+	case failure.After(success) && success.After(since):
+		return ComponentHealth{Status: HealthDegraded, Message: "last send failed: " + lastErr}
+	case failure.After(success):
+		return ComponentHealth{Status: HealthDown, Message: fmt.Sprintf("no successful send in the last %s: %s", m.window, lastErr)}
+	}
+	return ComponentHealth{Status: HealthUp}
+}
@@ -2,29 +2,214 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"log"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"insider-messaging/internal/domain/phone"
 
 	"github.com/joho/godotenv"
 )
 
+// Webhook cevabında messageId olmadığında uygulanacak politikalar
+const (
+	MsgIDPolicyFail      = "fail"
+	MsgIDPolicySynthetic = "synthetic"
+	MsgIDPolicyNone      = "none"
+)
+
+// Batch boyutunun belirlenme modları
+const (
+	BatchSizingFixed    = "fixed"
+	BatchSizingAdaptive = "adaptive"
+)
+
+// Gönderim modları
+const (
+	DispatchModeTick   = "tick"
+	DispatchModeStream = "stream"
+)
+
+// Gönderim kuyruğu altyapıları
+const (
+	QueueBackendMySQL = "mysql"
+	QueueBackendRedis = "redis"
+)
+
+// Outbox olaylarının yayınlanabileceği sink'ler
+const (
+	OutboxSinkLog     = "log"
+	OutboxSinkRedis   = "redis"
+	OutboxSinkWebhook = "webhook"
+)
+
+// RateLimit bir pencere içinde izin verilen maksimum sayıyı tanımlar
+type RateLimit struct {
+	Max    int
+	Window time.Duration
+}
+
+// ScheduleSpec isimli bir zamanlamayı bir işe bağlar. Spec cron ifadesidir,
+// geçerliliği scheduler oluşturulurken kontrol edilir.
+type ScheduleSpec struct {
+	Name string
+	Job  string
+	Spec string
+	// Interval zamanlama SCHEDULES verilmediği için SCHEDULE_SECONDS'tan
+	// oluşturulduysa true; API'deki intervalSeconds sadece buna uygulanır
+	Interval bool
+}
+
+// QuietWindow gün içinde mesaj gönderilmeyecek saat aralığı, gece yarısını geçebilir.
+// Start ve End günün başından itibaren dakika cinsindendir.
+type QuietWindow struct {
+	Start int
+	End   int
+}
+
 type Config struct {
-	Port                  string
-	DBHost                string
-	DBPort                string
-	DBUser                string
-	DBPassword            string
-	DBName                string
-	WebhookURL            string
-	WebhookAuthKey        string
-	APIKey                string
-	RedisAddr             string
-	RedisPassword         string
-	MsgCharLimit          int
-	ScheduleSec           int
-	MsgPerTick            int
+	Port           string
+	DBHost         string
+	DBPort         string
+	DBUser         string
+	DBPassword     string
+	DBName         string
+	WebhookURL     string
+	WebhookAuthKey string
+	APIKey         string
+	RedisAddr      string
+	RedisPassword  string
+	MsgCharLimit   int
+	ScheduleSec    int
+	MsgPerTick     int
+	// SendConcurrency bir batch içinde aynı anda yapılan webhook isteği sayısı
+	SendConcurrency int
+	// DispatchSettingsReloadSeconds API'den değiştirilen gönderim ayarlarının yeniden okunma aralığı
+	DispatchSettingsReloadSeconds int
+	// Schedules scheduler'ın çalıştırdığı zamanlamalar, SCHEDULES boşsa
+	// SCHEDULE_SECONDS aralıklı tek bir send-batch zamanlaması
+	Schedules []ScheduleSpec
+	// SchedulerRunHistory saklanan son batch çalışması sayısı
+	SchedulerRunHistory int
+	// AutoStart kayıtlı scheduler durumu yoksa (ilk açılış) scheduler'ı başlatır
+	AutoStart bool
+	// LeaderElection açıksa scheduler'ı instance'lardan sadece lider çalıştırır
+	LeaderElection bool
+	// LeaderLockName Redis anahtarı veya MySQL GET_LOCK ismi
+	LeaderLockName string
+	// LeaderLockTTLSeconds liderin kilidi yenilemeden tutabileceği süre, ölen liderin
+	// yerine en geç bu kadar sürede yenisi geçer
+	LeaderLockTTLSeconds int
+	// DrainTimeoutSeconds kapanışta süren batch'in bitmesi için beklenen süre; dolarsa
+	// batch iptal edilir ve gönderilmemiş mesajlar kuyruğa bırakılır
+	DrainTimeoutSeconds int
+	// HealthCheckTimeoutSeconds /livez ve /readyz'de her bağımlılık kontrolüne tanınan süre
+	HealthCheckTimeoutSeconds int
+	// WebhookHealthWindowSeconds webhook sağlığı için bakılan son gönderimlerin penceresi
+	WebhookHealthWindowSeconds int
+	// RetentionDays retention işinin gönderimi tamamlanmış mesajları sakladığı gün sayısı
+	RetentionDays int
+	// CacheReconcileHours cache-reconcile işinin Redis'te kontrol ettiği gönderim penceresi
+	CacheReconcileHours   int
 	WebhookTimeoutSeconds int
+	WebhookMsgIDPolicy    string
+	// WebhookMetadataKeys webhook payload'ına eklenecek metadata anahtarları
+	WebhookMetadataKeys []string
+
+	MetadataMaxBytes int
+	MaxTags          int
+	// ContentPolicyReloadSeconds içerik kurallarının veritabanından yeniden okunma aralığı
+	ContentPolicyReloadSeconds int
+	// LinkBaseURL kısa linklerin kök adresi (örn. https://sms.example.com), boşsa link takibi kapalı
+	LinkBaseURL    string
+	LinkCodeLength int
+	// PhoneDefaultRegion ulusal formatta gelen numaraların ülkesi (ISO 3166-1 alpha-2)
+	PhoneDefaultRegion string
+
+	WebhookAuthMode          string
+	WebhookAuthHeader        string
+	WebhookBasicUser         string
+	WebhookBasicPassword     string
+	WebhookOAuthTokenURL     string
+	WebhookOAuthClientID     string
+	WebhookOAuthClientSecret string
+	WebhookOAuthScopes       []string
+	WebhookOAuthAuthStyle    string
+
+	WebhookTLSCertFile      string
+	WebhookTLSKeyFile       string
+	WebhookTLSCAFile        string
+	WebhookTLSMinVersion    string
+	WebhookTLSPins          []string
+	WebhookTLSReloadSeconds int
+
+	CircuitEnabled         bool
+	CircuitFailureRatio    float64
+	CircuitMinRequests     int
+	CircuitWindowSize      int
+	CircuitCooldownSeconds int
+	CircuitHalfOpenProbes  int
+
+	MaxSendAttempts  int
+	RetryBaseSeconds int
+	RetryMaxSeconds  int
+
+	// DispatchMode tick ise mesajlar sadece send-batch zamanlamasıyla, stream ise
+	// oluşturulur oluşturulmaz gönderilir; zamanlama kaçanlar için tarama olarak kalır
+	DispatchMode string
+	// DispatchNotifyChannel stream modunda instance'lar arası bildirimin Redis kanalı
+	DispatchNotifyChannel string
+
+	// QueueBackend mysql ise batch'ler veritabanını sorgulayarak, redis ise Redis
+	// Streams consumer group'undan mesaj alır; mesaj durumu her iki modda da veritabanındadır
+	QueueBackend string
+	// QueueStream ve QueueGroup redis modunda kullanılan stream anahtarı ve consumer group
+	QueueStream string
+	QueueGroup  string
+	// QueueClaimIdleSeconds ack edilmeyen bir mesajın başka bir instance tarafından
+	// devralınması için beklenen süre, bir batch'in süresinden uzun olmalı
+	QueueClaimIdleSeconds int
+
+	// OutboxSinks mesaj olaylarının yayınlandığı sink'ler (log, redis, webhook),
+	// boşsa outbox kapalıdır ve olay yazılmaz
+	OutboxSinks []string
+	// OutboxStream redis sink'inin olayları eklediği stream
+	OutboxStream string
+	// OutboxWebhookURL webhook sink'inin olayları POST ettiği adres
+	OutboxWebhookURL string
+	// OutboxPollSeconds relay'in yeni olaylara bakma aralığı
+	OutboxPollSeconds int
+	// OutboxBatchSize relay'in bir seferde yayınladığı en fazla olay
+	OutboxBatchSize int
+	// OutboxRetentionHours yayınlanmış olayların silinmeden önce tutulduğu süre
+	OutboxRetentionHours int
+	// OutboxMaxAttempts bu kadar denemede yayınlanamayan olay dead-letter'a
+	// ayrılır ve sonraki olayları bekletmez
+	OutboxMaxAttempts int
+
+	// BatchSizing fixed ise batch boyutu gönderim ayarlarından, adaptive ise her
+	// çalışmada kuyruk derinliği, webhook gecikmesi ve hata oranından belirlenir
+	BatchSizing string
+	// AdaptiveBatchMin ve AdaptiveBatchMax adaptive modda batch boyutunun sınırları
+	AdaptiveBatchMin int
+	AdaptiveBatchMax int
+	// AdaptiveBatchTarget batch'in gönderim aralığının ne kadarında bitmesinin hedeflendiği (0-1)
+	AdaptiveBatchTarget float64
+	// AdaptiveBatchWindow gecikme ve hata oranının hesaplandığı son gönderim sayısı
+	AdaptiveBatchWindow int
+
+	// RecipientLimits öncelik adına göre numara başına gönderim limitleri
+	RecipientLimits map[string]RateLimit
+
+	// QuietHours tüm mesajlara uygulanan global sessiz saatler, nil ise kapalı
+	QuietHours           *QuietWindow
+	QuietHoursByPriority map[string]QuietWindow
+	QuietHoursByCategory map[string]QuietWindow
+	QuietHoursDefaultTZ  string
 }
 
 // Load environment variable'ları yükler ve config oluşturur
@@ -77,6 +262,77 @@ func Load() (*Config, error) {
 		ScheduleSec:           sched,
 		MsgPerTick:            per,
 		WebhookTimeoutSeconds: webhookTimeout,
+		WebhookMsgIDPolicy:    envString("WEBHOOK_MSGID_POLICY", MsgIDPolicySynthetic),
+		WebhookMetadataKeys:   envList("WEBHOOK_METADATA_KEYS"),
+		MetadataMaxBytes:      envInt("MESSAGE_METADATA_MAX_BYTES", 1024),
+		MaxTags:               envInt("MESSAGE_MAX_TAGS", 10),
+		SendConcurrency:       envInt("SEND_CONCURRENCY", 1),
+		SchedulerRunHistory:   envInt("SCHEDULER_RUN_HISTORY", 20),
+		AutoStart:             envBool("AUTO_START", false),
+		LeaderElection:        envBool("LEADER_ELECTION", false),
+		LeaderLockName:        envString("LEADER_LOCK_NAME", "insider-messaging:scheduler-leader"),
+		LeaderLockTTLSeconds:  envInt("LEADER_LOCK_TTL_SECONDS", 15),
+		DrainTimeoutSeconds:   envInt("DRAIN_TIMEOUT_SECONDS", 20),
+		RetentionDays:         envInt("MESSAGE_RETENTION_DAYS", 90),
+		CacheReconcileHours:   envInt("CACHE_RECONCILE_WINDOW_HOURS", 24),
+		PhoneDefaultRegion:    strings.ToUpper(envString("PHONE_DEFAULT_REGION", "TR")),
+
+		ContentPolicyReloadSeconds:    envInt("CONTENT_POLICY_RELOAD_SECONDS", 30),
+		DispatchSettingsReloadSeconds: envInt("SCHEDULER_CONFIG_RELOAD_SECONDS", 15),
+		LinkBaseURL:                   os.Getenv("LINK_BASE_URL"),
+		LinkCodeLength:                envInt("LINK_CODE_LENGTH", 7),
+		HealthCheckTimeoutSeconds:     envInt("HEALTH_CHECK_TIMEOUT_SECONDS", 2),
+		WebhookHealthWindowSeconds:    envInt("WEBHOOK_HEALTH_WINDOW_SECONDS", 300),
+
+		WebhookAuthMode:          os.Getenv("WEBHOOK_AUTH_MODE"),
+		WebhookAuthHeader:        envString("WEBHOOK_AUTH_HEADER", "x-ins-auth-key"),
+		WebhookBasicUser:         os.Getenv("WEBHOOK_BASIC_USER"),
+		WebhookBasicPassword:     os.Getenv("WEBHOOK_BASIC_PASSWORD"),
+		WebhookOAuthTokenURL:     os.Getenv("WEBHOOK_OAUTH_TOKEN_URL"),
+		WebhookOAuthClientID:     os.Getenv("WEBHOOK_OAUTH_CLIENT_ID"),
+		WebhookOAuthClientSecret: os.Getenv("WEBHOOK_OAUTH_CLIENT_SECRET"),
+		WebhookOAuthScopes:       envList("WEBHOOK_OAUTH_SCOPES"),
+		WebhookOAuthAuthStyle:    envString("WEBHOOK_OAUTH_AUTH_STYLE", "header"),
+
+		WebhookTLSCertFile:      os.Getenv("WEBHOOK_TLS_CERT_FILE"),
+		WebhookTLSKeyFile:       os.Getenv("WEBHOOK_TLS_KEY_FILE"),
+		WebhookTLSCAFile:        os.Getenv("WEBHOOK_TLS_CA_FILE"),
+		WebhookTLSMinVersion:    os.Getenv("WEBHOOK_TLS_MIN_VERSION"),
+		WebhookTLSPins:          envList("WEBHOOK_TLS_PINS"),
+		WebhookTLSReloadSeconds: envInt("WEBHOOK_TLS_RELOAD_SECONDS", 30),
+
+		CircuitEnabled:         envBool("WEBHOOK_CB_ENABLED", true),
+		CircuitFailureRatio:    envFloat("WEBHOOK_CB_FAILURE_RATIO", 0.5),
+		CircuitMinRequests:     envInt("WEBHOOK_CB_MIN_REQUESTS", 5),
+		CircuitWindowSize:      envInt("WEBHOOK_CB_WINDOW", 20),
+		CircuitCooldownSeconds: envInt("WEBHOOK_CB_COOLDOWN_SECONDS", 60),
+		CircuitHalfOpenProbes:  envInt("WEBHOOK_CB_HALF_OPEN_PROBES", 1),
+
+		MaxSendAttempts:  envInt("MAX_SEND_ATTEMPTS", 5),
+		RetryBaseSeconds: envInt("RETRY_BASE_SECONDS", 30),
+		RetryMaxSeconds:  envInt("RETRY_MAX_SECONDS", 3600),
+
+		DispatchMode:          envString("DISPATCH_MODE", DispatchModeTick),
+		DispatchNotifyChannel: envString("DISPATCH_NOTIFY_CHANNEL", "insider-messaging:dispatch"),
+
+		QueueBackend:          envString("QUEUE_BACKEND", QueueBackendMySQL),
+		QueueStream:           envString("QUEUE_STREAM", "insider-messaging:queue"),
+		QueueGroup:            envString("QUEUE_GROUP", "dispatchers"),
+		QueueClaimIdleSeconds: envInt("QUEUE_CLAIM_IDLE_SECONDS", 120),
+
+		OutboxSinks:          envList("OUTBOX_SINKS"),
+		OutboxStream:         envString("OUTBOX_STREAM", "insider-messaging:events"),
+		OutboxWebhookURL:     os.Getenv("OUTBOX_WEBHOOK_URL"),
+		OutboxPollSeconds:    envInt("OUTBOX_POLL_SECONDS", 2),
+		OutboxBatchSize:      envInt("OUTBOX_BATCH_SIZE", 100),
+		OutboxRetentionHours: envInt("OUTBOX_RETENTION_HOURS", 24),
+		OutboxMaxAttempts:    envInt("OUTBOX_MAX_ATTEMPTS", 10),
+
+		BatchSizing:         envString("BATCH_SIZING", BatchSizingFixed),
+		AdaptiveBatchMin:    envInt("ADAPTIVE_BATCH_MIN", 1),
+		AdaptiveBatchMax:    envInt("ADAPTIVE_BATCH_MAX", 500),
+		AdaptiveBatchTarget: envFloat("ADAPTIVE_BATCH_TARGET", 0.8),
+		AdaptiveBatchWindow: envInt("ADAPTIVE_BATCH_WINDOW", 50),
 	}
 
 	if cfg.DBHost == "" {
@@ -94,8 +350,285 @@ func Load() (*Config, error) {
 	if cfg.DBName == "" {
 		return nil, errors.New("DB_NAME is required")
 	}
+	limits, err := ParseRateLimits(envString("RECIPIENT_LIMITS", "low=10/1h,normal=20/1h,high=60/1h"))
+	if err != nil {
+		return nil, fmt.Errorf("RECIPIENT_LIMITS: %w", err)
+	}
+	cfg.RecipientLimits = limits
+
+	if v := os.Getenv("QUIET_HOURS"); v != "" {
+		w, err := ParseQuietWindow(v)
+		if err != nil {
+			return nil, fmt.Errorf("QUIET_HOURS: %w", err)
+		}
+		cfg.QuietHours = &w
+	}
+	if cfg.QuietHoursByPriority, err = ParseQuietWindows(os.Getenv("QUIET_HOURS_PRIORITY")); err != nil {
+		return nil, fmt.Errorf("QUIET_HOURS_PRIORITY: %w", err)
+	}
+	if cfg.QuietHoursByCategory, err = ParseQuietWindows(envString("QUIET_HOURS_CATEGORY", "marketing=21:00-09:00")); err != nil {
+		return nil, fmt.Errorf("QUIET_HOURS_CATEGORY: %w", err)
+	}
+	cfg.QuietHoursDefaultTZ = envString("QUIET_HOURS_DEFAULT_TZ", "Europe/Istanbul")
+	if _, err := time.LoadLocation(cfg.QuietHoursDefaultTZ); err != nil {
+		return nil, fmt.Errorf("QUIET_HOURS_DEFAULT_TZ: %w", err)
+	}
+
+	if v := os.Getenv("SCHEDULES"); v != "" {
+		if cfg.Schedules, err = ParseSchedules(v); err != nil {
+			return nil, fmt.Errorf("SCHEDULES: %w", err)
+		}
+	} else {
+		if cfg.ScheduleSec <= 0 {
+			cfg.ScheduleSec = 120
+		}
+		cfg.Schedules = []ScheduleSpec{{Name: "send-batch", Job: "send-batch", Spec: fmt.Sprintf("@every %ds", cfg.ScheduleSec), Interval: true}}
+	}
+	if cfg.SchedulerRunHistory < 1 {
+		return nil, errors.New("SCHEDULER_RUN_HISTORY must be at least 1")
+	}
+	if cfg.LeaderLockTTLSeconds < 3 {
+		return nil, errors.New("LEADER_LOCK_TTL_SECONDS must be at least 3")
+	}
+	if cfg.LeaderLockName == "" || len(cfg.LeaderLockName) > 64 {
+		return nil, errors.New("LEADER_LOCK_NAME must be 1-64 characters")
+	}
+	if cfg.DrainTimeoutSeconds < 1 {
+		return nil, errors.New("DRAIN_TIMEOUT_SECONDS must be at least 1")
+	}
+	if cfg.HealthCheckTimeoutSeconds < 1 {
+		return nil, errors.New("HEALTH_CHECK_TIMEOUT_SECONDS must be at least 1")
+	}
+	if cfg.WebhookHealthWindowSeconds < 1 {
+		return nil, errors.New("WEBHOOK_HEALTH_WINDOW_SECONDS must be at least 1")
+	}
+	if cfg.RetentionDays < 1 {
+		return nil, errors.New("MESSAGE_RETENTION_DAYS must be at least 1")
+	}
+	if cfg.CacheReconcileHours < 1 {
+		return nil, errors.New("CACHE_RECONCILE_WINDOW_HOURS must be at least 1")
+	}
+
+	if cfg.LinkBaseURL != "" {
+		u, err := url.Parse(cfg.LinkBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, errors.New("LINK_BASE_URL must be an absolute http(s) URL")
+		}
+	}
+	for _, s := range cfg.OutboxSinks {
+		switch s {
+		case OutboxSinkLog, OutboxSinkRedis:
+		case OutboxSinkWebhook:
+			u, err := url.Parse(cfg.OutboxWebhookURL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, errors.New("OUTBOX_WEBHOOK_URL must be an absolute http(s) URL when the webhook sink is enabled")
+			}
+		default:
+			return nil, fmt.Errorf("OUTBOX_SINKS: unknown sink %q", s)
+		}
+	}
+	if cfg.OutboxPollSeconds < 1 {
+		return nil, errors.New("OUTBOX_POLL_SECONDS must be at least 1")
+	}
+	if cfg.OutboxBatchSize < 1 || cfg.OutboxBatchSize > 1000 {
+		return nil, errors.New("OUTBOX_BATCH_SIZE must be between 1 and 1000")
+	}
+	if cfg.OutboxRetentionHours < 1 {
+		return nil, errors.New("OUTBOX_RETENTION_HOURS must be at least 1")
+	}
+	if cfg.OutboxMaxAttempts < 1 {
+		return nil, errors.New("OUTBOX_MAX_ATTEMPTS must be at least 1")
+	}
+	if cfg.LinkCodeLength < 5 || cfg.LinkCodeLength > 16 {
+		return nil, errors.New("LINK_CODE_LENGTH must be between 5 and 16")
+	}
+	if phone.TimeZones(cfg.PhoneDefaultRegion) == nil {
+		return nil, fmt.Errorf("PHONE_DEFAULT_REGION: unsupported region %q", cfg.PhoneDefaultRegion)
+	}
+
+	switch cfg.WebhookMsgIDPolicy {
+	case MsgIDPolicyFail, MsgIDPolicySynthetic, MsgIDPolicyNone:
+	default:
+		return nil, fmt.Errorf("WEBHOOK_MSGID_POLICY must be one of %s, %s, %s", MsgIDPolicyFail, MsgIDPolicySynthetic, MsgIDPolicyNone)
+	}
+	switch cfg.DispatchMode {
+	case DispatchModeTick, DispatchModeStream:
+	default:
+		return nil, fmt.Errorf("DISPATCH_MODE must be one of %s, %s", DispatchModeTick, DispatchModeStream)
+	}
+	switch cfg.QueueBackend {
+	case QueueBackendMySQL, QueueBackendRedis:
+	default:
+		return nil, fmt.Errorf("QUEUE_BACKEND must be one of %s, %s", QueueBackendMySQL, QueueBackendRedis)
+	}
+	if cfg.QueueStream == "" || cfg.QueueGroup == "" {
+		return nil, errors.New("QUEUE_STREAM and QUEUE_GROUP must not be empty")
+	}
+	if cfg.QueueClaimIdleSeconds < 10 {
+		return nil, errors.New("QUEUE_CLAIM_IDLE_SECONDS must be at least 10")
+	}
+	switch cfg.BatchSizing {
+	case BatchSizingFixed, BatchSizingAdaptive:
+	default:
+		return nil, fmt.Errorf("BATCH_SIZING must be one of %s, %s", BatchSizingFixed, BatchSizingAdaptive)
+	}
+	if cfg.AdaptiveBatchMin < 1 || cfg.AdaptiveBatchMax < cfg.AdaptiveBatchMin || cfg.AdaptiveBatchMax > 10000 {
+		return nil, errors.New("ADAPTIVE_BATCH_MIN and ADAPTIVE_BATCH_MAX must satisfy 1 <= min <= max <= 10000")
+	}
+	if cfg.AdaptiveBatchTarget <= 0 || cfg.AdaptiveBatchTarget > 1 {
+		return nil, errors.New("ADAPTIVE_BATCH_TARGET must be in (0, 1]")
+	}
+	if cfg.AdaptiveBatchWindow < 1 {
+		return nil, errors.New("ADAPTIVE_BATCH_WINDOW must be at least 1")
+	}
 	if cfg.WebhookURL == "" {
 		log.Println("WARNING: WEBHOOK_URL is empty")
 	}
 	return cfg, nil
 }
+
+// ParseRateLimits "low=10/1h,normal=20/30m" formatındaki limitleri çözer.
+// Max değeri 0 olan öncelikler limitsiz kabul edilir.
+func ParseRateLimits(spec string) (map[string]RateLimit, error) {
+	limits := map[string]RateLimit{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, rule, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid limit %q, expected name=max/window", part)
+		}
+		maxStr, windowStr, ok := strings.Cut(rule, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid limit %q, expected name=max/window", part)
+		}
+		max, err := strconv.Atoi(strings.TrimSpace(maxStr))
+		if err != nil || max < 0 {
+			return nil, fmt.Errorf("invalid max in %q", part)
+		}
+		window, err := time.ParseDuration(strings.TrimSpace(windowStr))
+		if err != nil || window < time.Second {
+			return nil, fmt.Errorf("invalid window in %q", part)
+		}
+		limits[strings.ToLower(strings.TrimSpace(name))] = RateLimit{Max: max, Window: window}
+	}
+	return limits, nil
+}
+
+// ParseSchedules "name=job:cron;name=job:cron" formatındaki zamanlamaları çözer.
+// Cron ifadesi boşluk ve virgül içerebildiği için zamanlamalar ; ile ayrılır,
+// örn. "send=send-batch:*/30 * * * * *;cleanup=retention:CRON_TZ=Europe/Istanbul 0 3 * * *"
+func ParseSchedules(spec string) ([]ScheduleSpec, error) {
+	var out []ScheduleSpec
+	seen := map[string]bool{}
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, rest, ok := strings.Cut(part, "=")
+		job, cron, ok2 := strings.Cut(rest, ":")
+		name, job, cron = strings.TrimSpace(name), strings.TrimSpace(job), strings.TrimSpace(cron)
+		if !ok || !ok2 || name == "" || job == "" || cron == "" {
+			return nil, fmt.Errorf("invalid schedule %q, expected name=job:cron", part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate schedule name %q", name)
+		}
+		seen[name] = true
+		out = append(out, ScheduleSpec{Name: name, Job: job, Spec: cron})
+	}
+	if len(out) == 0 {
+		return nil, errors.New("no schedules defined")
+	}
+	return out, nil
+}
+
+// ParseQuietWindow "21:00-09:00" formatındaki aralığı çözer
+func ParseQuietWindow(spec string) (QuietWindow, error) {
+	from, to, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return QuietWindow{}, fmt.Errorf("invalid quiet window %q, expected HH:MM-HH:MM", spec)
+	}
+	start, err := time.Parse("15:04", strings.TrimSpace(from))
+	if err != nil {
+		return QuietWindow{}, fmt.Errorf("invalid quiet window start %q", from)
+	}
+	end, err := time.Parse("15:04", strings.TrimSpace(to))
+	if err != nil {
+		return QuietWindow{}, fmt.Errorf("invalid quiet window end %q", to)
+	}
+	return QuietWindow{Start: start.Hour()*60 + start.Minute(), End: end.Hour()*60 + end.Minute()}, nil
+}
+
+// ParseQuietWindows "marketing=21:00-09:00,low=20:00-10:00" formatındaki aralıkları çözer
+func ParseQuietWindows(spec string) (map[string]QuietWindow, error) {
+	windows := map[string]QuietWindow{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, rule, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid quiet window %q, expected name=HH:MM-HH:MM", part)
+		}
+		w, err := ParseQuietWindow(rule)
+		if err != nil {
+			return nil, err
+		}
+		windows[strings.ToLower(strings.TrimSpace(name))] = w
+	}
+	return windows, nil
+}
+
+// envString string environment variable'ı okur, yoksa varsayılanı döner
+func envString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// envList virgülle ayrılmış environment variable'ı boş elemanları atarak okur
+func envList(key string) []string {
+	var out []string
+	for _, p := range strings.Split(os.Getenv(key), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// envInt integer environment variable'ı okur, yoksa veya geçersizse varsayılanı döner
+func envInt(key string, def int) int {
+	if v := os.Getenv(key); v != "" {
+		if i, err := strconv.Atoi(v); err == nil {
+			return i
+		}
+	}
+	return def
+}
+
+// envFloat float environment variable'ı okur, yoksa veya geçersizse varsayılanı döner
+func envFloat(key string, def float64) float64 {
+	if v := os.Getenv(key); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+// envBool bool environment variable'ı okur, yoksa veya geçersizse varsayılanı döner
+func envBool(key string, def bool) bool {
+	if v := os.Getenv(key); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b
+		}
+	}
+	return def
+}
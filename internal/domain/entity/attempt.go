@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// AttemptOutcome bir gönderim denemesinin sonucunu belirtir
+type AttemptOutcome string
+
+const (
+	AttemptSent   AttemptOutcome = "sent"
+	AttemptRetry  AttemptOutcome = "retry"
+	AttemptFailed AttemptOutcome = "failed"
+)
+
+// Attempt bir mesajın webhook'a tek bir gönderim denemesini kaydeder
+type Attempt struct {
+	ID           uint           `json:"id" example:"1"`
+	MessageID    uint           `json:"messageId" example:"1"`
+	Number       int            `json:"number" example:"1"`
+	Outcome      AttemptOutcome `json:"outcome" example:"retry"`
+	StatusCode   int            `json:"statusCode,omitempty" example:"503"`
+	Error        string         `json:"error,omitempty" example:"bad status: 503"`
+	ResponseBody string         `json:"responseBody,omitempty"`
+	LatencyMs    int64          `json:"latencyMs" example:"120"`
+	CreatedAt    time.Time      `json:"createdAt" example:"2024-01-01T12:00:00Z"`
+}
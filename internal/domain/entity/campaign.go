@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// CampaignStatus kampanyanın yaşam döngüsündeki durumunu belirtir
+type CampaignStatus string
+
+const (
+	// CampaignActive mesajları gönderim kuyruğunda olan kampanya
+	CampaignActive CampaignStatus = "active"
+	// CampaignPaused mesajları GetUnsent tarafından atlanan kampanya
+	CampaignPaused CampaignStatus = "paused"
+	// CampaignCancelled bekleyen mesajları iptal edilmiş kampanya, geri alınamaz
+	CampaignCancelled CampaignStatus = "cancelled"
+)
+
+// ErrInvalidTransition kampanya mevcut durumundan istenen duruma geçemezse döner
+var ErrInvalidTransition = errors.New("invalid campaign status transition")
+
+// Campaign birlikte gönderilen mesajları gruplar
+// @Description Campaign grouping many messages
+type Campaign struct {
+	ID          uint           `json:"id" example:"1"`
+	Name        string         `json:"name" example:"spring-sale"`
+	CreatedBy   string         `json:"createdBy,omitempty" example:"marketing-team"`
+	ScheduledAt *time.Time     `json:"scheduledAt,omitempty" example:"2024-01-01T09:00:00Z"`
+	TemplateID  *uint          `json:"templateId,omitempty" example:"1"`
+	Status      CampaignStatus `json:"status" example:"active"`
+	CreatedAt   time.Time      `json:"createdAt" example:"2024-01-01T08:00:00Z"`
+	UpdatedAt   time.Time      `json:"updatedAt" example:"2024-01-01T08:00:00Z"`
+}
+
+// CampaignStats kampanya mesajlarının durumlara göre sayılarıdır. Sağlayıcıdan
+// teslim raporu alınmadığı için delivered sayısı türetilemez; Sent webhook'un
+// kabul ettiği mesajlardır, alıcıya ulaştıkları anlamına gelmez.
+// @Description Message counts per status. There is no delivered count: the provider sends no delivery receipts, so sent only means the webhook accepted the message.
+type CampaignStats struct {
+	Total     int64 `json:"total" example:"1000"`
+	Pending   int64 `json:"pending" example:"400"`
+	Sent      int64 `json:"sent" example:"590"`
+	Failed    int64 `json:"failed" example:"10"`
+	Cancelled int64 `json:"cancelled" example:"0"`
+}
+
+// NewCampaign isim kontrolü yapar ve aktif bir kampanya oluşturur
+func NewCampaign(name, createdBy string, scheduledAt *time.Time, templateID *uint) (*Campaign, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("campaign name required")
+	}
+	return &Campaign{
+		Name:        name,
+		CreatedBy:   strings.TrimSpace(createdBy),
+		ScheduledAt: scheduledAt,
+		TemplateID:  templateID,
+		Status:      CampaignActive,
+	}, nil
+}
+
+// Transition kampanyayı hedef duruma geçirir. İptal edilen kampanya
+// tekrar açılamaz, aynı duruma geçiş hata değildir.
+func (c *Campaign) Transition(to CampaignStatus) error {
+	if c.Status == to {
+		return nil
+	}
+	if c.Status == CampaignCancelled {
+		return fmt.Errorf("%w: campaign is cancelled", ErrInvalidTransition)
+	}
+	switch to {
+	case CampaignActive, CampaignPaused, CampaignCancelled:
+		c.Status = to
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidTransition, to)
+	}
+}
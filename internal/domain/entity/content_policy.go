@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// PolicyAction kural ihlalinde mesaja ne yapılacağını belirtir
+type PolicyAction string
+
+const (
+	// PolicyReject mesaj oluşturulmaz, istek 422 ile döner
+	PolicyReject PolicyAction = "reject"
+	// PolicyQuarantine mesaj kaydedilir ama gönderilmez, sebebi lastError'da tutulur
+	PolicyQuarantine PolicyAction = "quarantine"
+)
+
+// ContentRules giden mesaj içeriğine uygulanan kurallar
+// @Description Content policy rules for outbound messages
+type ContentRules struct {
+	// BlockedKeywords büyük/küçük harf duyarsız yasaklı kelimeler
+	BlockedKeywords []string `json:"blockedKeywords" example:"casino"`
+	// BlockedPatterns Go regexp sözdiziminde yasaklı desenler
+	BlockedPatterns []string `json:"blockedPatterns" example:"(?i)free\\s+money"`
+	// AllowedDomains boş değilse sadece bu domain'lere (ve alt domain'lerine) link verilebilir
+	AllowedDomains []string `json:"allowedDomains" example:"insiderone.com"`
+	// MaxURLs mesajdaki en fazla link sayısı, 0 sınırsız
+	MaxURLs int `json:"maxUrls" example:"1"`
+	// OptOutText marketing mesajlarında bulunması zorunlu metin, boşsa aranmaz
+	OptOutText string       `json:"optOutText" example:"IPTAL yaz 4609'a gönder"`
+	Action     PolicyAction `json:"action" example:"reject" enums:"reject,quarantine"`
+	Version    int64        `json:"version" example:"3"`
+	UpdatedAt  time.Time    `json:"updatedAt" example:"2024-01-01T10:00:00Z"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// Gönderim ayarlarının kabul edilen sınırları
+const (
+	MaxDispatchIntervalSeconds = 86400
+	MaxDispatchBatchSize       = 10000
+	MaxDispatchConcurrency     = 64
+)
+
+// DispatchSettings çalışma zamanında değiştirilebilen gönderim ayarları
+// @Description Runtime dispatch settings shared by all instances
+type DispatchSettings struct {
+	// IntervalSeconds send-batch zamanlamasının aralığı
+	IntervalSeconds int `json:"intervalSeconds" example:"120"`
+	// BatchSize bir çalışmada alınan maksimum mesaj sayısı
+	BatchSize int `json:"batchSize" example:"2"`
+	// Concurrency bir batch içinde aynı anda yapılan webhook isteği sayısı
+	Concurrency int       `json:"concurrency" example:"1"`
+	Version     int64     `json:"version" example:"3"`
+	UpdatedAt   time.Time `json:"updatedAt" example:"2024-01-01T10:00:00Z"`
+}
+
+// Validate ayarların sınırlar içinde olduğunu kontrol eder
+func (s DispatchSettings) Validate() error {
+	if s.IntervalSeconds < 1 || s.IntervalSeconds > MaxDispatchIntervalSeconds {
+		return fmt.Errorf("intervalSeconds must be between 1 and %d", MaxDispatchIntervalSeconds)
+	}
+	if s.BatchSize < 1 || s.BatchSize > MaxDispatchBatchSize {
+		return fmt.Errorf("batchSize must be between 1 and %d", MaxDispatchBatchSize)
+	}
+	if s.Concurrency < 1 || s.Concurrency > MaxDispatchConcurrency {
+		return fmt.Errorf("concurrency must be between 1 and %d", MaxDispatchConcurrency)
+	}
+	return nil
+}
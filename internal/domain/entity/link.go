@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// Link mesaj içindeki bir URL'in yerine konan takipli kısa link
+type Link struct {
+	ID        uint   `json:"id" example:"1"`
+	MessageID uint   `json:"messageId" example:"1"`
+	Code      string `json:"code" example:"aZ3kP9q"`
+	TargetURL string `json:"targetUrl" example:"https://insiderone.com/spring-sale"`
+}
+
+// Click kısa linke yapılan bir tıklama
+type Click struct {
+	ID        uint      `json:"id"`
+	LinkID    uint      `json:"linkId"`
+	MessageID uint      `json:"messageId"`
+	UserAgent string    `json:"userAgent,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// LinkStats bir kısa linkin tıklama özeti
+type LinkStats struct {
+	Code         string     `json:"code" example:"aZ3kP9q"`
+	TargetURL    string     `json:"targetUrl" example:"https://insiderone.com/spring-sale"`
+	Clicks       int64      `json:"clicks" example:"3"`
+	FirstClickAt *time.Time `json:"firstClickAt,omitempty"`
+	LastClickAt  *time.Time `json:"lastClickAt,omitempty"`
+}
+
+// CampaignClickStats kampanya genelinde tıklama özeti. ClickThroughRate en az
+// bir linki tıklanan mesajların gönderilen mesajlara oranıdır.
+type CampaignClickStats struct {
+	CampaignID       uint    `json:"campaignId" example:"1"`
+	Clicks           int64   `json:"clicks" example:"120"`
+	ClickedMessages  int64   `json:"clickedMessages" example:"80"`
+	SentMessages     int64   `json:"sentMessages" example:"1000"`
+	ClickThroughRate float64 `json:"clickThroughRate" example:"0.08"`
+}
@@ -6,17 +6,98 @@ import (
 	"time"
 )
 
+// MessageStatus mesajın gönderim durumunu belirtir
+type MessageStatus string
+
+const (
+	StatusPending MessageStatus = "pending"
+	StatusSent    MessageStatus = "sent"
+	StatusFailed  MessageStatus = "failed"
+	// StatusCancelled kampanyası iptal edildiği için gönderilmeyecek mesaj
+	StatusCancelled MessageStatus = "cancelled"
+	// StatusQuarantined içerik kuralına takıldığı için gönderilmeyen mesaj
+	StatusQuarantined MessageStatus = "quarantined"
+)
+
+// Priority mesajın önceliğini belirtir, limit ve kurallar önceliğe göre değişebilir
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+)
+
+// ParsePriority string değeri Priority'ye çevirir, boş değer normal kabul edilir
+func ParsePriority(s string) (Priority, error) {
+	switch p := Priority(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return PriorityNormal, nil
+	case PriorityLow, PriorityNormal, PriorityHigh:
+		return p, nil
+	default:
+		return "", errors.New("priority must be one of low, normal, high")
+	}
+}
+
+// Category mesajın türünü belirtir. Transactional mesajlar (OTP, bildirim)
+// sessiz saat kurallarından muaftır.
+type Category string
+
+const (
+	CategoryGeneral       Category = "general"
+	CategoryTransactional Category = "transactional"
+	CategoryMarketing     Category = "marketing"
+)
+
+// ParseCategory string değeri Category'ye çevirir, boş değer general kabul edilir
+func ParseCategory(s string) (Category, error) {
+	switch c := Category(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return CategoryGeneral, nil
+	case CategoryGeneral, CategoryTransactional, CategoryMarketing:
+		return c, nil
+	default:
+		return "", errors.New("category must be one of general, transactional, marketing")
+	}
+}
+
+// MessageIDSource webhook mesaj ID'sinin nereden geldiğini belirtir
+type MessageIDSource string
+
+const (
+	// MessageIDProvider ID webhook sağlayıcısının cevabından geldi
+	MessageIDProvider MessageIDSource = "provider"
+	// MessageIDSynthetic sağlayıcı ID dönmediği için bizim ürettiğimiz ID
+	MessageIDSynthetic MessageIDSource = "synthetic"
+	// MessageIDNone sağlayıcı ID dönmedi ve ID üretilmedi
+	MessageIDNone MessageIDSource = "none"
+)
+
 // Message mesaj entity'si
 // @Description Message entity with sending status
 type Message struct {
-	ID           uint       `json:"id" example:"1"`
-	To           string     `json:"to" example:"+905551111111"`
-	Content      string     `json:"content" example:"Hello, this is a test message"`
-	Sent         bool       `json:"sent" example:"true"`
-	SentAt       *time.Time `json:"sentAt,omitempty" example:"2024-01-01T12:00:00Z"`
-	WebhookMsgID string     `json:"webhookMsgId,omitempty" example:"webhook-123"`
-	CreatedAt    time.Time  `json:"createdAt" example:"2024-01-01T10:00:00Z"`
-	UpdatedAt    time.Time  `json:"updatedAt" example:"2024-01-01T10:00:00Z"`
+	ID                 uint              `json:"id" example:"1"`
+	To                 string            `json:"to" example:"+905551111111"`
+	Country            string            `json:"country,omitempty" example:"TR"`
+	Content            string            `json:"content" example:"Hello, this is a test message"`
+	Sent               bool              `json:"sent" example:"true"`
+	Priority           Priority          `json:"priority" example:"normal"`
+	Category           Category          `json:"category" example:"general"`
+	TemplateID         *uint             `json:"templateId,omitempty" example:"1"`
+	CampaignID         *uint             `json:"campaignId,omitempty" example:"1"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	Tags               []string          `json:"tags,omitempty" example:"vip,order"`
+	Links              []Link            `json:"links,omitempty"`
+	Status             MessageStatus     `json:"status" example:"sent"`
+	Attempts           int               `json:"attempts" example:"1"`
+	LastError          string            `json:"lastError,omitempty" example:"bad status: 503"`
+	NextAttemptAt      *time.Time        `json:"nextAttemptAt,omitempty" example:"2024-01-01T12:05:00Z"`
+	SentAt             *time.Time        `json:"sentAt,omitempty" example:"2024-01-01T12:00:00Z"`
+	WebhookMsgID       string            `json:"webhookMsgId,omitempty" example:"webhook-123"`
+	WebhookMsgIDSource MessageIDSource   `json:"webhookMsgIdSource,omitempty" example:"provider"`
+	CreatedAt          time.Time         `json:"createdAt" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt          time.Time         `json:"updatedAt" example:"2024-01-01T10:00:00Z"`
 }
 
 // NewMessage yeni bir mesaj oluşturur ve validasyon yapar
@@ -29,13 +110,15 @@ func NewMessage(to, content string, limit int) (*Message, error) {
 	if len(content) > limit {
 		content = content[:limit]
 	}
-	return &Message{To: to, Content: content}, nil
+	return &Message{To: to, Content: content, Status: StatusPending, Priority: PriorityNormal, Category: CategoryGeneral}, nil
 }
 
 // MarkSent mesajı gönderilmiş olarak işaretler
-func (m *Message) MarkSent(webhookId string) {
+func (m *Message) MarkSent(webhookId string, source MessageIDSource) {
 	now := time.Now().UTC()
 	m.Sent = true
+	m.Status = StatusSent
 	m.SentAt = &now
 	m.WebhookMsgID = webhookId
+	m.WebhookMsgIDSource = source
 }
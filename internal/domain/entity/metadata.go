@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	metadataKeyRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	tagRegex         = regexp.MustCompile(`^[a-z0-9][a-z0-9_:.-]{0,63}$`)
+)
+
+// ValidMetadataKey anahtarın metadata için izin verilen formatta olup olmadığını döndürür
+func ValidMetadataKey(key string) bool {
+	return metadataKeyRegex.MatchString(key)
+}
+
+// SetMetadata anahtarları ve JSON boyutunu kontrol edip metadata'yı mesaja ekler
+func (m *Message) SetMetadata(md map[string]string, maxBytes int) error {
+	if len(md) == 0 {
+		m.Metadata = nil
+		return nil
+	}
+	for k := range md {
+		if !ValidMetadataKey(k) {
+			return fmt.Errorf("invalid metadata key %q: use 1-64 letters, digits, '_' or '-'", k)
+		}
+	}
+	b, err := json.Marshal(md)
+	if err != nil {
+		return err
+	}
+	if len(b) > maxBytes {
+		return fmt.Errorf("metadata is %d bytes, limit is %d", len(b), maxBytes)
+	}
+	m.Metadata = md
+	return nil
+}
+
+// SetTags etiketleri küçük harfe çevirir, tekrarları atar ve formatını kontrol eder
+func (m *Message) SetTags(tags []string, max int) error {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if seen[t] {
+			continue
+		}
+		if !tagRegex.MatchString(t) {
+			return fmt.Errorf("invalid tag %q: use 1-64 lowercase letters, digits, '_', ':', '.' or '-'", t)
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > max {
+		return fmt.Errorf("at most %d tags allowed", max)
+	}
+	sort.Strings(out)
+	if len(out) == 0 {
+		out = nil
+	}
+	m.Tags = out
+	return nil
+}
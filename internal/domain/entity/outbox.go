@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// OutboxEventType outbox'a yazılan olay türü
+type OutboxEventType string
+
+const (
+	EventMessageCreated     OutboxEventType = "message.created"
+	EventMessageSent        OutboxEventType = "message.sent"
+	EventMessageFailed      OutboxEventType = "message.failed"
+	EventMessageQuarantined OutboxEventType = "message.quarantined"
+	EventMessageCancelled   OutboxEventType = "message.cancelled"
+)
+
+// OutboxEvent mesaj değişikliğiyle aynı transaction'da yazılan ve relay
+// tarafından sink'lere yayınlanan olay. Olaylar en az bir kez yayınlanır;
+// alıcılar tekrarları ID ile ayıklamalıdır.
+type OutboxEvent struct {
+	ID        uint            `json:"id"`
+	Type      OutboxEventType `json:"type"`
+	MessageID uint            `json:"messageId"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+	// Attempts ve LastError başarısız yayın denemeleri
+	Attempts    int        `json:"-"`
+	LastError   string     `json:"-"`
+	PublishedAt *time.Time `json:"-"`
+}
+
+// MessageEvent mesaj olaylarının içeriği. created olayında mesajın tamamı,
+// durum değişikliklerinde değişen alanlar ve olayın eşleştirilebilmesi için
+// alıcı, kampanya, etiket ve metadata bulunur.
+type MessageEvent struct {
+	MessageID          uint              `json:"messageId"`
+	Status             MessageStatus     `json:"status"`
+	To                 string            `json:"to,omitempty"`
+	Priority           Priority          `json:"priority,omitempty"`
+	Category           Category          `json:"category,omitempty"`
+	TemplateID         *uint             `json:"templateId,omitempty"`
+	CampaignID         *uint             `json:"campaignId,omitempty"`
+	Tags               []string          `json:"tags,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+	WebhookMsgID       string            `json:"webhookMessageId,omitempty"`
+	WebhookMsgIDSource MessageIDSource   `json:"webhookMessageIdSource,omitempty"`
+	Reason             string            `json:"reason,omitempty"`
+	OccurredAt         time.Time         `json:"occurredAt"`
+}
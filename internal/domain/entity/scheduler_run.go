@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// SchedulerRun tek bir send-batch çalışmasının kaydı
+// @Description One batch run of the scheduler
+type SchedulerRun struct {
+	ID uint `json:"id" example:"42"`
+	// Trigger çalışmayı başlatan zamanlamanın adı veya manual
+	Trigger string `json:"trigger" example:"send-batch"`
+	// RequestedBy elle tetiklenen çalışmalarda isteği yapan
+	RequestedBy string    `json:"requestedBy,omitempty" example:"ops@example.com (api-key:1a2b3c4d)"`
+	Instance    string    `json:"instance" example:"messaging-7d9f-abcde"`
+	StartedAt   time.Time `json:"startedAt" example:"2024-01-01T12:00:00Z"`
+	FinishedAt  time.Time `json:"finishedAt" example:"2024-01-01T12:00:01Z"`
+	DurationMs  int64     `json:"durationMs" example:"850"`
+	BatchSize   int       `json:"batchSize" example:"2"`
+	// Sizing batch boyutunun nasıl belirlendiği: fixed, adaptive veya manual
+	Sizing string `json:"sizing,omitempty" example:"adaptive"`
+	// Backlog adaptive modda boyut hesaplanırken gönderilmeye hazır mesaj sayısı
+	Backlog int64 `json:"backlog,omitempty" example:"1250"`
+	Fetched int   `json:"fetched" example:"2"`
+	Sent    int   `json:"sent" example:"2"`
+	// Failed kalıcı başarısız olan, Retried tekrar denenmek üzere ertelenen mesajlar
+	Failed  int `json:"failed" example:"0"`
+	Retried int `json:"retried" example:"0"`
+	// Skipped içerik, sessiz saat veya numara limiti nedeniyle gönderilmeyen mesajlar
+	Skipped int    `json:"skipped" example:"0"`
+	Error   string `json:"error,omitempty"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+// SchedulerState tüm instance'ların paylaştığı scheduler durumu. Running istenen
+// durumdur; scheduler'ı sadece lider instance çalıştırır.
+// @Description Cluster-wide desired scheduler state and current leader
+type SchedulerState struct {
+	Running   bool      `json:"running" example:"true"`
+	StartedBy string    `json:"startedBy,omitempty" example:"ops@example.com (api-key:1a2b3c4d)"`
+	StartedAt time.Time `json:"startedAt" example:"2024-01-01T09:00:00Z"`
+	StoppedBy string    `json:"stoppedBy,omitempty" example:"ops@example.com (api-key:1a2b3c4d)"`
+	StoppedAt time.Time `json:"stoppedAt" example:"2024-01-01T08:59:00Z"`
+	// Leader liderliği en son alan instance ve ne zaman aldığı
+	Leader      string    `json:"leader,omitempty" example:"messaging-7d9f-abcde/3f9a1c"`
+	LeaderSince time.Time `json:"leaderSince" example:"2024-01-01T09:00:05Z"`
+}
+
+// SchedulerStateChange istenen scheduler durumundaki bir değişikliğin denetim kaydı
+// @Description Audit record of a scheduler start or stop
+type SchedulerStateChange struct {
+	ID      uint `json:"id" example:"7"`
+	Running bool `json:"running" example:"true"`
+	// Actor X-Actor header'ı veya AUTO_START gibi sistem kaynağı
+	Actor string `json:"actor,omitempty" example:"ops@example.com"`
+	// APIKey isteğin yapıldığı API key'in özeti, anahtarın kendisi saklanmaz
+	APIKey    string    `json:"apiKey,omitempty" example:"api-key:1a2b3c4d"`
+	Instance  string    `json:"instance" example:"messaging-7d9f-abcde/3f9a1c"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-01-01T09:00:00Z"`
+}
@@ -0,0 +1,138 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var placeholderRegex = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_.]*)\s*\}\}`)
+
+// ErrLocaleNotFound şablonda istenen dil ve varsayılan dil için varyant yoksa döner
+var ErrLocaleNotFound = errors.New("template has no variant for locale")
+
+// MissingVariablesError render sırasında değeri verilmeyen değişkenleri listeler
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return "missing template variables: " + strings.Join(e.Names, ", ")
+}
+
+// TemplateVariant şablonun bir dildeki metnidir
+type TemplateVariant struct {
+	Locale string `json:"locale" example:"tr"`
+	Body   string `json:"body" example:"Merhaba {{name}}, siparişiniz {{orderId}} yola çıktı"`
+}
+
+// Template isimli, dil varyantlı mesaj şablonu
+// @Description Message template with per-locale variants
+type Template struct {
+	ID            uint              `json:"id" example:"1"`
+	Name          string            `json:"name" example:"order-shipped"`
+	DefaultLocale string            `json:"defaultLocale" example:"tr"`
+	Variants      []TemplateVariant `json:"variants"`
+	CreatedAt     time.Time         `json:"createdAt" example:"2024-01-01T10:00:00Z"`
+	UpdatedAt     time.Time         `json:"updatedAt" example:"2024-01-01T10:00:00Z"`
+}
+
+// NormalizeLocale dil kodunu karşılaştırılabilir hale getirir (tr_TR -> tr-tr)
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// Validate şablonun isim, varyant ve varsayılan dil kurallarını kontrol eder,
+// dil kodlarını normalize eder
+func (t *Template) Validate() error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return errors.New("template name required")
+	}
+	if len(t.Variants) == 0 {
+		return errors.New("template needs at least one variant")
+	}
+	seen := make(map[string]bool, len(t.Variants))
+	for i := range t.Variants {
+		v := &t.Variants[i]
+		v.Locale = NormalizeLocale(v.Locale)
+		if v.Locale == "" {
+			return errors.New("variant locale required")
+		}
+		if seen[v.Locale] {
+			return fmt.Errorf("duplicate variant for locale %q", v.Locale)
+		}
+		seen[v.Locale] = true
+		if strings.TrimSpace(v.Body) == "" {
+			return fmt.Errorf("variant %q has empty body", v.Locale)
+		}
+	}
+	t.DefaultLocale = NormalizeLocale(t.DefaultLocale)
+	if t.DefaultLocale == "" {
+		t.DefaultLocale = t.Variants[0].Locale
+	}
+	if !seen[t.DefaultLocale] {
+		return fmt.Errorf("default locale %q has no variant", t.DefaultLocale)
+	}
+	return nil
+}
+
+// Variant istenen dil için varyantı bulur. Sırasıyla tam eşleşme (tr-tr),
+// dil kodu (tr) ve varsayılan dil denenir.
+func (t *Template) Variant(locale string) (*TemplateVariant, error) {
+	locale = NormalizeLocale(locale)
+	candidates := []string{locale}
+	if i := strings.Index(locale, "-"); i > 0 {
+		candidates = append(candidates, locale[:i])
+	}
+	candidates = append(candidates, NormalizeLocale(t.DefaultLocale))
+	for _, c := range candidates {
+		if c == "" {
+			continue
+		}
+		for i := range t.Variants {
+			if NormalizeLocale(t.Variants[i].Locale) == c {
+				return &t.Variants[i], nil
+			}
+		}
+	}
+	return nil, ErrLocaleNotFound
+}
+
+// Variables metindeki değişken isimlerini sıralı ve tekil olarak döndürür
+func Variables(body string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, m := range placeholderRegex.FindAllStringSubmatch(body, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render seçilen dil varyantındaki değişkenleri doldurur. Metindeki her değişken
+// zorunludur, eksik olanlar MissingVariablesError ile döner.
+func (t *Template) Render(locale string, vars map[string]string) (string, error) {
+	v, err := t.Variant(locale)
+	if err != nil {
+		return "", err
+	}
+	var missing []string
+	for _, name := range Variables(v.Body) {
+		if _, ok := vars[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", &MissingVariablesError{Names: missing}
+	}
+	return placeholderRegex.ReplaceAllStringFunc(v.Body, func(s string) string {
+		return vars[placeholderRegex.FindStringSubmatch(s)[1]]
+	}), nil
+}
@@ -0,0 +1,114 @@
+package phone
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+//go:embed metadata.json
+var metadataJSON []byte
+
+// regionJSON metadata.json içindeki bir ülke kaydı. Desenler ulusal numaraya
+// (ülke kodu ve ulusal önek olmadan) uygulanır.
+type regionJSON struct {
+	Region            string   `json:"region"`
+	CallingCode       string   `json:"callingCode"`
+	NationalPrefix    string   `json:"nationalPrefix"`
+	Leading           string   `json:"leading"`
+	General           string   `json:"general"`
+	Mobile            string   `json:"mobile"`
+	FixedLine         string   `json:"fixedLine"`
+	FixedLineOrMobile string   `json:"fixedLineOrMobile"`
+	TollFree          string   `json:"tollFree"`
+	Zones             []string `json:"zones"`
+}
+
+// region derlenmiş ülke kuralları
+type region struct {
+	code           string
+	callingCode    string
+	nationalPrefix string
+	leading        *regexp.Regexp
+	general        *regexp.Regexp
+	types          []typePattern
+	zones          []string
+}
+
+type typePattern struct {
+	typ     Type
+	pattern *regexp.Regexp
+}
+
+var (
+	regionsByCode map[string]*region
+	// regionsByCallingCode aynı ülke kodunu paylaşan bölgeler; leading deseni
+	// olanlar önce, ana bölge en sonda
+	regionsByCallingCode map[string][]*region
+)
+
+func init() {
+	var raw []regionJSON
+	if err := json.Unmarshal(metadataJSON, &raw); err != nil {
+		panic(fmt.Sprintf("phone: invalid metadata: %v", err))
+	}
+	regionsByCode = make(map[string]*region, len(raw))
+	regionsByCallingCode = make(map[string][]*region)
+	for _, r := range raw {
+		reg := &region{
+			code:           r.Region,
+			callingCode:    r.CallingCode,
+			nationalPrefix: r.NationalPrefix,
+			leading:        compile(r.Leading),
+			general:        compile(r.General),
+			zones:          r.Zones,
+		}
+		// tollFree önce denenir, mobil ve sabit hat desenleri onu da kapsayabilir
+		for _, tp := range []struct {
+			typ Type
+			pat string
+		}{
+			{TypeTollFree, r.TollFree},
+			{TypeMobile, r.Mobile},
+			{TypeFixedLine, r.FixedLine},
+			{TypeFixedLineOrMobile, r.FixedLineOrMobile},
+		} {
+			if p := compile(tp.pat); p != nil {
+				reg.types = append(reg.types, typePattern{typ: tp.typ, pattern: p})
+			}
+		}
+		regionsByCode[reg.code] = reg
+		regionsByCallingCode[reg.callingCode] = append(regionsByCallingCode[reg.callingCode], reg)
+	}
+	for _, regs := range regionsByCallingCode {
+		sort.SliceStable(regs, func(i, j int) bool { return regs[i].leading != nil && regs[j].leading == nil })
+	}
+}
+
+// compile boş olmayan deseni derler, metadata hatalıysa paniğe düşer
+func compile(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+	return regexp.MustCompile(pattern)
+}
+
+// Regions metadata'sı bulunan bölge kodlarını sıralı döndürür
+func Regions() []string {
+	out := make([]string, 0, len(regionsByCode))
+	for code := range regionsByCode {
+		out = append(out, code)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// TimeZones bölgenin IANA saat dilimlerini döndürür, bilinmeyen bölge için nil
+func TimeZones(regionCode string) []string {
+	if r, ok := regionsByCode[regionCode]; ok {
+		return r.zones
+	}
+	return nil
+}
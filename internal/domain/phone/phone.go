@@ -0,0 +1,164 @@
+// Package phone telefon numaralarını E.164 formatına çevirir ve gömülü ülke
+// kurallarına göre doğrular. Kurallar yaygın numara planlarını kapsar,
+// libphonenumber kadar ayrıntılı değildir.
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Type numaranın türünü belirtir
+type Type string
+
+const (
+	TypeMobile            Type = "mobile"
+	TypeFixedLine         Type = "fixed_line"
+	TypeFixedLineOrMobile Type = "fixed_line_or_mobile"
+	TypeTollFree          Type = "toll_free"
+	TypeUnknown           Type = "unknown"
+)
+
+var (
+	// ErrInvalidFormat numara rakam dışı karakter içeriyor veya uzunluğu E.164'e uymuyor
+	ErrInvalidFormat = errors.New("phone number must contain 8-15 digits, optionally prefixed with + or 00")
+	// ErrNoRegion ulusal formattaki numara için varsayılan bölge verilmemiş
+	ErrNoRegion = errors.New("national phone number needs a default region")
+)
+
+// Number çözümlenmiş telefon numarası
+type Number struct {
+	// E164 normalize edilmiş numara, örn. +905551111111
+	E164 string `json:"e164"`
+	// Region ISO 3166-1 alpha-2 ülke kodu, metadata'sı olmayan ülkelerde boş
+	Region      string `json:"region,omitempty"`
+	CallingCode string `json:"callingCode"`
+	// National ülke kodu ve ulusal önek olmadan numara
+	National string `json:"national"`
+	Type     Type   `json:"type"`
+}
+
+// Parse numarayı E.164'e çevirir ve doğrular. "+" veya "00" ile başlayan
+// numaralar uluslararası, diğerleri defaultRegion'a ait ulusal numara kabul
+// edilir. Boşluk, tire, nokta ve parantezler yok sayılır.
+func Parse(raw, defaultRegion string) (Number, error) {
+	s := strings.TrimSpace(raw)
+	international := false
+	switch {
+	case strings.HasPrefix(s, "+"):
+		international, s = true, s[1:]
+	case strings.HasPrefix(s, "00"):
+		international, s = true, s[2:]
+	}
+	digits, ok := stripFormatting(s)
+	if !ok || digits == "" {
+		return Number{}, ErrInvalidFormat
+	}
+
+	if !international {
+		reg, ok := regionsByCode[strings.ToUpper(strings.TrimSpace(defaultRegion))]
+		if !ok {
+			return Number{}, ErrNoRegion
+		}
+		digits = reg.callingCode + stripNationalPrefix(reg, digits)
+	}
+	if len(digits) < 8 || len(digits) > 15 || digits[0] == '0' {
+		return Number{}, ErrInvalidFormat
+	}
+
+	callingCode, regs := lookupCallingCode(digits)
+	if callingCode == "" {
+		// metadata'sı olmayan ülke: sadece E.164 yapısı kontrol edilir
+		return Number{E164: "+" + digits, National: digits, Type: TypeUnknown}, nil
+	}
+	national := digits[len(callingCode):]
+	reg := pickRegion(regs, national)
+	if !reg.general.MatchString(national) {
+		return Number{}, fmt.Errorf("invalid phone number for region %s", reg.code)
+	}
+	return Number{
+		E164:        "+" + digits,
+		Region:      reg.code,
+		CallingCode: callingCode,
+		National:    national,
+		Type:        reg.classify(national),
+	}, nil
+}
+
+// RegionOf E.164 numaranın ülke kodundan bölgesini bulur, doğrulama yapmaz
+func RegionOf(e164 string) string {
+	digits := strings.TrimPrefix(e164, "+")
+	callingCode, regs := lookupCallingCode(digits)
+	if callingCode == "" {
+		return ""
+	}
+	return pickRegion(regs, digits[len(callingCode):]).code
+}
+
+// stripFormatting yaygın ayraçları atar, rakam dışı karakter varsa false döner
+func stripFormatting(s string) (string, bool) {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false
+		}
+	}
+	return b.String(), true
+}
+
+// stripNationalPrefix ulusal öneki, kalan numara ülke kodunun numara planına
+// (uzunluk ve ilk rakamlar) uyuyorsa atar. Önek rakamı geçerli bir numaranın ilk
+// rakamı da olabildiği için (ör. RU'da 8 ve 800'lü numaralar) önek olmadan
+// yazılmış geçerli numara olduğu gibi bırakılır.
+func stripNationalPrefix(reg *region, digits string) string {
+	if reg.nationalPrefix == "" || !strings.HasPrefix(digits, reg.nationalPrefix) {
+		return digits
+	}
+	national := digits[len(reg.nationalPrefix):]
+	if validNational(reg.callingCode, national) || !validNational(reg.callingCode, digits) {
+		return national
+	}
+	return digits
+}
+
+// validNational numara ülke kodunu paylaşan bölgelerden birinin planına uyuyorsa true döner
+func validNational(callingCode, national string) bool {
+	regs := regionsByCallingCode[callingCode]
+	return len(regs) > 0 && pickRegion(regs, national).general.MatchString(national)
+}
+
+// lookupCallingCode ülke kodunu bulur. E.164 ülke kodları önek-bağımsız
+// olduğundan 1-3 haneden ilk eşleşen doğrudur.
+func lookupCallingCode(digits string) (string, []*region) {
+	for n := 1; n <= 3 && n <= len(digits); n++ {
+		if regs, ok := regionsByCallingCode[digits[:n]]; ok {
+			return digits[:n], regs
+		}
+	}
+	return "", nil
+}
+
+// pickRegion ortak ülke kodunda (ör. +1) numaranın başına göre bölgeyi seçer
+func pickRegion(regs []*region, national string) *region {
+	for _, r := range regs {
+		if r.leading == nil || r.leading.MatchString(national) {
+			return r
+		}
+	}
+	return regs[len(regs)-1]
+}
+
+// classify numara türünü desenlere göre belirler
+func (r *region) classify(national string) Type {
+	for _, tp := range r.types {
+		if tp.pattern.MatchString(national) {
+			return tp.typ
+		}
+	}
+	return TypeUnknown
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"errors"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+// ErrStatusChanged kampanya durumu okunduktan sonra başka bir istekle değiştiyse döner
+var ErrStatusChanged = errors.New("campaign status changed concurrently")
+
+type CampaignRepository interface {
+	// Create kampanyayı ve mesajlarını tek transaction'da kaydeder
+	Create(c *entity.Campaign, msgs []*entity.Message) error
+	Get(id uint) (*entity.Campaign, error)
+	List() ([]*entity.Campaign, error)
+	// SetStatus kampanya durumu hâlâ from ise to yapar, iptalde bekleyen mesajlar
+	// da iptal edilir. Durum değişmişse ErrStatusChanged döner.
+	SetStatus(id uint, from, to entity.CampaignStatus) error
+	Stats(id uint) (entity.CampaignStats, error)
+}
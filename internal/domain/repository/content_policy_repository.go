@@ -0,0 +1,10 @@
+package repository
+
+import "insider-messaging/internal/domain/entity"
+
+type ContentPolicyRepository interface {
+	// Load kayıtlı kuralları döndürür, hiç kaydedilmemişse nil döner
+	Load() (*entity.ContentRules, error)
+	// Save kuralları kaydeder ve versiyonu bir artırır
+	Save(r *entity.ContentRules) error
+}
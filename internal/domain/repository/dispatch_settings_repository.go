@@ -0,0 +1,10 @@
+package repository
+
+import "insider-messaging/internal/domain/entity"
+
+type DispatchSettingsRepository interface {
+	// Load kayıtlı ayarları döndürür, hiç kaydedilmemişse nil döner
+	Load() (*entity.DispatchSettings, error)
+	// Save ayarları kaydeder ve versiyonu bir artırır
+	Save(s *entity.DispatchSettings) error
+}
@@ -0,0 +1,12 @@
+package repository
+
+import "insider-messaging/internal/domain/entity"
+
+// LinkRepository kısa linkler mesajla birlikte MessageRepository.Create ile
+// kaydedilir, bu repository yönlendirme ve tıklama istatistikleri içindir
+type LinkRepository interface {
+	FindByCode(code string) (*entity.Link, error)
+	RecordClick(c *entity.Click) error
+	MessageClicks(messageID uint) ([]entity.LinkStats, error)
+	CampaignClicks(campaignID uint) (entity.CampaignClickStats, error)
+}
@@ -1,10 +1,46 @@
 package repository
 
-import "insider-messaging/internal/domain/entity"
+import (
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+// MessageFilter listeleme filtreleri, boş alanlar filtrelenmez
+type MessageFilter struct {
+	Tag           string
+	MetadataKey   string
+	MetadataValue string
+}
+
+// QueueStats gönderim kuyruğunun derinliği
+type QueueStats struct {
+	// Pending gönderilmeyi bekleyen tüm mesajlar, ertelenenler dahil
+	Pending int64 `json:"pending" example:"120"`
+	// Due şu an bir batch'e alınabilecek mesajlar
+	Due int64 `json:"due" example:"40"`
+}
 
 type MessageRepository interface {
 	GetUnsent(limit int) ([]*entity.Message, error)
-	MarkSent(id uint, webhookMsgId string) error
-	ListSent() ([]*entity.Message, error)
+	// GetDue ids içinden şu an gönderilmeye hazır olan mesajları döner
+	GetDue(ids []uint) ([]*entity.Message, error)
+	MarkSent(id uint, webhookMsgId string, source entity.MessageIDSource) error
+	ListSent(f MessageFilter) ([]*entity.Message, error)
 	Create(msg *entity.Message) error
+	// RecordAttempt deneme kaydını ekler ve mesajın deneme sayacını artırır
+	RecordAttempt(a *entity.Attempt) error
+	// Defer mesajı until zamanına kadar GetUnsent sonuçlarından çıkarır
+	Defer(id uint, until time.Time) error
+	// MarkFailed mesajı kalıcı olarak başarısız işaretler, tekrar denenmez
+	MarkFailed(id uint, reason string) error
+	// Quarantine mesajı içerik kuralı ihlali nedeniyle gönderimden çıkarır
+	Quarantine(id uint, reason string) error
+	// SentSince since'ten sonra gönderilmiş mesajları döner
+	SentSince(since time.Time) ([]*entity.Message, error)
+	// PurgeBefore before'dan önce tamamlanmış (sent, failed, cancelled) mesajları
+	// ve bağlı kayıtlarını siler, silinen mesaj sayısını döner
+	PurgeBefore(before time.Time) (int64, error)
+	// QueueStats bekleyen ve zamanı gelmiş mesaj sayılarını döner
+	QueueStats() (QueueStats, error)
 }
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+// OutboxRepository yayınlanmayı bekleyen outbox olaylarını yönetir. Olaylar
+// mesaj repository'leri tarafından değişiklikle aynı transaction'da yazılır.
+type OutboxRepository interface {
+	// Pending yayınlanmamış olayları yazılma sırasıyla limit kadar döner
+	Pending(limit int) ([]*entity.OutboxEvent, error)
+	// MarkPublished olayları yayınlandı olarak işaretler
+	MarkPublished(ids []uint) error
+	// MarkFailed yayınlanamayan olayın deneme sayısını artırır ve hatayı yazar
+	MarkFailed(id uint, reason string) error
+	// MarkDead deneme hakkı biten olayı dead-letter'a ayırır, Pending artık döndürmez
+	MarkDead(id uint, reason string) error
+	// DeadCount dead-letter'daki olay sayısını döner
+	DeadCount() (int64, error)
+	// PurgePublished before'dan önce yayınlanmış olayları siler, silinen sayıyı döner
+	PurgePublished(before time.Time) (int64, error)
+}
@@ -0,0 +1,10 @@
+package repository
+
+import "insider-messaging/internal/domain/entity"
+
+type SchedulerRunRepository interface {
+	// Record çalışmayı ekler ve en yeni keep kayıt dışındakileri siler
+	Record(run *entity.SchedulerRun, keep int) error
+	// Recent en yeni limit çalışmayı yeniden eskiye döner
+	Recent(limit int) ([]*entity.SchedulerRun, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+type SchedulerStateRepository interface {
+	// Load kayıtlı durumu döndürür, hiç kaydedilmemişse nil döner
+	Load() (*entity.SchedulerState, error)
+	// InitDesired durum hiç kaydedilmemişse s'yi denetim kaydıyla birlikte yazar,
+	// yazıldıysa true döner
+	InitDesired(s *entity.SchedulerState, change *entity.SchedulerStateChange) (bool, error)
+	// SaveDesired istenen durumu ve denetim kaydını aynı transaction'da yazar,
+	// lider bilgisine dokunmaz
+	SaveDesired(s *entity.SchedulerState, change *entity.SchedulerStateChange) error
+	// SetLeader liderliği alan instance'ı kaydeder, istenen duruma dokunmaz
+	SetLeader(leader string, since time.Time) error
+	// Changes en yeni limit denetim kaydını yeniden eskiye döner
+	Changes(limit int) ([]*entity.SchedulerStateChange, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"errors"
+
+	"insider-messaging/internal/domain/entity"
+)
+
+// ErrNotFound aranan kayıt yoksa döner
+var ErrNotFound = errors.New("record not found")
+
+type TemplateRepository interface {
+	Create(t *entity.Template) error
+	// Update şablonun adını, varsayılan dilini ve tüm varyantlarını değiştirir
+	Update(t *entity.Template) error
+	Get(id uint) (*entity.Template, error)
+	List() ([]*entity.Template, error)
+	Delete(id uint) error
+}
@@ -0,0 +1,57 @@
+package cache
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"insider-messaging/internal/application"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ application.MessageNotifier = (*RedisDispatchNotifier)(nil)
+
+// RedisDispatchNotifier yeni mesaj bildirimini yerel dispatcher'a iletir ve Redis
+// pub/sub ile diğer instance'lara yayar; böylece mesaj hangi instance'ta oluşursa
+// oluşsun scheduler'ı çalıştıran instance hemen uyanır
+type RedisDispatchNotifier struct {
+	rdb     *redis.Client
+	channel string
+	local   *application.DispatchSignal
+}
+
+// NewRedisDispatchNotifier channel üzerinden yayın yapan bir notifier oluşturur
+func NewRedisDispatchNotifier(rdb *redis.Client, channel string, local *application.DispatchSignal) *RedisDispatchNotifier {
+	return &RedisDispatchNotifier{rdb: rdb, channel: channel, local: local}
+}
+
+// Notify yerel dispatcher'ı uyandırır ve diğer instance'lara yayın yapar. Yayın
+// başarısız olursa mesaj bir sonraki taramada gönderilir.
+func (n *RedisDispatchNotifier) Notify() {
+	n.local.Notify()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := n.rdb.Publish(ctx, n.channel, "1").Err(); err != nil {
+		log.Printf("dispatch notify publish failed: %v", err)
+	}
+}
+
+// Run ctx kapanana kadar diğer instance'ların yayınlarını dinleyip yerel
+// dispatcher'ı uyandırır. Bağlantı koparsa abonelik kendiliğinden yenilenir.
+func (n *RedisDispatchNotifier) Run(ctx context.Context) {
+	sub := n.rdb.Subscribe(ctx, n.channel)
+	defer sub.Close()
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+			n.local.Notify()
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"insider-messaging/internal/application"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ application.LeaderLock = (*RedisLeaderLock)(nil)
+
+// renewScript kilidin süresini sadece sahibi hâlâ aynıysa uzatır
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+
+// releaseScript kilidi sadece sahibi hâlâ aynıysa siler
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+// RedisLeaderLock liderlik kilidini SET NX PX ile tutar, değer sahibin adıdır.
+// Lider süre dolmadan yenileyemezse kilit kendiliğinden düşer.
+type RedisLeaderLock struct {
+	rdb *redis.Client
+	key string
+}
+
+// NewRedisLeaderLock key üzerinde yeni bir liderlik kilidi oluşturur
+func NewRedisLeaderLock(rdb *redis.Client, key string) *RedisLeaderLock {
+	return &RedisLeaderLock{rdb: rdb, key: key}
+}
+
+// Acquire kilidi alır veya owner'daysa süresini uzatır
+func (l *RedisLeaderLock) Acquire(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	ok, err := l.rdb.SetNX(ctx, l.key, owner, ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+	n, err := renewScript.Run(ctx, l.rdb, []string{l.key}, owner, ttl.Milliseconds()).Int()
+	return n == 1, err
+}
+
+// Release kilit owner'daysa siler
+func (l *RedisLeaderLock) Release(ctx context.Context, owner string) error {
+	return releaseScript.Run(ctx, l.rdb, []string{l.key}, owner).Err()
+}
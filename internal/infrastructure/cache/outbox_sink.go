@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ application.OutboxSink = (*RedisStreamSink)(nil)
+
+// outboxStreamMaxLen stream'de tutulan yaklaşık olay sayısı, okunmayan stream sınırsız büyümesin
+const outboxStreamMaxLen = 100000
+
+// RedisStreamSink outbox olaylarını bir Redis stream'ine ekler
+type RedisStreamSink struct {
+	rdb    *redis.Client
+	stream string
+}
+
+// NewRedisStreamSink stream'e yazan bir sink oluşturur
+func NewRedisStreamSink(rdb *redis.Client, stream string) *RedisStreamSink {
+	return &RedisStreamSink{rdb: rdb, stream: stream}
+}
+
+// Name sink adı
+func (s *RedisStreamSink) Name() string { return config.OutboxSinkRedis }
+
+// Publish olayı stream'e ekler; alıcılar tekrarları eventId ile ayıklar
+func (s *RedisStreamSink) Publish(ctx context.Context, e *entity.OutboxEvent) error {
+	return s.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream, MaxLen: outboxStreamMaxLen, Approx: true,
+		Values: map[string]interface{}{
+			"eventId": e.ID, "type": string(e.Type), "messageId": e.MessageID, "payload": string(e.Payload),
+		},
+	}).Err()
+}
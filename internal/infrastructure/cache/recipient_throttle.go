@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ application.RecipientLimiter = (*RecipientThrottle)(nil)
+
+// RecipientThrottle numara başına gönderimleri Redis'te sabit pencereli sayaçlarla sınırlar.
+// Aynı pencere uzunluğunu kullanan öncelikler aynı sayacı paylaşır. Limit kontrolü
+// ve sayaç artışı tek bir Lua script'iyle yapılır; aynı anda çalışan worker'lar ve
+// instance'lar aynı boş yeri ikinci kez alamaz.
+type RecipientThrottle struct {
+	rdb     *redis.Client
+	limits  map[entity.Priority]config.RateLimit
+	windows []time.Duration
+	now     func() time.Time
+
+	mu sync.Mutex
+	// reserved onaylanmamış yerlerin anahtarları; pencere arada değişse de geri
+	// verme ayrıldığı sayaçlardan yapılır
+	reserved map[uint][]string
+}
+
+// throttleReserveScript KEYS'teki bütün pencere sayaçlarını artırır. ARGV[1] limiti
+// kontrol edilecek sayacın sırası (0 ise limit yok), ARGV[2] limit, sonrakiler
+// her sayacın saniye cinsinden ömrü. Limit doluysa hiçbir sayaç artmaz ve 0 döner.
+var throttleReserveScript = redis.NewScript(`
+local i = tonumber(ARGV[1])
+if i > 0 and tonumber(redis.call("GET", KEYS[i]) or "0") >= tonumber(ARGV[2]) then
+	return 0
+end
+for k = 1, #KEYS do
+	redis.call("INCR", KEYS[k])
+	redis.call("EXPIRE", KEYS[k], ARGV[k + 2])
+end
+return 1`)
+
+// throttleReleaseScript süresi dolmamış sayaçları bir azaltır; süresi dolan sayaç
+// yeniden oluşturulmaz
+var throttleReleaseScript = redis.NewScript(`
+for k = 1, #KEYS do
+	if tonumber(redis.call("GET", KEYS[k]) or "0") > 0 then
+		redis.call("DECR", KEYS[k])
+	end
+end
+return 1`)
+
+// NewRecipientThrottle config'teki limitlerle yeni bir throttle oluşturur
+func NewRecipientThrottle(rdb *redis.Client, limits map[string]config.RateLimit) *RecipientThrottle {
+	t := &RecipientThrottle{rdb: rdb, limits: map[entity.Priority]config.RateLimit{}, now: time.Now, reserved: map[uint][]string{}}
+	seen := map[time.Duration]bool{}
+	for name, l := range limits {
+		t.limits[entity.Priority(name)] = l
+		if !seen[l.Window] {
+			seen[l.Window] = true
+			t.windows = append(t.windows, l.Window)
+		}
+	}
+	sort.Slice(t.windows, func(i, j int) bool { return t.windows[i] < t.windows[j] })
+	return t
+}
+
+// Allow mesajın önceliğine ait limit dolmamışsa numaranın bütün pencere
+// sayaçlarında yer ayırır
+func (t *RecipientThrottle) Allow(ctx context.Context, m *entity.Message) (bool, time.Time, error) {
+	if len(t.windows) == 0 {
+		return true, time.Time{}, nil
+	}
+	l, limited := t.limits[m.Priority]
+	limited = limited && l.Max > 0
+	keys := make([]string, len(t.windows))
+	args := []interface{}{0, l.Max}
+	var resetsAt time.Time
+	for i, w := range t.windows {
+		key, resets := t.key(m.To, w)
+		keys[i] = key
+		args = append(args, int64((w+time.Minute)/time.Second))
+		if limited && w == l.Window {
+			args[0], resetsAt = i+1, resets
+		}
+	}
+	ok, err := throttleReserveScript.Run(ctx, t.rdb, keys, args...).Int()
+	if err != nil {
+		return true, time.Time{}, err
+	}
+	if ok == 0 {
+		return false, resetsAt, nil
+	}
+	t.mu.Lock()
+	t.reserved[m.ID] = keys
+	t.mu.Unlock()
+	return true, time.Time{}, nil
+}
+
+// Record ayrılan yeri gönderim olarak bırakır, sayaçlar Allow'da artmıştır
+func (t *RecipientThrottle) Record(ctx context.Context, m *entity.Message) error {
+	t.take(m.ID)
+	return nil
+}
+
+// Release ayrılan yeri sayaçlardan geri alır
+func (t *RecipientThrottle) Release(ctx context.Context, m *entity.Message) error {
+	keys := t.take(m.ID)
+	if len(keys) == 0 {
+		return nil
+	}
+	return throttleReleaseScript.Run(ctx, t.rdb, keys).Err()
+}
+
+// take mesajın ayrılmış yerinin anahtarlarını döner ve kaydı siler
+func (t *RecipientThrottle) take(id uint) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys := t.reserved[id]
+	delete(t.reserved, id)
+	return keys
+}
+
+// Counters numaranın her penceredeki güncel sayacını döndürür
+func (t *RecipientThrottle) Counters(ctx context.Context, to string) ([]application.RecipientCounter, error) {
+	out := make([]application.RecipientCounter, 0, len(t.windows))
+	for _, w := range t.windows {
+		key, resetsAt := t.key(to, w)
+		n, err := t.rdb.Get(ctx, key).Int64()
+		if err != nil && err != redis.Nil {
+			return nil, err
+		}
+		c := application.RecipientCounter{
+			Window:   w.String(),
+			Count:    n,
+			Limits:   map[entity.Priority]int{},
+			ResetsAt: resetsAt,
+		}
+		for p, l := range t.limits {
+			if l.Window == w {
+				c.Limits[p] = l.Max
+			}
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// key numaranın içinde bulunulan pencere için Redis anahtarını ve pencerenin bitişini döndürür
+func (t *RecipientThrottle) key(to string, window time.Duration) (string, time.Time) {
+	secs := int64(window / time.Second)
+	bucket := t.now().Unix() / secs
+	resetsAt := time.Unix((bucket+1)*secs, 0).UTC()
+	return fmt.Sprintf("throttle:recipient:%s:%d:%d", to, secs, bucket), resetsAt
+}
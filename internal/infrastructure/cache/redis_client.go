@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
 
 	"github.com/go-redis/redis/v8"
@@ -36,3 +37,17 @@ func NewRedis(cfg *config.Config) *redis.Client {
 	log.Println("Connected to Redis")
 	return rdb
 }
+
+// HealthCheck Redis'e ping atan bir sağlık kontrolü döner. Açılışta bağlantı
+// kurulamadıysa (rdb nil) Redis kullanılmadığı için her zaman down döner.
+func HealthCheck(rdb *redis.Client) application.HealthCheck {
+	return func(ctx context.Context) application.ComponentHealth {
+		if rdb == nil {
+			return application.ComponentHealth{Status: application.HealthDown, Message: "not connected at startup, Redis features are disabled"}
+		}
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			return application.ComponentHealth{Status: application.HealthDown, Message: err.Error()}
+		}
+		return application.ComponentHealth{Status: application.HealthUp}
+	}
+}
@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var _ application.MessageQueue = (*RedisStreamQueue)(nil)
+
+// queuedMarkerTTL bir mesajın stream'e tekrar eklenmesini engelleyen işaretin
+// ömrü. Ack edilmeden kalan işaret bu süre sonunda kendiliğinden düşer.
+const queuedMarkerTTL = time.Hour
+
+// RedisStreamQueue gönderilecek mesajları Redis Streams consumer group'u ile
+// instance'lar arasında paylaştırır. Stream sadece mesaj ID'lerini taşır; mesajın
+// kendisi ve durumu her Claim'de veritabanından okunur. Stream boşaldıkça zamanı
+// gelmiş mesajlar veritabanından stream'e eklenir. Ack edilmeden kalan mesajlar
+// (ör. instance çöktüyse) claimIdle sonunda XAUTOCLAIM ile başka bir consumer'a geçer.
+type RedisStreamQueue struct {
+	rdb       *redis.Client
+	repo      repository.MessageRepository
+	stream    string
+	group     string
+	consumer  string
+	claimIdle time.Duration
+
+	mu sync.Mutex
+	// entries bu consumer'ın aldığı mesajların stream kayıt ID'leri
+	entries map[uint]string
+}
+
+// NewRedisStreamQueue consumer group'u yoksa oluşturur ve consumer adıyla mesaj
+// alan bir kuyruk döner
+func NewRedisStreamQueue(ctx context.Context, rdb *redis.Client, repo repository.MessageRepository, stream, group, consumer string, claimIdle time.Duration) (*RedisStreamQueue, error) {
+	err := rdb.XGroupCreateMkStream(ctx, stream, group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("create consumer group: %w", err)
+	}
+	return &RedisStreamQueue{
+		rdb: rdb, repo: repo, stream: stream, group: group, consumer: consumer,
+		claimIdle: claimIdle, entries: map[uint]string{},
+	}, nil
+}
+
+// Claim önce süresi dolmuş bekleyen kayıtları devralır, eksik kalırsa stream'i
+// veritabanından doldurup yeni kayıtları okur. Veritabanında artık gönderilmeye
+// hazır olmayan mesajların kayıtları ack edilip atlanır.
+func (q *RedisStreamQueue) Claim(ctx context.Context, limit int) ([]*entity.Message, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	entries, err := q.reclaim(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("reclaim: %w", err)
+	}
+	if len(entries) > 0 {
+		log.Printf("queue reclaimed %d idle messages", len(entries))
+	}
+	if len(entries) < limit {
+		if err := q.refill(ctx, limit); err != nil {
+			return nil, err
+		}
+		streams, err := q.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group: q.group, Consumer: q.consumer, Streams: []string{q.stream, ">"},
+			Count: int64(limit - len(entries)), Block: -1,
+		}).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("read group: %w", err)
+		}
+		for _, s := range streams {
+			entries = append(entries, s.Messages...)
+		}
+	}
+	return q.load(ctx, entries)
+}
+
+// reclaim claimIdle'dan uzun süredir ack edilmemiş kayıtları bu consumer'a
+// devralır. go-redis'in XAutoClaim'i Redis 7'nin üç elemanlı cevabını
+// okuyamadığı için komut ham olarak gönderilir.
+func (q *RedisStreamQueue) reclaim(ctx context.Context, limit int) ([]redis.XMessage, error) {
+	reply, err := q.rdb.Do(ctx, "XAUTOCLAIM", q.stream, q.group, q.consumer,
+		q.claimIdle.Milliseconds(), "0-0", "COUNT", limit).Slice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(reply) < 2 {
+		return nil, fmt.Errorf("unexpected XAUTOCLAIM reply of %d elements", len(reply))
+	}
+	raw, _ := reply[1].([]interface{})
+	entries := make([]redis.XMessage, 0, len(raw))
+	for _, r := range raw {
+		// Redis 6.2 silinmiş kayıtlar için nil döner
+		fields, ok := r.([]interface{})
+		if !ok || len(fields) != 2 {
+			continue
+		}
+		id, _ := fields[0].(string)
+		kv, _ := fields[1].([]interface{})
+		values := make(map[string]interface{}, len(kv)/2)
+		for i := 0; i+1 < len(kv); i += 2 {
+			if k, ok := kv[i].(string); ok {
+				values[k] = kv[i+1]
+			}
+		}
+		entries = append(entries, redis.XMessage{ID: id, Values: values})
+	}
+	return entries, nil
+}
+
+// refill stream'de olmayan zamanı gelmiş mesajları ekler. Stream'de bekleyen
+// kayıtlar kadar fazla mesaj sorgulanır ki başka consumer'ların işlediği
+// mesajlar yeni mesajların önünü kesmesin; işaret anahtarı aynı mesajın iki
+// kez eklenmesini engeller.
+func (q *RedisStreamQueue) refill(ctx context.Context, limit int) error {
+	queued, err := q.rdb.XLen(ctx, q.stream).Result()
+	if err != nil {
+		return fmt.Errorf("stream length: %w", err)
+	}
+	msgs, err := q.repo.GetUnsent(limit + int(queued))
+	if err != nil {
+		return err
+	}
+	for _, m := range msgs {
+		ok, err := q.rdb.SetNX(ctx, q.markerKey(m.ID), 1, queuedMarkerTTL).Result()
+		if err != nil {
+			return fmt.Errorf("queue marker: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		err = q.rdb.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: map[string]interface{}{"id": m.ID}}).Err()
+		if err != nil {
+			q.rdb.Del(ctx, q.markerKey(m.ID))
+			return fmt.Errorf("enqueue id=%d: %w", m.ID, err)
+		}
+	}
+	return nil
+}
+
+// load kayıtların mesajlarını veritabanından okur. Bozuk, tekrarlanan veya
+// artık gönderilmeye hazır olmayan mesajların kayıtları kuyruktan düşürülür.
+func (q *RedisStreamQueue) load(ctx context.Context, entries []redis.XMessage) ([]*entity.Message, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	claimed := make(map[uint]string, len(entries))
+	ids := make([]uint, 0, len(entries))
+	for _, e := range entries {
+		raw, _ := e.Values["id"].(string)
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			log.Printf("queue entry %s has invalid message id %q, dropped", e.ID, raw)
+			q.drop(ctx, 0, e.ID)
+			continue
+		}
+		if _, dup := claimed[uint(id)]; dup {
+			q.drop(ctx, 0, e.ID)
+			continue
+		}
+		claimed[uint(id)] = e.ID
+		ids = append(ids, uint(id))
+	}
+	msgs, err := q.repo.GetDue(ids)
+	if err != nil {
+		// kayıtlar bekleyen listede kalır, claimIdle sonunda tekrar alınır
+		return nil, err
+	}
+	q.mu.Lock()
+	for _, m := range msgs {
+		q.entries[m.ID] = claimed[m.ID]
+		delete(claimed, m.ID)
+	}
+	q.mu.Unlock()
+	for id, entryID := range claimed {
+		q.drop(ctx, id, entryID)
+	}
+	return msgs, nil
+}
+
+// Ack mesajın kaydını stream'den siler. Mesaj gönderilmediyse bir sonraki
+// doldurmada veritabanındaki durumuna göre tekrar eklenir.
+func (q *RedisStreamQueue) Ack(ctx context.Context, id uint) error {
+	q.mu.Lock()
+	entryID, ok := q.entries[id]
+	delete(q.entries, id)
+	q.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	return q.remove(ctx, id, entryID)
+}
+
+// drop kaydı kuyruktan düşürür, hata sadece loglanır
+func (q *RedisStreamQueue) drop(ctx context.Context, id uint, entryID string) {
+	if err := q.remove(ctx, id, entryID); err != nil {
+		log.Printf("queue drop failed entry=%s err=%v", entryID, err)
+	}
+}
+
+// remove kaydı ack edip stream'den ve mesajın işaretini siler
+func (q *RedisStreamQueue) remove(ctx context.Context, id uint, entryID string) error {
+	_, err := q.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.XAck(ctx, q.stream, q.group, entryID)
+		p.XDel(ctx, q.stream, entryID)
+		if id != 0 {
+			p.Del(ctx, q.markerKey(id))
+		}
+		return nil
+	})
+	return err
+}
+
+// markerKey mesajın stream'de olduğunu gösteren anahtar
+func (q *RedisStreamQueue) markerKey(id uint) string {
+	return fmt.Sprintf("%s:queued:%d", q.stream, id)
+}
@@ -3,12 +3,145 @@ package db
 import "time"
 
 type MessageModel struct {
-	ID           uint   `gorm:"primaryKey;autoIncrement"`
-	To           string `gorm:"size:32"`
-	Content      string `gorm:"type:text"`
-	Sent         bool   `gorm:"default:false;index"`
-	SentAt       *time.Time
-	WebhookMsgID string `gorm:"size:128"`
+	ID                 uint              `gorm:"primaryKey;autoIncrement"`
+	To                 string            `gorm:"size:32"`
+	Country            string            `gorm:"size:2"`
+	Content            string            `gorm:"type:text"`
+	Sent               bool              `gorm:"default:false;index"`
+	Status             string            `gorm:"size:16;default:pending;index"`
+	Priority           string            `gorm:"size:8;default:normal"`
+	Category           string            `gorm:"size:16;default:general"`
+	TemplateID         *uint             `gorm:"index"`
+	CampaignID         *uint             `gorm:"index"`
+	Metadata           *string           `gorm:"type:text"`
+	Tags               []MessageTagModel `gorm:"foreignKey:MessageID"`
+	Links              []ShortLinkModel  `gorm:"foreignKey:MessageID"`
+	Attempts           int               `gorm:"default:0"`
+	LastError          string            `gorm:"size:512"`
+	NextAttemptAt      *time.Time
+	SentAt             *time.Time
+	WebhookMsgID       string `gorm:"size:128"`
+	WebhookMsgIDSource string `gorm:"size:16"`
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+}
+
+type MessageTagModel struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	MessageID uint   `gorm:"index"`
+	Tag       string `gorm:"size:64;index"`
+}
+
+type ShortLinkModel struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	MessageID uint   `gorm:"index"`
+	Code      string `gorm:"size:16;uniqueIndex"`
+	TargetURL string `gorm:"size:2048"`
+}
+
+type LinkClickModel struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	LinkID    uint   `gorm:"index"`
+	MessageID uint   `gorm:"index"`
+	UserAgent string `gorm:"size:256"`
+	CreatedAt time.Time
+}
+
+type MessageAttemptModel struct {
+	ID           uint `gorm:"primaryKey;autoIncrement"`
+	MessageID    uint `gorm:"index"`
+	Number       int
+	Outcome      string `gorm:"size:16"`
+	StatusCode   int
+	Error        string `gorm:"size:512"`
+	ResponseBody string `gorm:"size:512"`
+	LatencyMs    int64
 	CreatedAt    time.Time
-	UpdatedAt    time.Time
+}
+
+type CampaignModel struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Name        string `gorm:"size:128"`
+	CreatedBy   string `gorm:"size:128"`
+	ScheduledAt *time.Time
+	TemplateID  *uint
+	Status      string `gorm:"size:16;default:active;index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// ContentPolicyModel içerik kurallarını tek satırda JSON olarak tutar
+type ContentPolicyModel struct {
+	ID        uint   `gorm:"primaryKey"`
+	Rules     string `gorm:"type:text"`
+	Version   int64
+	UpdatedAt time.Time
+}
+
+// DispatchSettingsModel çalışma zamanı gönderim ayarlarını tek satırda tutar
+type DispatchSettingsModel struct {
+	ID              uint `gorm:"primaryKey"`
+	IntervalSeconds int
+	BatchSize       int
+	Concurrency     int
+	Version         int64
+	UpdatedAt       time.Time
+}
+
+// SchedulerStateModel istenen scheduler durumunu ve lideri tek satırda tutar
+type SchedulerStateModel struct {
+	ID          uint `gorm:"primaryKey"`
+	Running     bool
+	StartedBy   string `gorm:"size:160"`
+	StartedAt   time.Time
+	StoppedBy   string `gorm:"size:160"`
+	StoppedAt   time.Time
+	Leader      string `gorm:"size:160"`
+	LeaderSince time.Time
+	UpdatedAt   time.Time
+}
+
+// SchedulerStateChangeModel scheduler başlatma/durdurma denetim kaydı
+type SchedulerStateChangeModel struct {
+	ID        uint `gorm:"primaryKey;autoIncrement"`
+	Running   bool
+	Actor     string    `gorm:"size:64"`
+	APIKey    string    `gorm:"size:32"`
+	Instance  string    `gorm:"size:160"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// SchedulerRunModel son batch çalışmalarını tutan halka tablo
+type SchedulerRunModel struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Trigger     string `gorm:"size:64"`
+	RequestedBy string `gorm:"size:160"`
+	Instance    string `gorm:"size:128"`
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	DurationMs  int64
+	BatchSize   int
+	Sizing      string `gorm:"size:16"`
+	Backlog     int64
+	Fetched     int
+	Sent        int
+	Failed      int
+	Retried     int
+	Skipped     int
+	Error       string `gorm:"size:512"`
+}
+
+// OutboxModel mesaj değişiklikleriyle aynı transaction'da yazılan, relay'in
+// yayınlamasını bekleyen olaylar
+type OutboxModel struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Type        string `gorm:"size:32"`
+	MessageID   uint   `gorm:"index"`
+	Payload     string `gorm:"type:text"`
+	Attempts    int
+	LastError   string `gorm:"size:512"`
+	CreatedAt   time.Time
+	PublishedAt *time.Time `gorm:"index"`
+	// DeadAt olay OUTBOX_MAX_ATTEMPTS denemede yayınlanamadıysa dolar, relay atlar
+	DeadAt *time.Time `gorm:"index"`
 }
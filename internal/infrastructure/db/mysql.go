@@ -1,10 +1,12 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
+	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
 
 	"gorm.io/driver/mysql"
@@ -55,3 +57,17 @@ func NewMySQL(cfg *config.Config) (*gorm.DB, error) {
 	log.Println("Connected to MySQL")
 	return db, nil
 }
+
+// HealthCheck veritabanına ping atan bir sağlık kontrolü döner
+func HealthCheck(db *gorm.DB) application.HealthCheck {
+	return func(ctx context.Context) application.ComponentHealth {
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.PingContext(ctx)
+		}
+		if err != nil {
+			return application.ComponentHealth{Status: application.HealthDown, Message: err.Error()}
+		}
+		return application.ComponentHealth{Status: application.HealthUp}
+	}
+}
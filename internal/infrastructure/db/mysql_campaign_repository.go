@@ -0,0 +1,184 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MySQLCampaignRepository struct {
+	db   *gorm.DB
+	opts repositoryOptions
+}
+
+// NewMySQLCampaignRepository yeni bir kampanya repository'si oluşturur ve tabloları hazırlar
+func NewMySQLCampaignRepository(db *gorm.DB, opts ...RepositoryOption) repository.CampaignRepository {
+	db.AutoMigrate(&CampaignModel{}, &MessageModel{})
+	return &MySQLCampaignRepository{db: db, opts: applyRepositoryOptions(db, opts)}
+}
+
+// Create kampanyayı ve mesajlarını birlikte kaydeder, biri başarısız olursa hiçbiri yazılmaz.
+// Outbox açıksa her mesajın created olayı da aynı transaction'da yazılır.
+func (r *MySQLCampaignRepository) Create(c *entity.Campaign, msgs []*entity.Message) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		row := CampaignModel{
+			Name: c.Name, CreatedBy: c.CreatedBy, ScheduledAt: c.ScheduledAt,
+			TemplateID: c.TemplateID, Status: string(c.Status),
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		c.ID = row.ID
+		c.CreatedAt = row.CreatedAt
+		c.UpdatedAt = row.UpdatedAt
+		if len(msgs) == 0 {
+			return nil
+		}
+
+		rows := make([]MessageModel, len(msgs))
+		for i, m := range msgs {
+			m.CampaignID = &c.ID
+			rows[i] = toMessageModel(m)
+		}
+		if err := tx.CreateInBatches(rows, 500).Error; err != nil {
+			return err
+		}
+		for i, m := range msgs {
+			applyCreated(m, rows[i])
+		}
+		if !r.opts.outbox {
+			return nil
+		}
+		events := make([]OutboxModel, len(msgs))
+		for i, m := range msgs {
+			events[i] = createdEvent(m)
+		}
+		return writeOutbox(tx, events)
+	})
+}
+
+// Get ID ile kampanyayı getirir
+func (r *MySQLCampaignRepository) Get(id uint) (*entity.Campaign, error) {
+	var row CampaignModel
+	if err := r.db.First(&row, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return toCampaignEntity(row), nil
+}
+
+// List kampanyaları en yeniden eskiye getirir
+func (r *MySQLCampaignRepository) List() ([]*entity.Campaign, error) {
+	var rows []CampaignModel
+	if err := r.db.Order("created_at desc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.Campaign, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toCampaignEntity(row))
+	}
+	return out, nil
+}
+
+// SetStatus kampanya durumu hâlâ from ise to yapar; aynı anda gelen iki
+// geçişten sadece biri uygulanır. İptalde bekleyen mesajlar aynı transaction
+// içinde cancelled yapılır, gönderilmiş mesajlara dokunulmaz.
+func (r *MySQLCampaignRepository) SetStatus(id uint, from, to entity.CampaignStatus) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&CampaignModel{}).Where("id = ? AND status = ?", id, string(from)).Update("status", string(to))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			var n int64
+			if err := tx.Model(&CampaignModel{}).Where("id = ?", id).Count(&n).Error; err != nil {
+				return err
+			}
+			if n == 0 {
+				return repository.ErrNotFound
+			}
+			return repository.ErrStatusChanged
+		}
+		if to != entity.CampaignCancelled {
+			return nil
+		}
+		now := time.Now().UTC()
+		pending := tx.Model(&MessageModel{}).Where("campaign_id = ? AND status = ?", id, entity.StatusPending)
+		var ids []uint
+		if r.opts.outbox {
+			// iptal edilen mesajların olayları için satırlar kilitlenip ID'leri alınır
+			if err := pending.Session(&gorm.Session{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			pending = tx.Model(&MessageModel{}).Where("id IN ?", ids)
+		}
+		if err := pending.Updates(map[string]interface{}{
+			"status": entity.StatusCancelled, "next_attempt_at": nil, "updated_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		var rows []MessageModel
+		if err := tx.Preload("Tags").Where("id IN ?", ids).Order("id").Find(&rows).Error; err != nil {
+			return err
+		}
+		events := make([]OutboxModel, len(rows))
+		for i, row := range rows {
+			events[i] = outboxRow(entity.EventMessageCancelled, withMessageFields(entity.MessageEvent{
+				Status: entity.StatusCancelled, OccurredAt: now,
+			}, row))
+		}
+		return writeOutbox(tx, events)
+	})
+}
+
+// Stats kampanya mesajlarını durumlarına göre sayar
+func (r *MySQLCampaignRepository) Stats(id uint) (entity.CampaignStats, error) {
+	var counts []struct {
+		Status string
+		Count  int64
+	}
+	if err := r.db.Model(&MessageModel{}).Select("status, count(*) as count").
+		Where("campaign_id = ?", id).Group("status").Scan(&counts).Error; err != nil {
+		return entity.CampaignStats{}, err
+	}
+	var s entity.CampaignStats
+	for _, c := range counts {
+		s.Total += c.Count
+		switch entity.MessageStatus(c.Status) {
+		case entity.StatusPending:
+			s.Pending = c.Count
+		case entity.StatusSent:
+			s.Sent = c.Count
+		case entity.StatusFailed:
+			s.Failed = c.Count
+		case entity.StatusCancelled:
+			s.Cancelled = c.Count
+		}
+	}
+	return s, nil
+}
+
+// toCampaignEntity veritabanı satırını domain kampanyasına çevirir
+func toCampaignEntity(row CampaignModel) *entity.Campaign {
+	return &entity.Campaign{
+		ID:          row.ID,
+		Name:        row.Name,
+		CreatedBy:   row.CreatedBy,
+		ScheduledAt: row.ScheduledAt,
+		TemplateID:  row.TemplateID,
+		Status:      entity.CampaignStatus(row.Status),
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// contentPolicyRowID kurallar tek bir satırda tutulur
+const contentPolicyRowID = 1
+
+type MySQLContentPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLContentPolicyRepository yeni bir içerik kuralı repository'si oluşturur ve tabloyu hazırlar
+func NewMySQLContentPolicyRepository(db *gorm.DB) repository.ContentPolicyRepository {
+	db.AutoMigrate(&ContentPolicyModel{})
+	return &MySQLContentPolicyRepository{db: db}
+}
+
+// Load kayıtlı kuralları getirir
+func (r *MySQLContentPolicyRepository) Load() (*entity.ContentRules, error) {
+	var row ContentPolicyModel
+	if err := r.db.First(&row, contentPolicyRowID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var rules entity.ContentRules
+	if err := json.Unmarshal([]byte(row.Rules), &rules); err != nil {
+		return nil, err
+	}
+	rules.Version = row.Version
+	rules.UpdatedAt = row.UpdatedAt
+	return &rules, nil
+}
+
+// Save kuralları yazar, versiyon satır kilidi altında artırılır
+func (r *MySQLContentPolicyRepository) Save(rules *entity.ContentRules) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var row ContentPolicyModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&row, contentPolicyRowID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		b, err := json.Marshal(rules)
+		if err != nil {
+			return err
+		}
+		row.ID = contentPolicyRowID
+		row.Rules = string(b)
+		row.Version++
+		if err := tx.Save(&row).Error; err != nil {
+			return err
+		}
+		rules.Version = row.Version
+		rules.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
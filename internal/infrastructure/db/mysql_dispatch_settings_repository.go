@@ -0,0 +1,64 @@
+package db
+
+import (
+	"errors"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// dispatchSettingsRowID ayarlar tek bir satırda tutulur
+const dispatchSettingsRowID = 1
+
+type MySQLDispatchSettingsRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLDispatchSettingsRepository yeni bir gönderim ayarları repository'si oluşturur ve tabloyu hazırlar
+func NewMySQLDispatchSettingsRepository(db *gorm.DB) repository.DispatchSettingsRepository {
+	db.AutoMigrate(&DispatchSettingsModel{})
+	return &MySQLDispatchSettingsRepository{db: db}
+}
+
+// Load kayıtlı ayarları getirir
+func (r *MySQLDispatchSettingsRepository) Load() (*entity.DispatchSettings, error) {
+	var row DispatchSettingsModel
+	if err := r.db.First(&row, dispatchSettingsRowID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity.DispatchSettings{
+		IntervalSeconds: row.IntervalSeconds,
+		BatchSize:       row.BatchSize,
+		Concurrency:     row.Concurrency,
+		Version:         row.Version,
+		UpdatedAt:       row.UpdatedAt,
+	}, nil
+}
+
+// Save ayarları yazar, versiyon satır kilidi altında artırılır
+func (r *MySQLDispatchSettingsRepository) Save(s *entity.DispatchSettings) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var row DispatchSettingsModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&row, dispatchSettingsRowID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		row.ID = dispatchSettingsRowID
+		row.IntervalSeconds = s.IntervalSeconds
+		row.BatchSize = s.BatchSize
+		row.Concurrency = s.Concurrency
+		row.Version++
+		if err := tx.Save(&row).Error; err != nil {
+			return err
+		}
+		s.Version = row.Version
+		s.UpdatedAt = row.UpdatedAt
+		return nil
+	})
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/application"
+
+	"gorm.io/gorm"
+)
+
+var _ application.LeaderLock = (*MySQLLeaderLock)(nil)
+
+// MySQLLeaderLock Redis yokken liderliği MySQL GET_LOCK ile tutar. Kilit ona sahip
+// bağlantıya bağlıdır; bu yüzden havuzdan ayrılmış bir bağlantı liderlik boyunca
+// açık tutulur. Süreç ölür veya bağlantı koparsa MySQL kilidi kendiliğinden bırakır,
+// ttl kullanılmaz. Kilidi tutuyor olabilecek bir bağlantı havuza iade edilmez,
+// kapatılır; aksi halde kilit havuzdaki oturumda kalır ve kimse lider olamaz.
+type MySQLLeaderLock struct {
+	db   *gorm.DB
+	name string
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewMySQLLeaderLock name isimli kilit için yeni bir liderlik kilidi oluşturur.
+// MySQL kilit isimleri en fazla 64 karakter olabilir.
+func NewMySQLLeaderLock(db *gorm.DB, name string) *MySQLLeaderLock {
+	return &MySQLLeaderLock{db: db, name: name}
+}
+
+// Acquire kilidi tutan bağlantı hâlâ sahipse true döner, değilse kilidi yeni bir
+// bağlantıyla beklemeden almayı dener
+func (l *MySQLLeaderLock) Acquire(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		var held sql.NullInt64
+		err := l.conn.QueryRowContext(ctx, "SELECT IS_USED_LOCK(?) = CONNECTION_ID()", l.name).Scan(&held)
+		if err == nil && held.Valid && held.Int64 == 1 {
+			return true, nil
+		}
+		discardConn(l.conn)
+		l.conn = nil
+		if err != nil {
+			return false, err
+		}
+	}
+
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var got sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, 0)", l.name).Scan(&got); err != nil {
+		// GET_LOCK sunucuda başarılı olup ctx Scan'den önce iptal edilmiş olabilir
+		discardConn(conn)
+		return false, err
+	}
+	if !got.Valid || got.Int64 != 1 {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+// Release kilidi bırakır. Bağlantı sadece RELEASE_LOCK kilidin bırakıldığını
+// doğrularsa havuza geri verilir, değilse kapatılır.
+func (l *MySQLLeaderLock) Release(ctx context.Context, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.conn == nil {
+		return nil
+	}
+	conn := l.conn
+	l.conn = nil
+	var released sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT RELEASE_LOCK(?)", l.name).Scan(&released); err != nil {
+		discardConn(conn)
+		return err
+	}
+	if !released.Valid || released.Int64 != 1 {
+		discardConn(conn)
+		return nil
+	}
+	return conn.Close()
+}
+
+// discardConn bağlantıyı havuza iade etmeden kapatır. sql.Conn.Close oturumu
+// havuza geri verir; ErrBadConn dönen Raw ise fiziksel bağlantıyı kapattırır ve MySQL
+// oturumun kilitlerini bırakır.
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(any) error { return driver.ErrBadConn })
+	conn.Close()
+}
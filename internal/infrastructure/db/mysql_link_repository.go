@@ -0,0 +1,115 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type MySQLLinkRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLLinkRepository yeni bir link repository'si oluşturur ve tabloları hazırlar
+func NewMySQLLinkRepository(db *gorm.DB) repository.LinkRepository {
+	db.AutoMigrate(&ShortLinkModel{}, &LinkClickModel{})
+	return &MySQLLinkRepository{db: db}
+}
+
+// FindByCode kısa link kodundan linki bulur
+func (r *MySQLLinkRepository) FindByCode(code string) (*entity.Link, error) {
+	var row ShortLinkModel
+	if err := r.db.Where("code = ?", code).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return &entity.Link{ID: row.ID, MessageID: row.MessageID, Code: row.Code, TargetURL: row.TargetURL}, nil
+}
+
+// RecordClick tıklama kaydı ekler
+func (r *MySQLLinkRepository) RecordClick(c *entity.Click) error {
+	row := LinkClickModel{LinkID: c.LinkID, MessageID: c.MessageID, UserAgent: truncate(c.UserAgent, 256)}
+	if err := r.db.Create(&row).Error; err != nil {
+		return err
+	}
+	c.ID = row.ID
+	c.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// MessageClicks mesajın linklerini tıklama sayılarıyla getirir
+func (r *MySQLLinkRepository) MessageClicks(messageID uint) ([]entity.LinkStats, error) {
+	var links []ShortLinkModel
+	if err := r.db.Where("message_id = ?", messageID).Order("id asc").Find(&links).Error; err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		LinkID uint
+		Count  int64
+		First  string
+		Last   string
+	}
+	if err := r.db.Model(&LinkClickModel{}).
+		Select("link_id, count(*) as count, min(created_at) as first, max(created_at) as last").
+		Where("message_id = ?", messageID).Group("link_id").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	byLink := make(map[uint]int, len(counts))
+	for i, c := range counts {
+		byLink[c.LinkID] = i
+	}
+	stats := make([]entity.LinkStats, 0, len(links))
+	for _, l := range links {
+		s := entity.LinkStats{Code: l.Code, TargetURL: l.TargetURL}
+		if i, ok := byLink[l.ID]; ok {
+			s.Clicks = counts[i].Count
+			s.FirstClickAt = parseDBTime(counts[i].First)
+			s.LastClickAt = parseDBTime(counts[i].Last)
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+// CampaignClicks kampanya mesajlarının tıklama özetini hesaplar
+func (r *MySQLLinkRepository) CampaignClicks(campaignID uint) (entity.CampaignClickStats, error) {
+	s := entity.CampaignClickStats{CampaignID: campaignID}
+	campaignMessages := r.db.Model(&MessageModel{}).Select("id").Where("campaign_id = ?", campaignID)
+	var agg struct {
+		Clicks   int64
+		Messages int64
+	}
+	if err := r.db.Model(&LinkClickModel{}).
+		Select("count(*) as clicks, count(distinct message_id) as messages").
+		Where("message_id IN (?)", campaignMessages).Scan(&agg).Error; err != nil {
+		return s, err
+	}
+	if err := r.db.Model(&MessageModel{}).
+		Where("campaign_id = ? AND status = ?", campaignID, entity.StatusSent).
+		Count(&s.SentMessages).Error; err != nil {
+		return s, err
+	}
+	s.Clicks = agg.Clicks
+	s.ClickedMessages = agg.Messages
+	if s.SentMessages > 0 {
+		s.ClickThroughRate = float64(s.ClickedMessages) / float64(s.SentMessages)
+	}
+	return s, nil
+}
+
+// parseDBTime min/max ile dönen zamanı çözer; MySQL ve SQLite farklı formatlar döndürebilir
+func parseDBTime(s string) *time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
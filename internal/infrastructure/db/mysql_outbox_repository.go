@@ -0,0 +1,153 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+// RepositoryOption mesaj yazan repository'lere opsiyonel davranış ekler
+type RepositoryOption func(*repositoryOptions)
+
+type repositoryOptions struct {
+	outbox bool
+}
+
+// WithOutbox mesaj oluşturma ve durum değişikliklerinin olaylarını aynı
+// transaction'da outbox tablosuna yazar
+func WithOutbox() RepositoryOption {
+	return func(o *repositoryOptions) { o.outbox = true }
+}
+
+func applyRepositoryOptions(db *gorm.DB, opts []RepositoryOption) repositoryOptions {
+	var o repositoryOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.outbox {
+		db.AutoMigrate(&OutboxModel{})
+	}
+	return o
+}
+
+// outboxRow mesaj olayını outbox satırına çevirir
+func outboxRow(t entity.OutboxEventType, e entity.MessageEvent) OutboxModel {
+	if e.OccurredAt.IsZero() {
+		e.OccurredAt = time.Now().UTC()
+	}
+	// MessageEvent her zaman serialize edilebilir
+	b, _ := json.Marshal(e)
+	return OutboxModel{Type: string(t), MessageID: e.MessageID, Payload: string(b)}
+}
+
+// writeOutbox olayları verilen transaction içinde yazar
+func writeOutbox(tx *gorm.DB, rows []OutboxModel) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(rows, 500).Error
+}
+
+// createdEvent yeni kaydedilmiş mesajın created olayı
+func createdEvent(m *entity.Message) OutboxModel {
+	return outboxRow(entity.EventMessageCreated, entity.MessageEvent{
+		MessageID: m.ID, Status: m.Status, To: m.To, Priority: m.Priority, Category: m.Category,
+		TemplateID: m.TemplateID, CampaignID: m.CampaignID, Tags: m.Tags, Metadata: m.Metadata,
+		OccurredAt: m.CreatedAt.UTC(),
+	})
+}
+
+// withMessageFields durum olayına mesajın alıcı, kampanya, etiket ve metadata
+// alanlarını ekler; tüketiciler olayı created olayına bakmadan eşleştirebilir
+func withMessageFields(e entity.MessageEvent, row MessageModel) entity.MessageEvent {
+	e.MessageID, e.To = row.ID, row.To
+	e.Priority, e.Category = entity.Priority(row.Priority), entity.Category(row.Category)
+	e.TemplateID, e.CampaignID = row.TemplateID, row.CampaignID
+	e.Tags, e.Metadata = tagNames(row.Tags), decodeMetadata(row.Metadata)
+	return e
+}
+
+type MySQLOutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLOutboxRepository yeni bir outbox repository'si oluşturur ve tabloyu hazırlar
+func NewMySQLOutboxRepository(db *gorm.DB) repository.OutboxRepository {
+	db.AutoMigrate(&OutboxModel{})
+	return &MySQLOutboxRepository{db: db}
+}
+
+// Pending yayınlanmamış ve dead-letter'a ayrılmamış olayları ID sırasıyla getirir
+func (r *MySQLOutboxRepository) Pending(limit int) ([]*entity.OutboxEvent, error) {
+	var rows []OutboxModel
+	if err := r.db.Where("published_at IS NULL AND dead_at IS NULL").Order("id").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	events := make([]*entity.OutboxEvent, 0, len(rows))
+	for _, row := range rows {
+		events = append(events, &entity.OutboxEvent{
+			ID:        row.ID,
+			Type:      entity.OutboxEventType(row.Type),
+			MessageID: row.MessageID,
+			Payload:   json.RawMessage(row.Payload),
+			CreatedAt: row.CreatedAt,
+			Attempts:  row.Attempts,
+			LastError: row.LastError,
+		})
+	}
+	return events, nil
+}
+
+// MarkPublished olayların yayınlanma zamanını yazar
+func (r *MySQLOutboxRepository) MarkPublished(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Model(&OutboxModel{}).Where("id IN ?", ids).
+		Update("published_at", time.Now().UTC()).Error
+}
+
+// MarkFailed deneme sayısını artırır ve son hatayı yazar
+func (r *MySQLOutboxRepository) MarkFailed(id uint, reason string) error {
+	return r.db.Model(&OutboxModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts": gorm.Expr("attempts + 1"), "last_error": truncate(reason, 512),
+	}).Error
+}
+
+// MarkDead deneme sayısını artırır, son hatayı yazar ve olayı dead-letter'a ayırır
+func (r *MySQLOutboxRepository) MarkDead(id uint, reason string) error {
+	return r.db.Model(&OutboxModel{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"attempts": gorm.Expr("attempts + 1"), "last_error": truncate(reason, 512), "dead_at": time.Now().UTC(),
+	}).Error
+}
+
+// DeadCount dead-letter'daki olayları sayar
+func (r *MySQLOutboxRepository) DeadCount() (int64, error) {
+	var n int64
+	err := r.db.Model(&OutboxModel{}).Where("dead_at IS NOT NULL").Count(&n).Error
+	return n, err
+}
+
+// PurgePublished eski yayınlanmış olayları parça parça siler
+func (r *MySQLOutboxRepository) PurgePublished(before time.Time) (int64, error) {
+	var total int64
+	for {
+		var ids []uint
+		err := r.db.Model(&OutboxModel{}).Where("published_at < ?", before.UTC()).
+			Limit(purgeChunk).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+		if err := r.db.Where("id IN ?", ids).Delete(&OutboxModel{}).Error; err != nil {
+			return total, err
+		}
+		total += int64(len(ids))
+		if len(ids) < purgeChunk {
+			return total, nil
+		}
+	}
+}
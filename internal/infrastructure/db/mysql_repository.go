@@ -1,6 +1,10 @@
 package db
 
 import (
+	"encoding/json"
+	"fmt"
+	"time"
+
 	"insider-messaging/internal/domain/entity"
 	"insider-messaging/internal/domain/repository"
 
@@ -8,58 +12,349 @@ import (
 )
 
 type MySQLMessageRepository struct {
-	db *gorm.DB
+	db   *gorm.DB
+	opts repositoryOptions
 }
 
 // NewMySQLMessageRepository yeni bir MySQL repository oluşturur ve tabloyu hazırlar
-func NewMySQLMessageRepository(db *gorm.DB) repository.MessageRepository {
-	db.AutoMigrate(&MessageModel{})
-	return &MySQLMessageRepository{db: db}
+func NewMySQLMessageRepository(db *gorm.DB, opts ...RepositoryOption) repository.MessageRepository {
+	// GetUnsent kampanya durumuna baktığı için kampanya tablosu da burada hazırlanır
+	db.AutoMigrate(&MessageModel{}, &MessageAttemptModel{}, &MessageTagModel{}, &ShortLinkModel{}, &LinkClickModel{}, &CampaignModel{})
+	// status kolonu eklenmeden önce gönderilmiş kayıtları düzelt
+	db.Model(&MessageModel{}).Where("sent = ? AND status = ?", true, entity.StatusPending).
+		Update("status", entity.StatusSent)
+	return &MySQLMessageRepository{db: db, opts: applyRepositoryOptions(db, opts)}
 }
 
-// Create yeni bir mesaj kaydı oluşturur
+// Create yeni bir mesaj kaydı oluşturur, outbox açıksa created olayı aynı transaction'da yazılır
 func (r *MySQLMessageRepository) Create(msg *entity.Message) error {
-	row := MessageModel{To: msg.To, Content: msg.Content, Sent: false}
-	return r.db.Create(&row).Error
+	row := toMessageModel(msg)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		if !r.opts.outbox {
+			return nil
+		}
+		created := *msg
+		applyCreated(&created, row)
+		return writeOutbox(tx, []OutboxModel{createdEvent(&created)})
+	})
+	if err != nil {
+		return err
+	}
+	applyCreated(msg, row)
+	return nil
+}
+
+// updateStatus mesaj satırını günceller; outbox açıksa ve satır değiştiyse olayı
+// aynı transaction'da yazar
+func (r *MySQLMessageRepository) updateStatus(id uint, values map[string]interface{}, t entity.OutboxEventType, e entity.MessageEvent) error {
+	if !r.opts.outbox {
+		return r.db.Model(&MessageModel{}).Where("id = ?", id).Updates(values).Error
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&MessageModel{}).Where("id = ?", id).Updates(values)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		var row MessageModel
+		if err := tx.Preload("Tags").First(&row, id).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, []OutboxModel{outboxRow(t, withMessageFields(e, row))})
+	})
+}
+
+// toMessageModel yeni mesajı boş alanlara varsayılanları koyarak satıra çevirir
+func toMessageModel(msg *entity.Message) MessageModel {
+	status := msg.Status
+	if status == "" {
+		status = entity.StatusPending
+	}
+	if msg.Sent {
+		status = entity.StatusSent
+	}
+	priority := msg.Priority
+	if priority == "" {
+		priority = entity.PriorityNormal
+	}
+	category := msg.Category
+	if category == "" {
+		category = entity.CategoryGeneral
+	}
+	row := MessageModel{
+		To: msg.To, Country: msg.Country, Content: msg.Content, Sent: msg.Sent, Status: string(status),
+		Priority: string(priority), Category: string(category), TemplateID: msg.TemplateID,
+		CampaignID: msg.CampaignID, LastError: truncate(msg.LastError, 512),
+	}
+	if len(msg.Metadata) > 0 {
+		// map[string]string her zaman serialize edilebilir
+		b, _ := json.Marshal(msg.Metadata)
+		md := string(b)
+		row.Metadata = &md
+	}
+	for _, t := range msg.Tags {
+		row.Tags = append(row.Tags, MessageTagModel{Tag: t})
+	}
+	for _, l := range msg.Links {
+		row.Links = append(row.Links, ShortLinkModel{Code: l.Code, TargetURL: l.TargetURL})
+	}
+	return row
+}
+
+// applyCreated veritabanının atadığı alanları entity'ye geri yazar
+func applyCreated(msg *entity.Message, row MessageModel) {
+	msg.ID = row.ID
+	msg.Status = entity.MessageStatus(row.Status)
+	msg.Priority = entity.Priority(row.Priority)
+	msg.Category = entity.Category(row.Category)
+	msg.CreatedAt = row.CreatedAt
+	msg.UpdatedAt = row.UpdatedAt
+	msg.Links = toLinks(row.Links)
 }
 
-// GetUnsent gönderilmemiş mesajları getirir, limit kadar
+// GetUnsent gönderilmemiş ve zamanı gelmiş mesajları getirir, limit kadar.
+// Duraklatılmış, iptal edilmiş veya henüz zamanı gelmemiş kampanyaların mesajları atlanır.
 func (r *MySQLMessageRepository) GetUnsent(limit int) ([]*entity.Message, error) {
 	var rows []MessageModel
-	if err := r.db.Where("sent = ?", false).Order("created_at asc").Limit(limit).Find(&rows).Error; err != nil {
+	// linkler gönderim öncesi içerik kontrolünde hedef domain'ler için gerekir
+	if err := r.dueQuery(time.Now().UTC()).Preload("Links").
+		Order("created_at asc").Limit(limit).Find(&rows).Error; err != nil {
 		return nil, err
 	}
-	msgs := make([]*entity.Message, 0, len(rows))
-	for _, rr := range rows {
-		msgs = append(msgs, &entity.Message{
-			ID: rr.ID, To: rr.To, Content: rr.Content, Sent: rr.Sent,
-			SentAt: rr.SentAt, WebhookMsgID: rr.WebhookMsgID,
-			CreatedAt: rr.CreatedAt, UpdatedAt: rr.UpdatedAt,
-		})
+	return toEntities(rows), nil
+}
+
+// GetDue ids içinden hala gönderilmeye hazır olan mesajları oluşturulma sırasıyla getirir.
+// Gönderilmiş, ertelenmiş veya kampanyası duraklatılmış mesajlar sonuçta yer almaz.
+func (r *MySQLMessageRepository) GetDue(ids []uint) ([]*entity.Message, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	var rows []MessageModel
+	if err := r.dueQuery(time.Now().UTC()).Where("id IN ?", ids).Preload("Links").
+		Order("created_at asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toEntities(rows), nil
+}
+
+// dueQuery now itibarıyla gönderilmeye hazır mesajları seçen sorgu
+func (r *MySQLMessageRepository) dueQuery(now time.Time) *gorm.DB {
+	activeCampaigns := r.db.Model(&CampaignModel{}).Select("id").
+		Where("status = ?", entity.CampaignActive).
+		Where("scheduled_at IS NULL OR scheduled_at <= ?", now)
+	return r.db.Model(&MessageModel{}).Where("sent = ? AND status = ?", false, entity.StatusPending).
+		Where("next_attempt_at IS NULL OR next_attempt_at <= ?", now).
+		Where("campaign_id IS NULL OR campaign_id IN (?)", activeCampaigns)
+}
+
+// QueueStats bekleyen ve şu an gönderilmeye hazır mesaj sayılarını döner
+func (r *MySQLMessageRepository) QueueStats() (repository.QueueStats, error) {
+	var s repository.QueueStats
+	if err := r.db.Model(&MessageModel{}).Where("sent = ? AND status = ?", false, entity.StatusPending).
+		Count(&s.Pending).Error; err != nil {
+		return s, err
 	}
-	return msgs, nil
+	err := r.dueQuery(time.Now().UTC()).Count(&s.Due).Error
+	return s, err
 }
 
 // MarkSent mesajı gönderilmiş olarak işaretler
-func (r *MySQLMessageRepository) MarkSent(id uint, webhookMsgId string) error {
-	return r.db.Model(&MessageModel{}).Where("id = ?", id).Updates(map[string]interface{}{
-		"sent": true, "webhook_msg_id": webhookMsgId, "sent_at": gorm.Expr("NOW()"),
-	}).Error
+func (r *MySQLMessageRepository) MarkSent(id uint, webhookMsgId string, source entity.MessageIDSource) error {
+	now := time.Now().UTC()
+	return r.updateStatus(id, map[string]interface{}{
+		"sent": true, "status": entity.StatusSent, "webhook_msg_id": webhookMsgId,
+		"webhook_msg_id_source": string(source), "sent_at": now, "next_attempt_at": nil,
+	}, entity.EventMessageSent, entity.MessageEvent{
+		Status: entity.StatusSent, WebhookMsgID: webhookMsgId, WebhookMsgIDSource: source, OccurredAt: now,
+	})
 }
 
-// ListSent gönderilmiş tüm mesajları getirir
-func (r *MySQLMessageRepository) ListSent() ([]*entity.Message, error) {
+// ListSent gönderilmiş mesajları etiket ve metadata filtresine göre getirir
+func (r *MySQLMessageRepository) ListSent(f repository.MessageFilter) ([]*entity.Message, error) {
+	q := r.db.Preload("Tags").Where("sent = ?", true)
+	if f.Tag != "" {
+		q = q.Where("id IN (?)", r.db.Model(&MessageTagModel{}).Select("message_id").Where("tag = ?", f.Tag))
+	}
+	if f.MetadataKey != "" {
+		// anahtar parametre olarak verilir, MySQL ve SQLite json_extract ile aynı path'i kabul eder
+		path := fmt.Sprintf(`$."%s"`, f.MetadataKey)
+		if f.MetadataValue != "" {
+			q = q.Where("json_extract(metadata, ?) = ?", path, f.MetadataValue)
+		} else {
+			q = q.Where("json_extract(metadata, ?) IS NOT NULL", path)
+		}
+	}
 	var rows []MessageModel
-	if err := r.db.Where("sent = ?", true).Order("sent_at desc").Find(&rows).Error; err != nil {
+	if err := q.Order("sent_at desc").Find(&rows).Error; err != nil {
 		return nil, err
 	}
+	return toEntities(rows), nil
+}
+
+// RecordAttempt deneme kaydını ekler, mesajın deneme sayacını ve son hatasını günceller
+func (r *MySQLMessageRepository) RecordAttempt(a *entity.Attempt) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		row := MessageAttemptModel{
+			MessageID: a.MessageID, Number: a.Number, Outcome: string(a.Outcome),
+			StatusCode: a.StatusCode, Error: truncate(a.Error, 512),
+			ResponseBody: truncate(a.ResponseBody, 512), LatencyMs: a.LatencyMs,
+		}
+		if err := tx.Create(&row).Error; err != nil {
+			return err
+		}
+		a.ID = row.ID
+		a.CreatedAt = row.CreatedAt
+		return tx.Model(&MessageModel{}).Where("id = ?", a.MessageID).Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": truncate(a.Error, 512),
+		}).Error
+	})
+}
+
+// Defer mesajın bir sonraki deneme zamanını ayarlar
+func (r *MySQLMessageRepository) Defer(id uint, until time.Time) error {
+	return r.db.Model(&MessageModel{}).Where("id = ?", id).
+		Update("next_attempt_at", until.UTC()).Error
+}
+
+// MarkFailed mesajı kalıcı olarak başarısız işaretler
+func (r *MySQLMessageRepository) MarkFailed(id uint, reason string) error {
+	return r.updateStatus(id, map[string]interface{}{
+		"status": entity.StatusFailed, "last_error": truncate(reason, 512), "next_attempt_at": nil,
+	}, entity.EventMessageFailed, entity.MessageEvent{Status: entity.StatusFailed, Reason: truncate(reason, 512)})
+}
+
+// Quarantine mesajı karantinaya alır, sebebi last_error'a yazılır
+func (r *MySQLMessageRepository) Quarantine(id uint, reason string) error {
+	return r.updateStatus(id, map[string]interface{}{
+		"status": entity.StatusQuarantined, "last_error": truncate(reason, 512), "next_attempt_at": nil,
+	}, entity.EventMessageQuarantined, entity.MessageEvent{Status: entity.StatusQuarantined, Reason: truncate(reason, 512)})
+}
+
+// SentSince since'ten sonra gönderilmiş mesajları gönderim sırasına göre getirir
+func (r *MySQLMessageRepository) SentSince(since time.Time) ([]*entity.Message, error) {
+	var rows []MessageModel
+	if err := r.db.Where("status = ? AND sent_at >= ?", entity.StatusSent, since.UTC()).
+		Order("sent_at").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return toEntities(rows), nil
+}
+
+// purgeChunk tek transaction'da silinen mesaj sayısı, büyük tabloları uzun süre kilitlememek için
+const purgeChunk = 1000
+
+// PurgeBefore tamamlanmış eski mesajları denemeleri, etiketleri, linkleri ve
+// tıklamalarıyla birlikte parça parça siler. Karantinadaki mesajlar incelenmek
+// üzere tutulur.
+func (r *MySQLMessageRepository) PurgeBefore(before time.Time) (int64, error) {
+	var total int64
+	for {
+		var ids []uint
+		err := r.db.Model(&MessageModel{}).
+			Where("status IN ? AND COALESCE(sent_at, updated_at) < ?",
+				[]entity.MessageStatus{entity.StatusSent, entity.StatusFailed, entity.StatusCancelled}, before.UTC()).
+			Limit(purgeChunk).Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return total, err
+		}
+		err = r.db.Transaction(func(tx *gorm.DB) error {
+			for _, child := range []interface{}{&MessageAttemptModel{}, &MessageTagModel{}, &LinkClickModel{}, &ShortLinkModel{}} {
+				if err := tx.Where("message_id IN ?", ids).Delete(child).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Where("id IN ?", ids).Delete(&MessageModel{}).Error
+		})
+		if err != nil {
+			return total, err
+		}
+		total += int64(len(ids))
+		if len(ids) < purgeChunk {
+			return total, nil
+		}
+	}
+}
+
+// toEntities veritabanı satırlarını domain entity'lerine çevirir
+func toEntities(rows []MessageModel) []*entity.Message {
 	msgs := make([]*entity.Message, 0, len(rows))
 	for _, rr := range rows {
-		msgs = append(msgs, &entity.Message{
-			ID: rr.ID, To: rr.To, Content: rr.Content, Sent: rr.Sent,
-			SentAt: rr.SentAt, WebhookMsgID: rr.WebhookMsgID,
-			CreatedAt: rr.CreatedAt, UpdatedAt: rr.UpdatedAt,
-		})
+		msgs = append(msgs, toEntity(rr))
+	}
+	return msgs
+}
+
+// toEntity tek bir veritabanı satırını domain entity'sine çevirir
+func toEntity(rr MessageModel) *entity.Message {
+	return &entity.Message{
+		ID:                 rr.ID,
+		To:                 rr.To,
+		Country:            rr.Country,
+		Content:            rr.Content,
+		Sent:               rr.Sent,
+		Status:             entity.MessageStatus(rr.Status),
+		Priority:           entity.Priority(rr.Priority),
+		Category:           entity.Category(rr.Category),
+		TemplateID:         rr.TemplateID,
+		CampaignID:         rr.CampaignID,
+		Metadata:           decodeMetadata(rr.Metadata),
+		Tags:               tagNames(rr.Tags),
+		Links:              toLinks(rr.Links),
+		Attempts:           rr.Attempts,
+		LastError:          rr.LastError,
+		NextAttemptAt:      rr.NextAttemptAt,
+		SentAt:             rr.SentAt,
+		WebhookMsgID:       rr.WebhookMsgID,
+		WebhookMsgIDSource: entity.MessageIDSource(rr.WebhookMsgIDSource),
+		CreatedAt:          rr.CreatedAt,
+		UpdatedAt:          rr.UpdatedAt,
+	}
+}
+
+// decodeMetadata JSON metadata kolonunu map'e çevirir, bozuk veri yok sayılır
+func decodeMetadata(s *string) map[string]string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	var md map[string]string
+	if err := json.Unmarshal([]byte(*s), &md); err != nil {
+		return nil
+	}
+	return md
+}
+
+// tagNames etiket satırlarını isim listesine çevirir
+func tagNames(rows []MessageTagModel) []string {
+	if len(rows) == 0 {
+		return nil
+	}
+	tags := make([]string, len(rows))
+	for i, t := range rows {
+		tags[i] = t.Tag
+	}
+	return tags
+}
+
+// toLinks kısa link satırlarını entity'ye çevirir
+func toLinks(rows []ShortLinkModel) []entity.Link {
+	if len(rows) == 0 {
+		return nil
+	}
+	links := make([]entity.Link, len(rows))
+	for i, l := range rows {
+		links[i] = entity.Link{ID: l.ID, MessageID: l.MessageID, Code: l.Code, TargetURL: l.TargetURL}
+	}
+	return links
+}
+
+// truncate string'i kolon boyutuna göre kısaltır
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
 	}
-	return msgs, nil
+	return s
 }
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type MySQLSchedulerRunRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLSchedulerRunRepository yeni bir çalışma geçmişi repository'si oluşturur ve tabloyu hazırlar
+func NewMySQLSchedulerRunRepository(db *gorm.DB) repository.SchedulerRunRepository {
+	db.AutoMigrate(&SchedulerRunModel{})
+	return &MySQLSchedulerRunRepository{db: db}
+}
+
+// Record çalışmayı ekler, tablo en yeni keep kayıtla sınırlı tutulur
+func (r *MySQLSchedulerRunRepository) Record(run *entity.SchedulerRun, keep int) error {
+	row := SchedulerRunModel{
+		Trigger: run.Trigger, RequestedBy: run.RequestedBy, Instance: run.Instance, StartedAt: run.StartedAt.UTC(),
+		FinishedAt: run.FinishedAt.UTC(), DurationMs: run.DurationMs, BatchSize: run.BatchSize,
+		Sizing: run.Sizing, Backlog: run.Backlog,
+		Fetched: run.Fetched, Sent: run.Sent, Failed: run.Failed, Retried: run.Retried,
+		Skipped: run.Skipped, Error: truncate(run.Error, 512),
+	}
+	if err := r.db.Create(&row).Error; err != nil {
+		return err
+	}
+	run.ID = row.ID
+	if keep <= 0 {
+		return nil
+	}
+	var ids []uint
+	if err := r.db.Model(&SchedulerRunModel{}).Order("id desc").Offset(keep).Limit(1).Pluck("id", &ids).Error; err != nil || len(ids) == 0 {
+		return err
+	}
+	return r.db.Where("id <= ?", ids[0]).Delete(&SchedulerRunModel{}).Error
+}
+
+// Recent en yeni çalışmaları getirir
+func (r *MySQLSchedulerRunRepository) Recent(limit int) ([]*entity.SchedulerRun, error) {
+	var rows []SchedulerRunModel
+	if err := r.db.Order("id desc").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	runs := make([]*entity.SchedulerRun, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, &entity.SchedulerRun{
+			ID: row.ID, Trigger: row.Trigger, RequestedBy: row.RequestedBy, Instance: row.Instance, StartedAt: row.StartedAt,
+			FinishedAt: row.FinishedAt, DurationMs: row.DurationMs, BatchSize: row.BatchSize,
+			Sizing: row.Sizing, Backlog: row.Backlog,
+			Fetched: row.Fetched, Sent: row.Sent, Failed: row.Failed, Retried: row.Retried,
+			Skipped: row.Skipped, Error: row.Error,
+		})
+	}
+	return runs, nil
+}
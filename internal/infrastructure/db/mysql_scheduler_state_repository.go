@@ -0,0 +1,132 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// schedulerStateRowID durum tek bir satırda tutulur
+const schedulerStateRowID = 1
+
+type MySQLSchedulerStateRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLSchedulerStateRepository yeni bir scheduler durum repository'si oluşturur ve tabloyu hazırlar
+func NewMySQLSchedulerStateRepository(db *gorm.DB) repository.SchedulerStateRepository {
+	db.AutoMigrate(&SchedulerStateModel{}, &SchedulerStateChangeModel{})
+	return &MySQLSchedulerStateRepository{db: db}
+}
+
+// Load kayıtlı durumu getirir
+func (r *MySQLSchedulerStateRepository) Load() (*entity.SchedulerState, error) {
+	var row SchedulerStateModel
+	if err := r.db.First(&row, schedulerStateRowID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entity.SchedulerState{
+		Running:     row.Running,
+		StartedBy:   row.StartedBy,
+		StartedAt:   row.StartedAt,
+		StoppedBy:   row.StoppedBy,
+		StoppedAt:   row.StoppedAt,
+		Leader:      row.Leader,
+		LeaderSince: row.LeaderSince,
+	}, nil
+}
+
+// InitDesired satır yoksa ekler; aynı anda açılan instance'lardan sadece biri yazar
+func (r *MySQLSchedulerStateRepository) InitDesired(s *entity.SchedulerState, change *entity.SchedulerStateChange) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		row := SchedulerStateModel{ID: schedulerStateRowID}
+		setDesired(&row, s)
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&row)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		created = true
+		return recordChange(tx, change)
+	})
+	return created, err
+}
+
+// SaveDesired istenen durumu satır kilidi altında yazar
+func (r *MySQLSchedulerStateRepository) SaveDesired(s *entity.SchedulerState, change *entity.SchedulerStateChange) error {
+	return r.update(func(tx *gorm.DB, row *SchedulerStateModel) error {
+		setDesired(row, s)
+		return recordChange(tx, change)
+	})
+}
+
+func setDesired(row *SchedulerStateModel, s *entity.SchedulerState) {
+	row.Running = s.Running
+	row.StartedBy, row.StartedAt = s.StartedBy, s.StartedAt
+	row.StoppedBy, row.StoppedAt = s.StoppedBy, s.StoppedAt
+}
+
+func recordChange(tx *gorm.DB, c *entity.SchedulerStateChange) error {
+	row := SchedulerStateChangeModel{
+		Running:  c.Running,
+		Actor:    truncate(c.Actor, 64),
+		APIKey:   c.APIKey,
+		Instance: c.Instance,
+	}
+	if err := tx.Create(&row).Error; err != nil {
+		return err
+	}
+	c.ID, c.CreatedAt = row.ID, row.CreatedAt
+	return nil
+}
+
+// Changes denetim kayıtlarını yeniden eskiye getirir
+func (r *MySQLSchedulerStateRepository) Changes(limit int) ([]*entity.SchedulerStateChange, error) {
+	var rows []SchedulerStateChangeModel
+	if err := r.db.Order("id DESC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.SchedulerStateChange, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, &entity.SchedulerStateChange{
+			ID:        row.ID,
+			Running:   row.Running,
+			Actor:     row.Actor,
+			APIKey:    row.APIKey,
+			Instance:  row.Instance,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// SetLeader lider bilgisini satır kilidi altında yazar
+func (r *MySQLSchedulerStateRepository) SetLeader(leader string, since time.Time) error {
+	return r.update(func(tx *gorm.DB, row *SchedulerStateModel) error {
+		row.Leader, row.LeaderSince = leader, since
+		return nil
+	})
+}
+
+func (r *MySQLSchedulerStateRepository) update(apply func(*gorm.DB, *SchedulerStateModel) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var row SchedulerStateModel
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&row, schedulerStateRowID).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		row.ID = schedulerStateRowID
+		if err := apply(tx, &row); err != nil {
+			return err
+		}
+		return tx.Save(&row).Error
+	})
+}
@@ -0,0 +1,127 @@
+package db
+
+import (
+	"errors"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"gorm.io/gorm"
+)
+
+type MySQLTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewMySQLTemplateRepository yeni bir şablon repository'si oluşturur ve tabloları hazırlar
+func NewMySQLTemplateRepository(db *gorm.DB) repository.TemplateRepository {
+	db.AutoMigrate(&TemplateModel{}, &TemplateVariantModel{})
+	return &MySQLTemplateRepository{db: db}
+}
+
+// Create şablonu varyantlarıyla birlikte kaydeder
+func (r *MySQLTemplateRepository) Create(t *entity.Template) error {
+	row := toTemplateModel(t)
+	if err := r.db.Create(&row).Error; err != nil {
+		return err
+	}
+	t.ID = row.ID
+	t.CreatedAt = row.CreatedAt
+	t.UpdatedAt = row.UpdatedAt
+	return nil
+}
+
+// Update şablonu günceller, eski varyantları silip yenilerini yazar
+func (r *MySQLTemplateRepository) Update(t *entity.Template) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing TemplateModel
+		if err := tx.First(&existing, t.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return repository.ErrNotFound
+			}
+			return err
+		}
+		row := toTemplateModel(t)
+		existing.Name = row.Name
+		existing.DefaultLocale = row.DefaultLocale
+		if err := tx.Omit("Variants").Save(&existing).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("template_id = ?", t.ID).Delete(&TemplateVariantModel{}).Error; err != nil {
+			return err
+		}
+		for i := range row.Variants {
+			row.Variants[i].TemplateID = t.ID
+		}
+		if err := tx.Create(&row.Variants).Error; err != nil {
+			return err
+		}
+		t.CreatedAt = existing.CreatedAt
+		t.UpdatedAt = existing.UpdatedAt
+		return nil
+	})
+}
+
+// Get ID ile şablonu varyantlarıyla getirir
+func (r *MySQLTemplateRepository) Get(id uint) (*entity.Template, error) {
+	var row TemplateModel
+	if err := r.db.Preload("Variants").First(&row, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+	return toTemplateEntity(row), nil
+}
+
+// List tüm şablonları isim sırasıyla getirir
+func (r *MySQLTemplateRepository) List() ([]*entity.Template, error) {
+	var rows []TemplateModel
+	if err := r.db.Preload("Variants").Order("name asc").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]*entity.Template, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, toTemplateEntity(row))
+	}
+	return out, nil
+}
+
+// Delete şablonu ve varyantlarını siler
+func (r *MySQLTemplateRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&TemplateModel{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return repository.ErrNotFound
+		}
+		return tx.Where("template_id = ?", id).Delete(&TemplateVariantModel{}).Error
+	})
+}
+
+// toTemplateModel domain şablonunu veritabanı satırına çevirir
+func toTemplateModel(t *entity.Template) TemplateModel {
+	row := TemplateModel{ID: t.ID, Name: t.Name, DefaultLocale: t.DefaultLocale}
+	for _, v := range t.Variants {
+		row.Variants = append(row.Variants, TemplateVariantModel{Locale: v.Locale, Body: v.Body})
+	}
+	return row
+}
+
+// toTemplateEntity veritabanı satırını domain şablonuna çevirir
+func toTemplateEntity(row TemplateModel) *entity.Template {
+	t := &entity.Template{
+		ID:            row.ID,
+		Name:          row.Name,
+		DefaultLocale: row.DefaultLocale,
+		Variants:      make([]entity.TemplateVariant, 0, len(row.Variants)),
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}
+	for _, v := range row.Variants {
+		t.Variants = append(t.Variants, entity.TemplateVariant{Locale: v.Locale, Body: v.Body})
+	}
+	return t
+}
@@ -0,0 +1,19 @@
+package db
+
+import "time"
+
+type TemplateModel struct {
+	ID            uint                   `gorm:"primaryKey;autoIncrement"`
+	Name          string                 `gorm:"size:128;uniqueIndex"`
+	DefaultLocale string                 `gorm:"size:16"`
+	Variants      []TemplateVariantModel `gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE"`
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type TemplateVariantModel struct {
+	ID         uint   `gorm:"primaryKey;autoIncrement"`
+	TemplateID uint   `gorm:"uniqueIndex:idx_template_locale"`
+	Locale     string `gorm:"size:16;uniqueIndex:idx_template_locale"`
+	Body       string `gorm:"type:text"`
+}
@@ -0,0 +1,314 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+)
+
+var _ application.SchedulerController = (*Cluster)(nil)
+
+// Cluster birden fazla instance'ta scheduler'ı lider seçimiyle çalıştırır. İstenen
+// durum veritabanında tutulur ve hangi instance'a gelirse gelsin başlatma/durdurma
+// herkese uygulanır; zamanlamaları sadece kilidi tutan lider çalıştırır. Lider ölür
+// veya kilidi yenileyemezse kilidin süresi dolunca başka bir instance devralır.
+// Kilit verilmezse tek instance kabul edilir; bu instance her zaman liderdir ve
+// durumu restart'lar arasında korumak için veritabanını kullanır.
+type Cluster struct {
+	s     *Scheduler
+	lock  application.LeaderLock
+	state repository.SchedulerStateRepository
+	// owner kilitte bu instance'ı temsil eder; aynı host'taki süreçler ayrışsın diye
+	// host adına rastgele bir ek alır
+	owner string
+	ttl   time.Duration
+	wake  chan struct{}
+
+	mu      sync.Mutex
+	leader  bool
+	desired entity.SchedulerState
+	// reconciledAt döngünün son turu, döngü takılırsa liveness başarısız olur
+	reconciledAt time.Time
+}
+
+// NewCluster scheduler'ı lider seçimine bağlar. Kilit ve istenen durum ttl/3
+// aralıklarla yenilenir; lock nil olabilir.
+func NewCluster(s *Scheduler, lock application.LeaderLock, state repository.SchedulerStateRepository, ttl time.Duration) *Cluster {
+	return &Cluster{
+		s:     s,
+		lock:  lock,
+		state: state,
+		owner: s.instance + "/" + newRunID()[:6],
+		ttl:   ttl,
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Run ctx kapanana kadar liderliği dener veya yeniler ve istenen durumu uygular.
+// Kapanırken scheduler'ı durdurur ve kilidi bırakır, böylece devir beklemeden olur.
+func (c *Cluster) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.ttl / 3)
+	defer ticker.Stop()
+	for {
+		c.reconcile(ctx)
+		select {
+		case <-ctx.Done():
+			c.resign()
+			return
+		case <-ticker.C:
+		case <-c.wake:
+		}
+	}
+}
+
+// reconcile kilidi alır veya yeniler, istenen durumu okur ve yerel scheduler'ı
+// buna göre başlatır ya da durdurur. Kilide ulaşılamazsa lider kendini geri çeker;
+// iki lider olmasındansa kısa süre hiç gönderim yapılmaması tercih edilir.
+func (c *Cluster) reconcile(ctx context.Context) {
+	leader, err := true, error(nil)
+	if c.lock != nil {
+		lockCtx, cancel := context.WithTimeout(ctx, c.ttl/3)
+		leader, err = c.lock.Acquire(lockCtx, c.owner, c.ttl)
+		cancel()
+		if err != nil {
+			log.Printf("leader lock failed: %v", err)
+			leader = false
+		}
+	}
+	st, err := c.state.Load()
+	if err != nil {
+		log.Printf("scheduler state load failed: %v", err)
+	}
+
+	c.mu.Lock()
+	was := c.leader
+	c.leader = leader
+	c.reconciledAt = time.Now()
+	if err == nil && st != nil {
+		c.desired = *st
+	}
+	desired := c.desired
+	c.mu.Unlock()
+
+	if leader && !was && c.lock != nil {
+		log.Printf("scheduler leadership acquired by %s", c.owner)
+		now := time.Now().UTC()
+		if err := c.state.SetLeader(c.owner, now); err != nil {
+			log.Printf("scheduler leader record failed: %v", err)
+		} else {
+			c.mu.Lock()
+			c.desired.Leader, c.desired.LeaderSince = c.owner, now
+			c.mu.Unlock()
+		}
+	} else if !leader && was {
+		log.Printf("scheduler leadership lost by %s", c.owner)
+	}
+
+	switch {
+	case leader && desired.Running:
+		c.s.Start(application.Actor{Name: desired.StartedBy})
+	case leader:
+		c.stop(desired.StoppedBy)
+	case was:
+		// kilit başka bir instance'a geçmiş olabilir, aynı mesajlar iki kez
+		// gönderilmesin diye süren çalışmalar beklenmeden iptal edilir
+		c.stop("leadership lost")
+		c.s.Abort()
+	default:
+		c.s.loadHistory()
+	}
+}
+
+// stop scheduler'ı durdurur ama süren çalışmaların bitmesini arka planda bekler;
+// döngü beklemediği için lider boşaltma sırasında kilidi yenilemeye devam eder
+func (c *Cluster) stop(actor string) {
+	if !c.s.IsRunning() {
+		return
+	}
+	wait := c.s.halt(application.Actor{Name: actor})
+	go wait()
+}
+
+// resign süren batch'in bitmesini bekleyip scheduler'ı durdurur ve kilidi bırakır.
+// Kilit boşaltma bitene kadar tutulur ki yeni lider aynı mesajları göndermesin.
+func (c *Cluster) resign() {
+	draining := make(chan struct{})
+	if c.lock != nil && c.IsLeader() {
+		go c.holdLock(draining)
+	}
+	drained := c.s.Shutdown()
+	close(draining)
+	if !drained {
+		log.Printf("scheduler drain did not finish in time, unsent messages were left pending")
+	}
+	if c.lock != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		if err := c.lock.Release(ctx, c.owner); err != nil {
+			log.Printf("leader lock release failed: %v", err)
+		}
+	}
+	c.mu.Lock()
+	c.leader = false
+	c.mu.Unlock()
+}
+
+// holdLock boşaltma sürerken kilidin süresini uzatır, boşaltma ttl'den uzun sürebilir
+func (c *Cluster) holdLock(done <-chan struct{}) {
+	ticker := time.NewTicker(c.ttl / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.ttl/3)
+			if _, err := c.lock.Acquire(ctx, c.owner, c.ttl); err != nil {
+				log.Printf("leader lock renew during drain failed: %v", err)
+			}
+			cancel()
+		}
+	}
+}
+
+// Health döngü 3 ttl boyunca tur atmadıysa veya süren batch takıldıysa down
+// döner. Döngü henüz başlamadıysa veya kapanışta boşaltma için durduysa up
+// sayılır; durdurma döngüyü bekletmediği için normal çalışmada tur gecikmez.
+func (c *Cluster) Health(ctx context.Context) application.ComponentHealth {
+	if c.s.closing.Load() {
+		return application.ComponentHealth{Status: application.HealthUp, Message: "draining"}
+	}
+	c.mu.Lock()
+	last, leader, running := c.reconciledAt, c.leader, c.desired.Running
+	c.mu.Unlock()
+	if !last.IsZero() && time.Since(last) > 3*c.ttl {
+		return application.ComponentHealth{
+			Status:  application.HealthDown,
+			Message: fmt.Sprintf("scheduler loop has not run for %s", time.Since(last).Round(time.Second)),
+		}
+	}
+	h := c.s.Health()
+	if h.Status == application.HealthUp {
+		h.Message = fmt.Sprintf("leader=%t running=%t", leader, running)
+	}
+	return h
+}
+
+// Bootstrap hiç kaydedilmiş durum yoksa (ilk açılış) istenen durumu autoStart'a
+// göre yazar. Kayıtlı durum varsa restart öncesindeki durum geçerli kalır.
+func (c *Cluster) Bootstrap(autoStart bool) error {
+	now := time.Now().UTC()
+	st := &entity.SchedulerState{Running: autoStart}
+	if autoStart {
+		st.StartedBy, st.StartedAt = bootstrapActor, now
+	} else {
+		st.StoppedBy, st.StoppedAt = bootstrapActor, now
+	}
+	created, err := c.state.InitDesired(st, &entity.SchedulerStateChange{Running: autoStart, Actor: bootstrapActor, Instance: c.owner})
+	if created {
+		log.Printf("scheduler state initialized with running=%v", autoStart)
+	}
+	return err
+}
+
+// bootstrapActor ilk açılışta durumu AUTO_START'tan yazan sistem kaynağı
+const bootstrapActor = "AUTO_START"
+
+// Start istenen durumu çalışıyor olarak kaydeder
+func (c *Cluster) Start(actor application.Actor) error {
+	return c.setDesired(true, actor)
+}
+
+// Stop istenen durumu durmuş olarak kaydeder, lider çalışan batch'i bitirip durur
+func (c *Cluster) Stop(actor application.Actor) error {
+	return c.setDesired(false, actor)
+}
+
+func (c *Cluster) setDesired(running bool, actor application.Actor) error {
+	st, err := c.state.Load()
+	if err != nil {
+		return err
+	}
+	if st == nil {
+		st = &entity.SchedulerState{}
+	}
+	now := time.Now().UTC()
+	st.Running = running
+	if running {
+		st.StartedBy, st.StartedAt = actor.String(), now
+	} else {
+		st.StoppedBy, st.StoppedAt = actor.String(), now
+	}
+	change := &entity.SchedulerStateChange{Running: running, Actor: actor.Name, APIKey: actor.APIKey, Instance: c.owner}
+	if err := c.state.SaveDesired(st, change); err != nil {
+		return err
+	}
+	log.Printf("scheduler desired state running=%v set by %s", running, actor)
+
+	c.mu.Lock()
+	c.desired = *st
+	c.mu.Unlock()
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// IsRunning istenen durumu döner, scheduler'ı lider dışındaki instance'lar çalıştırmaz
+func (c *Cluster) IsRunning() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.desired.Running
+}
+
+// IsLeader bu instance'ın lider olup olmadığını döner
+func (c *Cluster) IsLeader() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.leader
+}
+
+// Schedules yerel zamanlamaları döner, lider dışındaki instance'larda nextRunAt boştur
+func (c *Cluster) Schedules() []application.ScheduleInfo {
+	return c.s.Schedules()
+}
+
+// Status yerel çalışma geçmişini istenen durum ve lider bilgisiyle birleştirir
+func (c *Cluster) Status() application.SchedulerStatus {
+	st := c.s.Status()
+	c.mu.Lock()
+	d, leader := c.desired, c.leader
+	c.mu.Unlock()
+
+	st.Running = d.Running
+	st.StartedBy, st.StartedAt = d.StartedBy, timePtr(d.StartedAt)
+	st.StoppedBy, st.StoppedAt = d.StoppedBy, timePtr(d.StoppedAt)
+	st.Instance, st.Leader = c.owner, d.Leader
+	if leader {
+		st.Leader = c.owner
+	}
+	return st
+}
+
+// TriggerRun batch'i sadece liderde çalıştırır, böylece zamanlanmış batch'le çakışmaz
+func (c *Cluster) TriggerRun(actor string, batchSize int) (application.ManualRun, error) {
+	c.mu.Lock()
+	leader, current := c.leader, c.desired.Leader
+	c.mu.Unlock()
+	if !leader {
+		return application.ManualRun{}, fmt.Errorf("%w (leader: %s)", application.ErrNotLeader, current)
+	}
+	return c.s.TriggerRun(actor, batchSize)
+}
+
+// ManualRun bu instance'ta tetiklenen çalışmanın durumunu döner
+func (c *Cluster) ManualRun(ctx context.Context, id string, wait bool) (application.ManualRun, bool) {
+	return c.s.ManualRun(ctx, id, wait)
+}
@@ -0,0 +1,284 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule bir işin çalışma zamanlarını hesaplar
+type Schedule interface {
+	// Next t'den sonraki ilk çalışma zamanını döner, yoksa sıfır zaman
+	Next(t time.Time) time.Time
+}
+
+// everySchedule sabit aralıklı zamanlama (@every 30s)
+type everySchedule struct {
+	every time.Duration
+}
+
+func (s everySchedule) Next(t time.Time) time.Time {
+	return t.Add(s.every - time.Duration(t.Nanosecond()))
+}
+
+// cronSchedule alanları bit maskesi olarak tutulan cron ifadesi
+type cronSchedule struct {
+	second, minute, hour, dom, month, dow uint64
+	// domStar ve dowStar alan * veya ? ise true olur; ikisi de kısıtlıysa
+	// gün eşleşmesi klasik cron'daki gibi VEYA ile yapılır
+	domStar, dowStar bool
+	loc              *time.Location
+}
+
+type bounds struct {
+	min, max uint
+	names    map[string]uint
+}
+
+var (
+	secondBounds = bounds{0, 59, nil}
+	minuteBounds = bounds{0, 59, nil}
+	hourBounds   = bounds{0, 23, nil}
+	domBounds    = bounds{1, 31, nil}
+	monthBounds  = bounds{1, 12, map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// haftanın günü için 7 de pazar kabul edilir
+	dowBounds = bounds{0, 7, map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// ParseCron cron ifadesini çözer. Desteklenen biçimler:
+//   - 5 alan (dakika saat gün ay haftanın-günü) veya başta saniye ile 6 alan
+//   - @yearly, @monthly, @weekly, @daily, @hourly ve @every <süre>
+//   - saat dilimi için başta CRON_TZ=Europe/Istanbul (yoksa UTC)
+func ParseCron(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, errors.New("empty cron spec")
+	}
+
+	loc := time.UTC
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		tz, rest, _ := strings.Cut(spec, " ")
+		_, name, _ := strings.Cut(tz, "=")
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+		}
+		loc = l
+		spec = strings.TrimSpace(rest)
+	}
+
+	if strings.HasPrefix(spec, "@every") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every")))
+		if err != nil {
+			return nil, fmt.Errorf("invalid @every duration in %q", spec)
+		}
+		if d < time.Second {
+			return nil, fmt.Errorf("@every duration must be at least 1s, got %v", d)
+		}
+		return everySchedule{every: d.Truncate(time.Second)}, nil
+	}
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("unknown descriptor %q", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("expected 5 or 6 fields, got %d in %q", len(fields), spec)
+	}
+
+	s := &cronSchedule{loc: loc}
+	var err error
+	if s.second, _, err = parseField(fields[0], secondBounds); err != nil {
+		return nil, fmt.Errorf("second: %w", err)
+	}
+	if s.minute, _, err = parseField(fields[1], minuteBounds); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if s.hour, _, err = parseField(fields[2], hourBounds); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if s.dom, s.domStar, err = parseField(fields[3], domBounds); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if s.month, _, err = parseField(fields[4], monthBounds); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	if s.dow, s.dowStar, err = parseField(fields[5], dowBounds); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// parseField virgülle ayrılmış liste, aralık (1-5), adım (*/15, 10-40/5)
+// ve isim (MON, JAN) içeren tek bir alanı bit maskesine çevirir
+func parseField(field string, b bounds) (uint64, bool, error) {
+	var mask uint64
+	star := false
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		var lo, hi uint
+		switch {
+		case rng == "*" || rng == "?":
+			lo, hi = b.min, b.max
+			star = star || !hasStep
+		default:
+			from, to, isRange := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseValue(from, b); err != nil {
+				return 0, false, err
+			}
+			hi = lo
+			if isRange {
+				if hi, err = parseValue(to, b); err != nil {
+					return 0, false, err
+				}
+			} else if hasStep {
+				hi = b.max
+			}
+		}
+		if lo > hi {
+			return 0, false, fmt.Errorf("invalid range %q", part)
+		}
+		step := uint(1)
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, false, fmt.Errorf("invalid step in %q", part)
+			}
+			step = uint(n)
+		}
+		for v := lo; v <= hi; v += step {
+			mask |= 1 << v
+		}
+	}
+	return mask, star, nil
+}
+
+func parseValue(s string, b bounds) (uint, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < int(b.min) || n > int(b.max) {
+		return 0, fmt.Errorf("value %q out of range %d-%d", s, b.min, b.max)
+	}
+	return uint(n), nil
+}
+
+// Next t'den sonraki ilk eşleşen saniyeyi bulur. Eşleşmeyen en büyük birimden
+// başlayarak ilerler; bir birim taşarsa baştan kontrol eder. Beş yıl içinde
+// eşleşme yoksa (örn. 30 Şubat) sıfır zaman döner.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	orig := t.Location()
+	t = t.In(s.loc)
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	added := false
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.loc)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+
+	for !s.dayMatches(t) {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.loc)
+		}
+		t = t.AddDate(0, 0, 1)
+		// yaz saati geçişinde gece yarısı olmayabilir, günün başına hizala
+		if t.Hour() != 0 {
+			if t.Hour() > 12 {
+				t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+			} else {
+				t = t.Add(-time.Duration(t.Hour()) * time.Hour)
+			}
+		}
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !added {
+			added = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.loc)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+
+	for s.second&(1<<uint(t.Second())) == 0 {
+		if !added {
+			added = true
+			t = t.Truncate(time.Second)
+		}
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+
+	return t.In(orig)
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
@@ -2,84 +2,542 @@ package scheduler
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
 )
 
 var _ application.SchedulerController = (*Scheduler)(nil)
 
-// Scheduler belirli aralıklarla mesaj gönderme işlemini çalıştırır
+// Zamanlamaların bağlanabileceği iş isimleri
+const (
+	JobSendBatch      = "send-batch"
+	JobRetention      = "retention"
+	JobCacheReconcile = "cache-reconcile"
+)
+
+// ErrUnknownSchedule verilen isimde zamanlama yoksa döner
+var ErrUnknownSchedule = errors.New("unknown schedule")
+
+// ErrNoIntervalSchedule SCHEDULES ile özel zamanlamalar verildiği için gönderim aralığı uygulanamıyorsa döner
+var ErrNoIntervalSchedule = errors.New("send interval applies only to the SCHEDULE_SECONDS schedule")
+
+// Job scheduler'ın bir zamanlamaya bağlı olarak çalıştırdığı iş
+type Job func(ctx context.Context) error
+
+type job struct {
+	run     Job
+	timeout time.Duration
+	// sem aynı işin üst üste çalışmasını engeller; zamanlamalar doluysa atlar,
+	// elle tetiklenen çalışmalar boşalmasını bekler
+	sem chan struct{}
+}
+
+func newJob(run Job, timeout time.Duration) *job {
+	return &job{run: run, timeout: timeout, sem: make(chan struct{}, 1)}
+}
+
+// manualRun elle tetiklenen çalışmanın takip kaydı, done bittiğinde kapanır
+type manualRun struct {
+	info application.ManualRun
+	done chan struct{}
+}
+
+// Elle tetiklenen çalışmaların timeout'u ve bellekte tutulan kayıt sayısı.
+// Büyük batch'ler zamanlamalardan daha uzun sürebilir.
+const (
+	manualRunTimeout = 10 * time.Minute
+	manualRunKeep    = 50
+)
+
+// entry tek bir zamanlamanın tanımı ve son durumu
+type entry struct {
+	name     string
+	job      string
+	spec     string
+	schedule Schedule
+	next     time.Time
+	lastRun  time.Time
+	lastErr  string
+	// interval zamanlama SCHEDULE_SECONDS'tan oluşturulduysa true
+	interval bool
+	// reset zamanlama değiştiğinde bekleyen timer'ı yeniden kurdurur
+	reset chan struct{}
+}
+
+// streamTrigger sürekli gönderim modunda bildirimle çalışan batch'lerin tetikleyicisi
+const streamTrigger = "stream"
+
+// defaultHistorySize bellekte ve veritabanında tutulan batch çalışması sayısı
+const defaultHistorySize = 20
+
+// Scheduler isimli zamanlamalara göre işleri çalıştırır
 type Scheduler struct {
-	uc      *application.SendBatchUseCase
-	cfg     *config.Config
-	ticker  *time.Ticker
-	stopCh  chan struct{}
-	running bool
-	mu      sync.Mutex
-	wg      sync.WaitGroup
+	uc       *application.SendBatchUseCase
+	instance string
+	jobs     map[string]*job
+	entries  []*entry
+	stopCh   chan struct{}
+	running  bool
+	mu       sync.Mutex
+	// stateMu entry durumlarını, çalışma geçmişini ve başlatma/durdurma bilgisini
+	// korur; Stop mu'yu tutarken işlerin bitmesini beklediği için çalışan işler mu'yu alamaz
+	stateMu sync.Mutex
+	// loops son Start'ın döngülerini ve ondan önce durdurulup hâlâ boşalan
+	// döngüleri bekler; her Start yeni bir grup oluşturur
+	loops *sync.WaitGroup
+	// manualWg elle tetiklenen çalışmaları bekler, scheduler durmuş olsa da çalışırlar
+	manualWg sync.WaitGroup
+
+	runs        repository.SchedulerRunRepository
+	historySize int
+	// history en eski başta olacak şekilde son batch çalışmaları
+	history   []*entity.SchedulerRun
+	startedBy string
+	startedAt time.Time
+	stoppedBy string
+	stoppedAt time.Time
+	// manual elle tetiklenen çalışmalar, manualOrder eklenme sırasıyla ID'leri
+	manual      map[string]*manualRun
+	manualOrder []string
+	// stream varsa scheduler çalışırken her sinyalde kuyruk boşalana kadar batch
+	// çalıştırılır; zamanlamalar bu durumda kaçan mesajlar için taramadır
+	stream <-chan struct{}
+
+	// runCtx bütün çalışmaların context'i; kapanışta boşaltma süresi dolunca veya
+	// liderlik kaybedilince Abort ile iptal edilir. stateMu ile korunur.
+	runCtx     context.Context
+	cancelRuns context.CancelFunc
+	// closing kapanış başladıktan sonra yeni çalışma başlatılmasını engeller
+	closing      atomic.Bool
+	drainTimeout time.Duration
+	// batchDeadline süren send-batch çalışmasının deadline'ı (unix nano), çalışan yoksa 0
+	batchDeadline atomic.Int64
+}
+
+// Option scheduler'a opsiyonel iş veya bağımlılık ekler
+type Option func(*Scheduler)
+
+// WithJob zamanlamaların bağlanabileceği bir iş ekler, her çalışma timeout ile sınırlanır
+func WithJob(name string, timeout time.Duration, run Job) Option {
+	return func(s *Scheduler) { s.jobs[name] = newJob(run, timeout) }
 }
 
-// NewScheduler yeni bir scheduler oluşturur
-func NewScheduler(uc *application.SendBatchUseCase, cfg *config.Config) *Scheduler {
-	return &Scheduler{uc: uc, cfg: cfg}
+// WithRunHistory batch çalışmalarını size kayıtlık halka olarak veritabanına yazar,
+// scheduler açılışta son kayıtları buradan yükler
+func WithRunHistory(runs repository.SchedulerRunRepository, size int) Option {
+	return func(s *Scheduler) {
+		s.runs = runs
+		if size > 0 {
+			s.historySize = size
+		}
+	}
+}
+
+// WithStream sürekli gönderim modunu açar. signal'dan her bildirim geldiğinde
+// send-batch kuyrukta gönderilmeye hazır mesaj kalmayana kadar art arda çalışır.
+func WithStream(signal <-chan struct{}) Option {
+	return func(s *Scheduler) { s.stream = signal }
+}
+
+// NewScheduler cfg.Schedules'taki zamanlamalarla yeni bir scheduler oluşturur.
+// send-batch işi her zaman tanımlıdır; bilinmeyen iş veya geçersiz cron ifadesi hata döner.
+func NewScheduler(uc *application.SendBatchUseCase, cfg *config.Config, opts ...Option) (*Scheduler, error) {
+	timeout := time.Duration(cfg.WebhookTimeoutSeconds+10) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	s := &Scheduler{
+		uc:           uc,
+		instance:     instanceName(),
+		jobs:         map[string]*job{JobSendBatch: newJob(nil, timeout)},
+		manual:       map[string]*manualRun{},
+		historySize:  defaultHistorySize,
+		drainTimeout: time.Duration(cfg.DrainTimeoutSeconds) * time.Second,
+	}
+	if s.drainTimeout <= 0 {
+		s.drainTimeout = 20 * time.Second
+	}
+	s.runCtx, s.cancelRuns = context.WithCancel(context.Background())
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.loadHistory()
+
+	for _, sc := range cfg.Schedules {
+		if _, ok := s.jobs[sc.Job]; !ok {
+			return nil, fmt.Errorf("schedule %q: unknown job %q", sc.Name, sc.Job)
+		}
+		sched, err := ParseCron(sc.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("schedule %q: %w", sc.Name, err)
+		}
+		s.entries = append(s.entries, &entry{
+			name: sc.Name, job: sc.Job, spec: sc.Spec, schedule: sched, interval: sc.Interval, reset: make(chan struct{}, 1),
+		})
+	}
+	return s, nil
+}
+
+// loadHistory çalışma geçmişini veritabanından yükler. Lider seçimi açıkken
+// batch'leri çalıştırmayan instance'lar geçmişi bununla güncel tutar.
+func (s *Scheduler) loadHistory() {
+	if s.runs == nil {
+		return
+	}
+	recent, err := s.runs.Recent(s.historySize)
+	if err != nil {
+		log.Printf("scheduler run history load failed: %v", err)
+		return
+	}
+	history := make([]*entity.SchedulerRun, 0, len(recent))
+	for i := len(recent) - 1; i >= 0; i-- {
+		history = append(history, recent[i])
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	s.history = history
+}
+
+// instanceName çalışma kayıtlarında instance'ı ayırt etmek için host adını döner
+func instanceName() string {
+	if h, err := os.Hostname(); err == nil {
+		return h
+	}
+	return "unknown"
 }
 
 // Start scheduler'ı başlatır, zaten çalışıyorsa bir şey yapmaz
-func (s *Scheduler) Start() {
+func (s *Scheduler) Start(actor application.Actor) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if s.running {
-		return
+		return nil
 	}
 
-	interval := time.Duration(s.cfg.ScheduleSec) * time.Second
-	if interval <= 0 {
-		interval = 120 * time.Second
-	}
-
-	s.ticker = time.NewTicker(interval)
 	s.stopCh = make(chan struct{})
 	s.running = true
-	s.wg.Add(1)
-	go s.loop()
+	s.stateMu.Lock()
+	s.startedBy, s.startedAt = actor.String(), time.Now().UTC()
+	s.stateMu.Unlock()
+	log.Printf("scheduler started by %s", actor)
+	loops := new(sync.WaitGroup)
+	if prev := s.loops; prev != nil {
+		loops.Add(1)
+		go func() {
+			defer loops.Done()
+			prev.Wait()
+		}()
+	}
+	s.loops = loops
+	for _, e := range s.entries {
+		loops.Add(1)
+		go s.loop(e, s.stopCh, loops)
+	}
+	if s.stream != nil {
+		loops.Add(1)
+		go s.streamLoop(s.stopCh, loops)
+	}
+	return nil
 }
 
-// loop scheduler'ın ana döngüsü, ticker'a göre mesaj gönderme işlemini çalıştırır
-func (s *Scheduler) loop() {
-	defer s.wg.Done()
+// streamLoop başlangıçta ve her bildirimde kuyruğu boşaltır
+func (s *Scheduler) streamLoop(stop <-chan struct{}, loops *sync.WaitGroup) {
+	defer loops.Done()
 	for {
+		if !s.drain(stop) {
+			return
+		}
 		select {
-		case <-s.ticker.C:
-			timeout := time.Duration(s.cfg.WebhookTimeoutSeconds+10) * time.Second
-			if timeout <= 0 {
-				timeout = 30 * time.Second
-			}
-			ctx, cancel := context.WithTimeout(context.Background(), timeout)
-			if err := s.uc.Execute(ctx); err != nil {
-				log.Printf("sendbatch err: %v", err)
-			}
-			cancel()
-		case <-s.stopCh:
+		case <-s.stream:
+		case <-stop:
 			return
 		}
 	}
 }
 
-// Stop scheduler'ı durdurur ve tüm işlemlerin bitmesini bekler
-func (s *Scheduler) Stop() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if !s.running {
+// drain batch'leri kuyruk boşalana kadar art arda çalıştırır. Batch dolu gelmezse,
+// hata dönerse veya circuit/rate limit nedeniyle yarıda kaldıysa durur; kalan
+// mesajlar bir sonraki bildirim veya tarama ile gönderilir. Scheduler durdurulursa false döner.
+func (s *Scheduler) drain(stop <-chan struct{}) bool {
+	j := s.jobs[JobSendBatch]
+	for {
+		select {
+		case j.sem <- struct{}{}:
+		case <-stop:
+			return false
+		}
+		if s.closing.Load() {
+			<-j.sem
+			return false
+		}
+		ctx, cancel := context.WithTimeout(s.runContext(), j.timeout)
+		run, err := s.runBatch(ctx, streamTrigger, "", 0)
+		cancel()
+		<-j.sem
+		if err != nil {
+			log.Printf("stream batch err: %v", err)
+		}
+		processed := run.Sent + run.Failed + run.Retried + run.Skipped
+		if err != nil || run.Fetched == 0 || run.Fetched < run.BatchSize || processed < run.Fetched {
+			return true
+		}
+		select {
+		case <-stop:
+			return false
+		default:
+		}
+	}
+}
+
+// loop bir zamanlamanın döngüsü. Sıradaki zaman iş bittikten sonra hesaplandığı
+// için uzun süren bir çalışmanın kaçırdığı tetiklemeler biriktirilmez.
+func (s *Scheduler) loop(e *entry, stop <-chan struct{}, loops *sync.WaitGroup) {
+	defer loops.Done()
+	for {
+		s.stateMu.Lock()
+		next := e.schedule.Next(time.Now())
+		s.stateMu.Unlock()
+		if next.IsZero() {
+			log.Printf("schedule %s has no next run, stopping it", e.name)
+			return
+		}
+		s.setNext(e, next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.run(e)
+		case <-e.reset:
+			timer.Stop()
+		case <-stop:
+			timer.Stop()
+			s.setNext(e, time.Time{})
+			return
+		}
+	}
+}
+
+// run zamanlamanın işini çalıştırır, iş başka bir zamanlamadan çalışıyorsa atlar
+func (s *Scheduler) run(e *entry) {
+	j := s.jobs[e.job]
+	select {
+	case j.sem <- struct{}{}:
+		defer func() { <-j.sem }()
+	default:
+		log.Printf("schedule %s skipped, job %s still running", e.name, e.job)
 		return
 	}
-	s.ticker.Stop()
-	close(s.stopCh)
-	s.wg.Wait()
-	s.running = false
+	if s.closing.Load() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.runContext(), j.timeout)
+	defer cancel()
+	start := time.Now()
+	var err error
+	if e.job == JobSendBatch {
+		_, err = s.runBatch(ctx, e.name, "", 0)
+	} else {
+		err = j.run(ctx)
+	}
+	if err != nil {
+		log.Printf("%s err: %v", e.job, err)
+	}
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	e.lastRun = start
+	e.lastErr = ""
+	if err != nil {
+		e.lastErr = err.Error()
+	}
+}
+
+// runBatch bir batch çalıştırır ve sonucunu çalışma geçmişine ekler
+func (s *Scheduler) runBatch(ctx context.Context, trigger, actor string, limit int) (*entity.SchedulerRun, error) {
+	run := &entity.SchedulerRun{Trigger: trigger, RequestedBy: actor, Instance: s.instance, StartedAt: time.Now().UTC()}
+	if deadline, ok := ctx.Deadline(); ok {
+		s.batchDeadline.Store(deadline.UnixNano())
+		defer s.batchDeadline.Store(0)
+	}
+	res, err := s.uc.Run(ctx, limit)
+	run.FinishedAt = time.Now().UTC()
+	run.DurationMs = run.FinishedAt.Sub(run.StartedAt).Milliseconds()
+	run.BatchSize, run.Sizing, run.Backlog = res.BatchSize, res.Sizing, res.Backlog
+	run.Fetched, run.Sent = res.Fetched, res.Sent
+	run.Failed, run.Retried, run.Skipped = res.Failed, res.Retried, res.Skipped
+	if err != nil {
+		run.Error = err.Error()
+	}
+	// her bildirimde çalışan stream batch'lerinden boş olanlar geçmişi doldurmasın
+	if trigger != streamTrigger || run.Fetched > 0 || err != nil {
+		s.recordRun(run)
+	}
+	return run, err
+}
+
+// recordRun çalışmayı bellekteki halkaya ekler ve veritabanına yazar
+func (s *Scheduler) recordRun(run *entity.SchedulerRun) {
+	if s.runs != nil {
+		if err := s.runs.Record(run, s.historySize); err != nil {
+			log.Printf("scheduler run record failed: %v", err)
+		}
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	s.history = append(s.history, run)
+	if len(s.history) > s.historySize {
+		s.history = s.history[len(s.history)-s.historySize:]
+	}
+}
+
+func (s *Scheduler) setNext(e *entry, next time.Time) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	e.next = next
+}
+
+// Reschedule bir zamanlamanın cron ifadesini değiştirir. Scheduler çalışıyorsa
+// bekleyen tetikleme iptal edilir ve sıradaki zaman yeni ifadeye göre hesaplanır.
+func (s *Scheduler) Reschedule(name, spec string) error {
+	sched, err := ParseCron(spec)
+	if err != nil {
+		return err
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	for _, e := range s.entries {
+		if e.name != name {
+			continue
+		}
+		if e.spec == spec {
+			return nil
+		}
+		e.spec, e.schedule = spec, sched
+		select {
+		case e.reset <- struct{}{}:
+		default:
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnknownSchedule, name)
+}
+
+// SetInterval SCHEDULE_SECONDS'tan oluşan zamanlamanın aralığını değiştirir.
+// SCHEDULES ile verilen cron ifadelerine dokunulmaz, ErrNoIntervalSchedule döner.
+func (s *Scheduler) SetInterval(seconds int) error {
+	s.stateMu.Lock()
+	name := ""
+	for _, e := range s.entries {
+		if e.interval {
+			name = e.name
+			break
+		}
+	}
+	s.stateMu.Unlock()
+	if name == "" {
+		return ErrNoIntervalSchedule
+	}
+	return s.Reschedule(name, fmt.Sprintf("@every %ds", seconds))
+}
+
+// Stop scheduler'ı durdurur ve tüm işlemlerin bitmesini bekler. Scheduler
+// hemen durmuş sayılır; bekleme kilit dışında yapıldığı için IsRunning ve Start
+// süren batch'in bitmesini beklemez.
+func (s *Scheduler) Stop(actor application.Actor) error {
+	s.halt(actor)()
+	return nil
+}
+
+// halt scheduler'ı durmuş işaretler ve zamanlamaların yeni çalışma başlatmasını
+// engeller. Dönen fonksiyon süren çalışmaların bitmesini bekler.
+func (s *Scheduler) halt(actor application.Actor) (wait func()) {
+	s.mu.Lock()
+	stopped, loops := s.running, s.loops
+	if s.running {
+		close(s.stopCh)
+		s.running = false
+		s.stateMu.Lock()
+		s.stoppedBy, s.stoppedAt = actor.String(), time.Now().UTC()
+		s.stateMu.Unlock()
+	}
+	s.mu.Unlock()
+
+	return func() {
+		if loops != nil {
+			loops.Wait()
+		}
+		if stopped {
+			s.manualWg.Wait()
+			log.Printf("scheduler stopped by %s", actor)
+		}
+	}
+}
+
+// Shutdown kapanışta çağrılır. Yeni çalışma başlatılmaz, süren çalışmaların
+// DRAIN_TIMEOUT_SECONDS içinde bitmesi beklenir; süre dolarsa çalışmalar iptal
+// edilir ve gönderimi başlamamış mesajlar kuyruğa bırakılır. Süre içinde
+// bittiyse true döner.
+func (s *Scheduler) Shutdown() bool {
+	s.closing.Store(true)
+	var expired atomic.Bool
+	timer := time.AfterFunc(s.drainTimeout, func() {
+		expired.Store(true)
+		log.Printf("drain timeout of %v exceeded, cancelling in-flight batches", s.drainTimeout)
+		s.Abort()
+	})
+	defer timer.Stop()
+	s.Stop(application.Actor{Name: "shutdown"})
+	// scheduler durmuşken tetiklenen elle çalışmalar da beklenir
+	s.manualWg.Wait()
+	return !expired.Load()
+}
+
+// Abort süren çalışmaları hemen iptal eder; sonra başlayan çalışmalar yeni bir
+// context alır. Gönderimi başlamamış mesajlar kuyruğa bırakılır.
+func (s *Scheduler) Abort() {
+	s.stateMu.Lock()
+	cancel := s.cancelRuns
+	s.runCtx, s.cancelRuns = context.WithCancel(context.Background())
+	s.stateMu.Unlock()
+	cancel()
+}
+
+// runContext yeni bir çalışmanın türetileceği context
+func (s *Scheduler) runContext() context.Context {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return s.runCtx
+}
+
+// stuckBatchGrace timeout'u dolan batch'in takılmış sayılması için geçmesi gereken ek süre
+const stuckBatchGrace = time.Minute
+
+// Health süren batch timeout'unu çoktan aştıysa (ör. context'e uymayan bir
+// çağrıda takıldıysa) down döner; bu durumda sonraki batch'ler hiç başlayamaz
+func (s *Scheduler) Health() application.ComponentHealth {
+	if d := s.batchDeadline.Load(); d != 0 {
+		if over := time.Since(time.Unix(0, d)); over > stuckBatchGrace {
+			return application.ComponentHealth{
+				Status:  application.HealthDown,
+				Message: fmt.Sprintf("send batch stuck %s past its timeout", over.Round(time.Second)),
+			}
+		}
+	}
+	return application.ComponentHealth{Status: application.HealthUp}
 }
 
 // IsRunning scheduler'ın çalışıp çalışmadığını döndürür
@@ -88,3 +546,144 @@ func (s *Scheduler) IsRunning() bool {
 	defer s.mu.Unlock()
 	return s.running
 }
+
+// Schedules zamanlamaları sıradaki ve son çalışma zamanlarıyla döner
+func (s *Scheduler) Schedules() []application.ScheduleInfo {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	out := make([]application.ScheduleInfo, 0, len(s.entries))
+	for _, e := range s.entries {
+		info := application.ScheduleInfo{Name: e.name, Job: e.job, Spec: e.spec, LastError: e.lastErr}
+		info.NextRunAt, info.LastRunAt = timePtr(e.next), timePtr(e.lastRun)
+		out = append(out, info)
+	}
+	return out
+}
+
+// Status scheduler durumunu, send-batch'in sıradaki çalışmasını ve çalışma geçmişini döner
+func (s *Scheduler) Status() application.SchedulerStatus {
+	st := application.SchedulerStatus{Running: s.IsRunning(), DispatchMode: config.DispatchModeTick}
+	if s.stream != nil {
+		st.DispatchMode = config.DispatchModeStream
+	}
+
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	st.StartedBy, st.StartedAt = s.startedBy, timePtr(s.startedAt)
+	st.StoppedBy, st.StoppedAt = s.stoppedBy, timePtr(s.stoppedAt)
+	for _, e := range s.entries {
+		if e.job == JobSendBatch && !e.next.IsZero() && (st.NextRunAt == nil || e.next.Before(*st.NextRunAt)) {
+			st.NextRunAt = timePtr(e.next)
+		}
+	}
+	st.RecentRuns = make([]*entity.SchedulerRun, 0, len(s.history))
+	for i := len(s.history) - 1; i >= 0; i-- {
+		run := *s.history[i]
+		st.RecentRuns = append(st.RecentRuns, &run)
+		if st.LastError == "" && run.Error != "" {
+			st.LastError, st.LastErrorAt = run.Error, timePtr(run.FinishedAt)
+		}
+	}
+	if len(st.RecentRuns) > 0 {
+		st.LastRun = st.RecentRuns[0]
+	}
+	return st
+}
+
+func timePtr(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// TriggerRun send-batch'i arka planda bir kez çalıştırır. Çalışma isteği yapan
+// HTTP bağlantısından bağımsızdır, istemci beklemeyi bıraksa da batch tamamlanır.
+func (s *Scheduler) TriggerRun(actor string, batchSize int) (application.ManualRun, error) {
+	if s.closing.Load() {
+		return application.ManualRun{}, application.ErrShuttingDown
+	}
+	mr := &manualRun{
+		info: application.ManualRun{
+			ID: newRunID(), Status: application.ManualRunQueued, RequestedBy: actor,
+			RequestedAt: time.Now().UTC(), BatchSize: batchSize,
+		},
+		done: make(chan struct{}),
+	}
+	s.stateMu.Lock()
+	s.manual[mr.info.ID] = mr
+	s.manualOrder = append(s.manualOrder, mr.info.ID)
+	if len(s.manualOrder) > manualRunKeep {
+		delete(s.manual, s.manualOrder[0])
+		s.manualOrder = s.manualOrder[1:]
+	}
+	info := mr.info
+	s.stateMu.Unlock()
+
+	s.manualWg.Add(1)
+	go s.runManual(mr)
+	log.Printf("manual batch run %s requested by %s", info.ID, actor)
+	return info, nil
+}
+
+// runManual çalışan bir batch varsa bitmesini bekler, sonra batch'i çalıştırır
+func (s *Scheduler) runManual(mr *manualRun) {
+	defer s.manualWg.Done()
+	defer close(mr.done)
+
+	j := s.jobs[JobSendBatch]
+	j.sem <- struct{}{}
+	defer func() { <-j.sem }()
+	if s.closing.Load() {
+		s.setManual(mr, func(info *application.ManualRun) {
+			info.Status, info.Error = application.ManualRunFailed, application.ErrShuttingDown.Error()
+		})
+		return
+	}
+	s.setManual(mr, func(info *application.ManualRun) { info.Status = application.ManualRunRunning })
+
+	ctx, cancel := context.WithTimeout(s.runContext(), manualRunTimeout)
+	defer cancel()
+	run, err := s.runBatch(ctx, "manual", mr.info.RequestedBy, mr.info.BatchSize)
+	s.setManual(mr, func(info *application.ManualRun) {
+		info.Run = run
+		info.Status = application.ManualRunSucceeded
+		if err != nil {
+			info.Status, info.Error = application.ManualRunFailed, err.Error()
+		}
+	})
+}
+
+func (s *Scheduler) setManual(mr *manualRun, update func(*application.ManualRun)) {
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	update(&mr.info)
+}
+
+// ManualRun elle tetiklenen çalışmanın güncel durumunu döner
+func (s *Scheduler) ManualRun(ctx context.Context, id string, wait bool) (application.ManualRun, bool) {
+	s.stateMu.Lock()
+	mr, ok := s.manual[id]
+	s.stateMu.Unlock()
+	if !ok {
+		return application.ManualRun{}, false
+	}
+	if wait {
+		select {
+		case <-mr.done:
+		case <-ctx.Done():
+		}
+	}
+	s.stateMu.Lock()
+	defer s.stateMu.Unlock()
+	return mr.info, true
+}
+
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
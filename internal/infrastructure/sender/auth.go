@@ -0,0 +1,262 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/config"
+)
+
+// Webhook kimlik doğrulama modları
+const (
+	AuthModeNone   = "none"
+	AuthModeHeader = "header"
+	AuthModeBasic  = "basic"
+	AuthModeOAuth2 = "oauth2"
+)
+
+// Authenticator webhook isteğine kimlik bilgisini ekler
+type Authenticator interface {
+	Apply(ctx context.Context, req *http.Request) error
+}
+
+// tokenInvalidator webhook 401 döndüğünde önbellekteki kimlik bilgisini düşürür
+type tokenInvalidator interface {
+	Invalidate()
+}
+
+// NewAuthenticator config'teki WEBHOOK_AUTH_MODE'a göre strateji seçer.
+// Mod boşsa WEBHOOK_AUTH_KEY varlığına göre header veya none kullanılır.
+func NewAuthenticator(cfg *config.Config) (Authenticator, error) {
+	mode := cfg.WebhookAuthMode
+	if mode == "" {
+		mode = AuthModeNone
+		if cfg.WebhookAuthKey != "" {
+			mode = AuthModeHeader
+		}
+	}
+
+	switch mode {
+	case AuthModeNone:
+		return noAuth{}, nil
+	case AuthModeHeader:
+		header := cfg.WebhookAuthHeader
+		if header == "" {
+			header = "x-ins-auth-key"
+		}
+		return &StaticHeaderAuth{Header: header, Value: cfg.WebhookAuthKey}, nil
+	case AuthModeBasic:
+		if cfg.WebhookBasicUser == "" {
+			return nil, errors.New("WEBHOOK_BASIC_USER is required for basic auth")
+		}
+		return &BasicAuth{Username: cfg.WebhookBasicUser, Password: cfg.WebhookBasicPassword}, nil
+	case AuthModeOAuth2:
+		if cfg.WebhookOAuthTokenURL == "" || cfg.WebhookOAuthClientID == "" {
+			return nil, errors.New("WEBHOOK_OAUTH_TOKEN_URL and WEBHOOK_OAUTH_CLIENT_ID are required for oauth2 auth")
+		}
+		return NewOAuth2ClientCredentials(cfg), nil
+	default:
+		return nil, fmt.Errorf("unsupported WEBHOOK_AUTH_MODE %q", mode)
+	}
+}
+
+type noAuth struct{}
+
+func (noAuth) Apply(context.Context, *http.Request) error { return nil }
+
+// StaticHeaderAuth sabit bir header ekler (varsayılan x-ins-auth-key)
+type StaticHeaderAuth struct {
+	Header string
+	Value  string
+}
+
+// Apply header'ı isteğe ekler
+func (a *StaticHeaderAuth) Apply(_ context.Context, req *http.Request) error {
+	if a.Value != "" {
+		req.Header.Set(a.Header, a.Value)
+	}
+	return nil
+}
+
+// BasicAuth HTTP basic authentication kullanır
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+// Apply basic auth bilgisini isteğe ekler
+func (a *BasicAuth) Apply(_ context.Context, req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// OAuth2ClientCredentials token endpoint'inden client credentials ile bearer token alır.
+// Token önbellekte tutulur ve ömrünün %80'i dolduğunda arka planda yenilenir.
+type OAuth2ClientCredentials struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	authInBody   bool
+	client       *http.Client
+
+	fetchMu    sync.Mutex
+	mu         sync.Mutex
+	token      string
+	expiry     time.Time
+	refreshAt  time.Time
+	refreshing bool
+}
+
+// oauthTokenTimeout token endpoint'ine yapılan isteğin zaman aşımı
+const oauthTokenTimeout = 10 * time.Second
+
+// NewOAuth2ClientCredentials config'ten yeni bir OAuth2 stratejisi oluşturur.
+// Token endpoint'i webhook'tan farklı bir host olabileceği için webhook'un CA,
+// pin ve client sertifikası ayarları kullanılmaz; sistem kök sertifikalarıyla
+// ayrı bir client oluşturulur.
+func NewOAuth2ClientCredentials(cfg *config.Config) *OAuth2ClientCredentials {
+	return &OAuth2ClientCredentials{
+		tokenURL:     cfg.WebhookOAuthTokenURL,
+		clientID:     cfg.WebhookOAuthClientID,
+		clientSecret: cfg.WebhookOAuthClientSecret,
+		scopes:       cfg.WebhookOAuthScopes,
+		authInBody:   cfg.WebhookOAuthAuthStyle == "body",
+		client:       &http.Client{Timeout: oauthTokenTimeout},
+	}
+}
+
+type tokenResp struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// Apply geçerli bir bearer token'ı isteğe ekler
+func (a *OAuth2ClientCredentials) Apply(ctx context.Context, req *http.Request) error {
+	tok, err := a.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	return nil
+}
+
+// Token önbellekteki token'ı döndürür, süresi dolmuşsa yenisini alır
+func (a *OAuth2ClientCredentials) Token(ctx context.Context) (string, error) {
+	if tok, ok := a.cached(); ok {
+		return tok, nil
+	}
+
+	a.fetchMu.Lock()
+	defer a.fetchMu.Unlock()
+	// başka bir goroutine biz beklerken token almış olabilir
+	a.mu.Lock()
+	if a.token != "" && time.Now().Before(a.expiry) {
+		tok := a.token
+		a.mu.Unlock()
+		return tok, nil
+	}
+	a.mu.Unlock()
+	return a.fetch(ctx)
+}
+
+// cached geçerli token varsa döndürür ve yenileme zamanı geldiyse arka planda yeniler
+func (a *OAuth2ClientCredentials) cached() (string, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := time.Now()
+	if a.token == "" || !now.Before(a.expiry) {
+		return "", false
+	}
+	if !now.Before(a.refreshAt) && !a.refreshing {
+		a.refreshing = true
+		go a.refreshInBackground()
+	}
+	return a.token, true
+}
+
+// refreshInBackground token süresi dolmadan yenisini alır
+func (a *OAuth2ClientCredentials) refreshInBackground() {
+	a.fetchMu.Lock()
+	defer a.fetchMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := a.fetch(ctx); err != nil {
+		log.Printf("oauth2 proactive token refresh failed: %v", err)
+	}
+	a.mu.Lock()
+	a.refreshing = false
+	a.mu.Unlock()
+}
+
+// Invalidate webhook token'ı reddettiğinde önbelleği temizler
+func (a *OAuth2ClientCredentials) Invalidate() {
+	a.mu.Lock()
+	a.token = ""
+	a.mu.Unlock()
+}
+
+// fetch token endpoint'ine client credentials isteği atar, fetchMu tutulurken çağrılmalıdır
+func (a *OAuth2ClientCredentials) fetch(ctx context.Context) (string, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+	if a.authInBody {
+		form.Set("client_id", a.clientID)
+		form.Set("client_secret", a.clientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if !a.authInBody {
+		req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d: %s", resp.StatusCode, readErrorBody(resp.Body))
+	}
+
+	var tr tokenResp
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return "", errors.New("token response has no access_token")
+	}
+	if tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer") {
+		return "", fmt.Errorf("unsupported token type %q", tr.TokenType)
+	}
+
+	lifetime := time.Duration(tr.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = 5 * time.Minute
+	}
+	now := time.Now()
+	a.mu.Lock()
+	a.token = tr.AccessToken
+	a.expiry = now.Add(lifetime)
+	a.refreshAt = now.Add(lifetime * 4 / 5)
+	a.mu.Unlock()
+	return tr.AccessToken, nil
+}
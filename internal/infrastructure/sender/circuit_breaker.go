@@ -0,0 +1,230 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+)
+
+var (
+	_ application.SenderPort              = (*CircuitBreakerSender)(nil)
+	_ application.CircuitBreakerInspector = (*CircuitBreakerSender)(nil)
+)
+
+// CircuitBreakerSender SenderPort'u circuit breaker ile sarar.
+// Açık durumdayken webhook'a hiç istek atmadan application.ErrCircuitOpen döner.
+type CircuitBreakerSender struct {
+	next         application.SenderPort
+	failureRatio float64
+	minRequests  int
+	cooldown     time.Duration
+	halfOpenMax  int
+	now          func() time.Time
+
+	mu        sync.Mutex
+	state     application.CircuitState
+	window    []bool
+	pos       int
+	count     int
+	failures  int
+	openedAt  time.Time
+	probes    int
+	successes int
+	// generation her durum değişikliğinde artar; önceki durumda başlamış
+	// isteklerin geç gelen sonuçları yeni durumu etkilemez
+	generation uint64
+}
+
+// NewCircuitBreakerSender config'e göre yeni bir circuit breaker oluşturur
+func NewCircuitBreakerSender(next application.SenderPort, cfg *config.Config) *CircuitBreakerSender {
+	ratio := cfg.CircuitFailureRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 0.5
+	}
+	minReq := cfg.CircuitMinRequests
+	if minReq <= 0 {
+		minReq = 5
+	}
+	size := cfg.CircuitWindowSize
+	if size < minReq {
+		size = minReq
+	}
+	cooldown := time.Duration(cfg.CircuitCooldownSeconds) * time.Second
+	if cooldown <= 0 {
+		cooldown = 60 * time.Second
+	}
+	probes := cfg.CircuitHalfOpenProbes
+	if probes <= 0 {
+		probes = 1
+	}
+	return &CircuitBreakerSender{
+		next:         next,
+		failureRatio: ratio,
+		minRequests:  minReq,
+		cooldown:     cooldown,
+		halfOpenMax:  probes,
+		now:          time.Now,
+		state:        application.CircuitClosed,
+		window:       make([]bool, size),
+	}
+}
+
+// Send breaker izin veriyorsa mesajı alttaki sender'a iletir ve sonucu kaydeder
+func (b *CircuitBreakerSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	gen, err := b.acquire()
+	if err != nil {
+		return application.SendResult{}, err
+	}
+	res, err := b.next.Send(ctx, m)
+	b.record(gen, classifyOutcome(ctx, err))
+	return res, err
+}
+
+// acquire isteğin geçip geçmeyeceğine karar verir ve isteğin başladığı durumun
+// generation'ını döner
+func (b *CircuitBreakerSender) acquire() (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case application.CircuitOpen:
+		if b.now().Before(b.openedAt.Add(b.cooldown)) {
+			return 0, application.ErrCircuitOpen
+		}
+		b.state = application.CircuitHalfOpen
+		b.generation++
+		b.probes = 0
+		b.successes = 0
+		log.Println("circuit breaker half-open, probing webhook")
+		fallthrough
+	case application.CircuitHalfOpen:
+		if b.probes >= b.halfOpenMax {
+			return 0, application.ErrCircuitOpen
+		}
+		b.probes++
+	}
+	return b.generation, nil
+}
+
+// record gönderim sonucunu pencereye ekler ve gerekirse durumu değiştirir.
+// Breaker istek başladıktan sonra durum değiştirdiyse sonuç yok sayılır; aksi
+// halde açılmadan önce başlamış istekler cooldown'u uzatır veya yarı açık
+// durumda deneme sonucu sayılırdı.
+func (b *CircuitBreakerSender) record(gen uint64, o outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if gen != b.generation {
+		return
+	}
+	failed := o == outcomeFailure
+	if b.state == application.CircuitHalfOpen {
+		switch o {
+		case outcomeFailure:
+			b.trip()
+			return
+		case outcomeNeutral:
+			// webhook'un düzeldiği kanıtlanmadı, deneme hakkı başka bir isteğe kalır
+			if b.probes > 0 {
+				b.probes--
+			}
+			return
+		}
+		b.successes++
+		if b.successes >= b.halfOpenMax {
+			b.reset()
+			log.Println("circuit breaker closed, webhook recovered")
+		}
+		return
+	}
+
+	if b.count == len(b.window) {
+		if b.window[b.pos] {
+			b.failures--
+		}
+	} else {
+		b.count++
+	}
+	b.window[b.pos] = failed
+	if failed {
+		b.failures++
+	}
+	b.pos = (b.pos + 1) % len(b.window)
+
+	if b.count >= b.minRequests && float64(b.failures)/float64(b.count) >= b.failureRatio {
+		b.trip()
+	}
+}
+
+// trip breaker'ı açık duruma geçirir
+func (b *CircuitBreakerSender) trip() {
+	b.state = application.CircuitOpen
+	b.generation++
+	b.openedAt = b.now()
+	log.Printf("circuit breaker opened, webhook calls paused for %v", b.cooldown)
+}
+
+// reset breaker'ı kapalı duruma alır ve pencereyi temizler
+func (b *CircuitBreakerSender) reset() {
+	b.state = application.CircuitClosed
+	b.generation++
+	b.openedAt = time.Time{}
+	b.pos, b.count, b.failures = 0, 0, 0
+	for i := range b.window {
+		b.window[i] = false
+	}
+}
+
+// Status breaker'ın anlık durumunu döndürür
+func (b *CircuitBreakerSender) Status() application.CircuitBreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st := application.CircuitBreakerStatus{
+		State:     b.state,
+		Requests:  b.count,
+		Failures:  b.failures,
+		Threshold: b.failureRatio,
+	}
+	if b.count > 0 {
+		st.FailureRatio = float64(b.failures) / float64(b.count)
+	}
+	if b.state != application.CircuitClosed {
+		opened := b.openedAt
+		retry := opened.Add(b.cooldown)
+		st.OpenedAt = &opened
+		st.RetryAt = &retry
+	}
+	return st
+}
+
+// outcome bir gönderimin breaker açısından sonucu
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	// outcomeNeutral webhook'un sağlığını göstermeyen sonuç: çağıran isteği iptal
+	// etti veya webhook mesajı kalıcı olarak reddetti. Kapalı durumda arıza
+	// sayılmaz, yarı açık durumda başarı da sayılmaz.
+	outcomeNeutral
+)
+
+// classifyOutcome hatanın webhook arızası sayılıp sayılmayacağını belirler.
+// Çağıranın context'i bittiyse (kapanış, batch timeout) hata webhook'tan
+// kaynaklanmamış olabilir.
+func classifyOutcome(ctx context.Context, err error) outcome {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case errors.Is(err, context.Canceled), ctx.Err() != nil, application.IsPermanent(err):
+		return outcomeNeutral
+	}
+	return outcomeFailure
+}
@@ -0,0 +1,79 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"insider-messaging/internal/application"
+)
+
+// maxErrorBody hata kaydına eklenecek cevap gövdesinin maksimum uzunluğu
+const maxErrorBody = 200
+
+// classifyTransportError HTTP isteği hiç cevap alamadığında hatayı sınıflandırır
+func classifyTransportError(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	var ne net.Error
+	if errors.Is(err, context.DeadlineExceeded) || ctx.Err() == context.DeadlineExceeded ||
+		(errors.As(err, &ne) && ne.Timeout()) {
+		return &application.TimeoutError{Err: err}
+	}
+	return &application.RetryableError{Err: fmt.Errorf("failed to send request: %w", err)}
+}
+
+// classifyStatus 2xx dışı cevapları kalıcı veya tekrar denenebilir hataya çevirir
+func classifyStatus(resp *http.Response) error {
+	body := readErrorBody(resp.Body)
+	cause := fmt.Errorf("bad status: %d", resp.StatusCode)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusRequestTimeout,
+		resp.StatusCode == http.StatusTooEarly,
+		resp.StatusCode >= 500:
+		return &application.RetryableError{
+			StatusCode: resp.StatusCode,
+			Body:       body,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+			Err:        cause,
+		}
+	case resp.StatusCode == http.StatusUnauthorized, resp.StatusCode == http.StatusForbidden:
+		// kimlik doğrulama hatası mesajın değil yapılandırmanın sorunudur, mesajı yakmayalım
+		return &application.RetryableError{StatusCode: resp.StatusCode, Body: body, Err: cause}
+	default:
+		return &application.PermanentError{StatusCode: resp.StatusCode, Body: body, Err: cause}
+	}
+}
+
+// readErrorBody cevap gövdesinin başını okur ve kısaltır
+func readErrorBody(r io.Reader) string {
+	b, _ := io.ReadAll(io.LimitReader(r, maxErrorBody+1))
+	body := strings.TrimSpace(string(b))
+	if len(body) > maxErrorBody {
+		body = body[:maxErrorBody] + "..."
+	}
+	return body
+}
+
+// parseRetryAfter Retry-After header'ını saniye veya HTTP tarihi olarak çözer
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
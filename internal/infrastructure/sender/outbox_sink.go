@@ -0,0 +1,58 @@
+package sender
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+)
+
+var _ application.OutboxSink = (*WebhookOutboxSink)(nil)
+
+// WebhookOutboxSink outbox olaylarını bir HTTP adresine JSON olarak POST eder
+type WebhookOutboxSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookOutboxSink OUTBOX_WEBHOOK_URL'e yayın yapan bir sink oluşturur
+func NewWebhookOutboxSink(cfg *config.Config) *WebhookOutboxSink {
+	return &WebhookOutboxSink{url: cfg.OutboxWebhookURL, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Name sink adı
+func (s *WebhookOutboxSink) Name() string { return config.OutboxSinkWebhook }
+
+// Publish olayı POST eder, 2xx dışındaki cevaplar hata sayılır ve olay tekrar
+// gönderilir. Alıcılar tekrarları X-Event-ID header'ı ile ayıklayabilir.
+func (s *WebhookOutboxSink) Publish(ctx context.Context, e *entity.OutboxEvent) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatUint(uint64(e.ID), 10))
+	req.Header.Set("X-Event-Type", string(e.Type))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("bad status: %d", resp.StatusCode)
+	}
+	return nil
+}
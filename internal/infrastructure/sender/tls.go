@@ -0,0 +1,217 @@
+package sender
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"insider-messaging/internal/config"
+)
+
+// tlsReloader webhook istemcisinin sertifika, CA ve pin ayarlarını yönetir.
+// Dosyalar değiştiğinde uygulamayı yeniden başlatmadan yeni materyali yükler.
+type tlsReloader struct {
+	certFile   string
+	keyFile    string
+	caFile     string
+	pins       map[string]struct{}
+	checkEvery time.Duration
+	onReload   func()
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	roots     *x509.CertPool
+	modTimes  map[string]time.Time
+	lastCheck time.Time
+}
+
+// newTLSConfig config'teki TLS ayarlarından bir tls.Config oluşturur.
+// Hiçbir TLS ayarı yoksa nil döner ve varsayılan transport kullanılır.
+func newTLSConfig(cfg *config.Config, onReload func()) (*tls.Config, error) {
+	if cfg.WebhookTLSCertFile == "" && cfg.WebhookTLSKeyFile == "" && cfg.WebhookTLSCAFile == "" &&
+		len(cfg.WebhookTLSPins) == 0 && cfg.WebhookTLSMinVersion == "" {
+		return nil, nil
+	}
+	if (cfg.WebhookTLSCertFile == "") != (cfg.WebhookTLSKeyFile == "") {
+		return nil, errors.New("WEBHOOK_TLS_CERT_FILE and WEBHOOK_TLS_KEY_FILE must be set together")
+	}
+
+	minVersion, err := parseTLSVersion(cfg.WebhookTLSMinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &tlsReloader{
+		certFile:   cfg.WebhookTLSCertFile,
+		keyFile:    cfg.WebhookTLSKeyFile,
+		caFile:     cfg.WebhookTLSCAFile,
+		pins:       make(map[string]struct{}, len(cfg.WebhookTLSPins)),
+		checkEvery: time.Duration(cfg.WebhookTLSReloadSeconds) * time.Second,
+		onReload:   onReload,
+		modTimes:   map[string]time.Time{},
+	}
+	for _, p := range cfg.WebhookTLSPins {
+		r.pins[strings.TrimPrefix(p, "sha256/")] = struct{}{}
+	}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	tc := &tls.Config{MinVersion: minVersion}
+	if r.certFile != "" {
+		tc.GetClientCertificate = r.clientCertificate
+	}
+	if r.caFile != "" {
+		// CA havuzu değişebildiği için zincir doğrulamasını VerifyConnection içinde yapıyoruz
+		tc.InsecureSkipVerify = true
+	}
+	if r.caFile != "" || len(r.pins) > 0 {
+		tc.VerifyConnection = r.verifyConnection
+	}
+	return tc, nil
+}
+
+// load sertifika ve CA dosyalarını okur
+func (r *tlsReloader) load() error {
+	var cert *tls.Certificate
+	if r.certFile != "" {
+		c, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load webhook client certificate: %w", err)
+		}
+		cert = &c
+	}
+
+	var roots *x509.CertPool
+	if r.caFile != "" {
+		pem, err := os.ReadFile(r.caFile)
+		if err != nil {
+			return fmt.Errorf("failed to read webhook CA bundle: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return errors.New("webhook CA bundle contains no certificates")
+		}
+	}
+
+	modTimes := map[string]time.Time{}
+	for _, f := range []string{r.certFile, r.keyFile, r.caFile} {
+		if f == "" {
+			continue
+		}
+		if st, err := os.Stat(f); err == nil {
+			modTimes[f] = st.ModTime()
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.roots = roots
+	r.modTimes = modTimes
+	r.lastCheck = time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// maybeReload dosyaların değişip değişmediğini en fazla checkEvery aralıkla kontrol eder.
+// Yeni materyal hatalıysa eskisiyle devam edilir.
+func (r *tlsReloader) maybeReload() {
+	r.mu.RLock()
+	due := time.Since(r.lastCheck) >= r.checkEvery
+	modTimes := r.modTimes
+	r.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	changed := false
+	for f, old := range modTimes {
+		if st, err := os.Stat(f); err == nil && !st.ModTime().Equal(old) {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		r.mu.Lock()
+		r.lastCheck = time.Now()
+		r.mu.Unlock()
+		return
+	}
+
+	if err := r.load(); err != nil {
+		log.Printf("webhook TLS reload failed, keeping previous certificates: %v", err)
+		r.mu.Lock()
+		r.lastCheck = time.Now()
+		r.mu.Unlock()
+		return
+	}
+	log.Println("webhook TLS certificates reloaded")
+	if r.onReload != nil {
+		r.onReload()
+	}
+}
+
+// clientCertificate handshake sırasında güncel istemci sertifikasını döndürür
+func (r *tlsReloader) clientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	r.maybeReload()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// verifyConnection sunucu sertifikasını güncel CA havuzuna ve SPKI pinlerine göre doğrular
+func (r *tlsReloader) verifyConnection(cs tls.ConnectionState) error {
+	r.maybeReload()
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("webhook server presented no certificate")
+	}
+
+	r.mu.RLock()
+	roots := r.roots
+	r.mu.RUnlock()
+
+	if roots != nil {
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			DNSName:       cs.ServerName,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, c := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(c)
+		}
+		if _, err := cs.PeerCertificates[0].Verify(opts); err != nil {
+			return err
+		}
+	}
+
+	if len(r.pins) == 0 {
+		return nil
+	}
+	for _, c := range cs.PeerCertificates {
+		sum := sha256.Sum256(c.RawSubjectPublicKeyInfo)
+		if _, ok := r.pins[base64.StdEncoding.EncodeToString(sum[:])]; ok {
+			return nil
+		}
+	}
+	return errors.New("webhook server certificate does not match any pinned public key")
+}
+
+// parseTLSVersion "1.2" veya "1.3" gibi değerleri tls sabitlerine çevirir
+func parseTLSVersion(v string) (uint16, error) {
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported WEBHOOK_TLS_MIN_VERSION %q (use 1.2 or 1.3)", v)
+	}
+}
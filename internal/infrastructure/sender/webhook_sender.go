@@ -6,34 +6,55 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
+	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
 	"insider-messaging/internal/domain/entity"
 )
 
+var _ application.SenderPort = (*WebhookSender)(nil)
+
 type WebhookSender struct {
 	cfg    *config.Config
 	client *http.Client
+	auth   Authenticator
 }
 
-// NewWebhookSender yeni bir webhook sender oluşturur
-func NewWebhookSender(cfg *config.Config) *WebhookSender {
+// NewWebhookSender yeni bir webhook sender oluşturur, TLS ayarları geçersizse hata döner
+func NewWebhookSender(cfg *config.Config) (*WebhookSender, error) {
 	timeout := 10 * time.Second
 	if cfg.WebhookTimeoutSeconds > 0 {
 		timeout = time.Duration(cfg.WebhookTimeoutSeconds) * time.Second
 	}
-	return &WebhookSender{
-		cfg:    cfg,
-		client: &http.Client{Timeout: timeout},
+	client := &http.Client{Timeout: timeout}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// sertifikalar yenilendiğinde açık bağlantılar eski sertifikayla kalmasın
+	tlsCfg, err := newTLSConfig(cfg, transport.CloseIdleConnections)
+	if err != nil {
+		return nil, err
+	}
+	if tlsCfg != nil {
+		transport.TLSClientConfig = tlsCfg
+		client.Transport = transport
 	}
+
+	auth, err := NewAuthenticator(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WebhookSender{cfg: cfg, client: client, auth: auth}, nil
 }
 
 type webhookReq struct {
-	To      string `json:"to"`
-	Content string `json:"content"`
+	To       string            `json:"to"`
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 type webhookResp struct {
@@ -42,27 +63,30 @@ type webhookResp struct {
 }
 
 // Send mesajı webhook URL'ine gönderir ve dönen messageId'yi alır
-func (s *WebhookSender) Send(ctx context.Context, m *entity.Message) (string, error) {
-	payload := webhookReq{To: m.To, Content: m.Content}
+func (s *WebhookSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	payload := webhookReq{To: m.To, Content: m.Content, Metadata: s.forwardedMetadata(m)}
 	b, err := json.Marshal(payload)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal payload: %w", err)
+		return application.SendResult{}, fmt.Errorf("failed to marshal payload: %w", err)
 	}
 	req, err := http.NewRequestWithContext(ctx, "POST", s.cfg.WebhookURL, bytes.NewReader(b))
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return application.SendResult{}, fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if s.cfg.WebhookAuthKey != "" {
-		req.Header.Set("x-ins-auth-key", s.cfg.WebhookAuthKey)
+	if err := s.auth.Apply(ctx, req); err != nil {
+		return application.SendResult{}, &application.RetryableError{Err: fmt.Errorf("webhook auth: %w", err)}
 	}
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to send request: %w", err)
+		return application.SendResult{}, classifyTransportError(ctx, err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return "", fmt.Errorf("bad status: %d", resp.StatusCode)
+		if inv, ok := s.auth.(tokenInvalidator); ok && resp.StatusCode == http.StatusUnauthorized {
+			inv.Invalidate()
+		}
+		return application.SendResult{}, classifyStatus(resp)
 	}
 
 	bodyBytes := make([]byte, 0, 512)
@@ -77,24 +101,49 @@ func (s *WebhookSender) Send(ctx context.Context, m *entity.Message) (string, er
 		}
 	}
 
+	// 2xx cevap çözülemese de webhook mesajı kabul etmiştir; tekrar denemek
+	// mükerrer gönderim olacağı için messageId yokmuş gibi davranılır
 	var wr webhookResp
-	if err := json.Unmarshal(bodyBytes, &wr); err != nil {
-		responseStr := string(bodyBytes)
-		if len(responseStr) > 200 {
-			responseStr = responseStr[:200] + "..."
+	if err := json.Unmarshal(bodyBytes, &wr); err != nil || wr.MessageId == "" || wr.MessageId == "{{uuid}}" {
+		return s.missingMessageID(resp.StatusCode, bodyBytes)
+	}
+
+	return application.SendResult{MessageID: wr.MessageId, MessageIDSource: entity.MessageIDProvider}, nil
+}
+
+// forwardedMetadata mesaj metadata'sından sadece yapılandırılmış anahtarları seçer
+func (s *WebhookSender) forwardedMetadata(m *entity.Message) map[string]string {
+	var out map[string]string
+	for _, k := range s.cfg.WebhookMetadataKeys {
+		if v, ok := m.Metadata[k]; ok {
+			if out == nil {
+				out = make(map[string]string, len(s.cfg.WebhookMetadataKeys))
+			}
+			out[k] = v
 		}
-		return "", fmt.Errorf("failed to decode response (status %d): %v. Response body: %s", resp.StatusCode, err, responseStr)
 	}
+	return out
+}
 
-	if wr.MessageId == "" || wr.MessageId == "{{uuid}}" {
+// missingMessageID webhook cevabında messageId olmadığında yapılandırılmış politikayı uygular
+func (s *WebhookSender) missingMessageID(status int, body []byte) (application.SendResult, error) {
+	switch s.cfg.WebhookMsgIDPolicy {
+	case config.MsgIDPolicyFail:
+		// webhook mesajı kabul etti, tekrar denemek mükerrer gönderim olur
+		return application.SendResult{}, &application.PermanentError{
+			StatusCode: status,
+			Body:       readErrorBody(bytes.NewReader(body)),
+			Err:        errors.New("webhook response has no messageId"),
+		}
+	case config.MsgIDPolicyNone:
+		return application.SendResult{MessageIDSource: entity.MessageIDNone}, nil
+	default:
 		uuid, err := generateUUID()
 		if err != nil {
-			return "", fmt.Errorf("failed to generate UUID: %w", err)
+			return application.SendResult{}, fmt.Errorf("failed to generate UUID: %w", err)
 		}
-		return uuid, nil
+		return application.SendResult{MessageID: uuid, MessageIDSource: entity.MessageIDSynthetic}, nil
 	}
-
-	return wr.MessageId, nil
 }
 
 // generateUUID rastgele bir UUID v4 oluşturur
@@ -0,0 +1,368 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/phone"
+	"insider-messaging/internal/domain/repository"
+)
+
+// maxCampaignRecipients tek istekte oluşturulabilecek en fazla mesaj sayısı
+const maxCampaignRecipients = 10000
+
+type CampaignRecipient struct {
+	To        string            `json:"to" example:"+905551111111"`
+	Locale    string            `json:"locale,omitempty" example:"tr"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// CreateCampaignRequest content veya templateId alanlarından biri verilmelidir
+type CreateCampaignRequest struct {
+	Name        string              `json:"name" example:"spring-sale" binding:"required"`
+	CreatedBy   string              `json:"createdBy,omitempty" example:"marketing-team"`
+	ScheduledAt *time.Time          `json:"scheduledAt,omitempty" example:"2024-01-01T09:00:00Z"`
+	TemplateID  uint                `json:"templateId,omitempty" example:"1"`
+	Locale      string              `json:"locale,omitempty" example:"tr"`
+	Content     string              `json:"content,omitempty" example:"Spring sale starts today"`
+	Priority    string              `json:"priority,omitempty" example:"low" enums:"low,normal,high"`
+	Category    string              `json:"category,omitempty" example:"marketing" enums:"general,transactional,marketing"`
+	Tags        []string            `json:"tags,omitempty" example:"spring"`
+	TrackLinks  bool                `json:"trackLinks,omitempty" example:"true"`
+	Recipients  []CampaignRecipient `json:"recipients" binding:"required"`
+}
+
+type CampaignResponse struct {
+	*entity.Campaign
+	Stats entity.CampaignStats `json:"stats"`
+}
+
+// CreateCampaign kampanyayı ve alıcı başına mesajlarını oluşturur
+// @Summary      Create a campaign
+// @Description  Create a campaign and one pending message per recipient. Content is either given directly or rendered per recipient from a template. Messages are not sent before scheduledAt.
+// @Tags         campaigns
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key  header    string                 true  "API Key for authentication"
+// @Param        campaign   body      CreateCampaignRequest  true  "Campaign data"
+// @Success      201        {object}  CampaignResponse
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /campaigns [post]
+func (h *Handler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	if !h.campaignsEnabled(w) {
+		return
+	}
+	var in CreateCampaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request payload",
+			Message: "Request body must be valid JSON",
+			Code:    "INVALID_PAYLOAD",
+		})
+		return
+	}
+	if len(in.Recipients) == 0 || len(in.Recipients) > maxCampaignRecipients {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid recipients",
+			Message: fmt.Sprintf("A campaign needs between 1 and %d recipients", maxCampaignRecipients),
+			Code:    "INVALID_RECIPIENTS",
+		})
+		return
+	}
+	if (in.Content == "") == (in.TemplateID == 0) {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid content",
+			Message: "Send exactly one of content or templateId",
+			Code:    "CONTENT_AND_TEMPLATE",
+		})
+		return
+	}
+	if in.TrackLinks && !h.linkTrackingEnabled(w) {
+		return
+	}
+	priority, err := entity.ParsePriority(in.Priority)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid priority", Message: err.Error(), Code: "INVALID_PRIORITY"})
+		return
+	}
+	category, err := entity.ParseCategory(in.Category)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Invalid category", Message: err.Error(), Code: "INVALID_CATEGORY"})
+		return
+	}
+
+	var tpl *entity.Template
+	var templateID *uint
+	if in.TemplateID != 0 {
+		if !h.templatesEnabled(w) {
+			return
+		}
+		if tpl, err = h.templates.Get(in.TemplateID); err != nil {
+			h.templateError(w, err)
+			return
+		}
+		templateID = &in.TemplateID
+	}
+
+	campaign, err := entity.NewCampaign(in.Name, in.CreatedBy, in.ScheduledAt, templateID)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "Validation failed", Message: err.Error(), Code: "VALIDATION_ERROR"})
+		return
+	}
+
+	msgs := make([]*entity.Message, 0, len(in.Recipients))
+	for i, rc := range in.Recipients {
+		msg, err := h.campaignMessage(rc, in, tpl)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid recipient",
+				Message: fmt.Sprintf("recipient %d: %v", i, err),
+				Code:    "INVALID_RECIPIENT",
+			})
+			return
+		}
+		msg.Priority = priority
+		msg.Category = category
+		msg.TemplateID = templateID
+		if v := h.checkPolicy(msg); v != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{
+				Error:   "Content rejected by policy",
+				Message: fmt.Sprintf("recipient %d: %v", i, v),
+				Code:    "CONTENT_REJECTED",
+			})
+			return
+		}
+		msgs = append(msgs, msg)
+	}
+
+	if err := h.campaigns.Create(campaign, msgs); err != nil {
+		logError(w, "Failed to create campaign in database", http.StatusInternalServerError)
+		return
+	}
+	h.notify()
+	writeJSON(w, http.StatusCreated, CampaignResponse{
+		Campaign: campaign,
+		Stats:    entity.CampaignStats{Total: int64(len(msgs)), Pending: int64(len(msgs))},
+	})
+}
+
+// campaignMessage bir alıcı için mesajı doğrular ve içeriğini hazırlar
+func (h *Handler) campaignMessage(rc CampaignRecipient, in CreateCampaignRequest, tpl *entity.Template) (*entity.Message, error) {
+	number, err := phone.Parse(rc.To, h.cfg.PhoneDefaultRegion)
+	if err != nil {
+		return nil, err
+	}
+	content := in.Content
+	if tpl != nil {
+		locale := rc.Locale
+		if locale == "" {
+			locale = in.Locale
+		}
+		rendered, err := tpl.Render(locale, rc.Variables)
+		if err != nil {
+			return nil, err
+		}
+		content = rendered
+	}
+	var links []entity.Link
+	if in.TrackLinks {
+		if content, links, err = h.shortener.Shorten(content); err != nil {
+			return nil, err
+		}
+	}
+	msg, err := entity.NewMessage(number.E164, content, h.cfg.MsgCharLimit)
+	if err != nil {
+		return nil, err
+	}
+	msg.Links = links
+	msg.Country = number.Region
+	if err := msg.SetMetadata(rc.Metadata, h.cfg.MetadataMaxBytes); err != nil {
+		return nil, err
+	}
+	if err := msg.SetTags(in.Tags, h.cfg.MaxTags); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// ListCampaigns kampanyaları listeler
+// @Summary      List campaigns
+// @Tags         campaigns
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Success      200        {array}   entity.Campaign
+// @Failure      401        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /campaigns [get]
+func (h *Handler) ListCampaigns(w http.ResponseWriter, r *http.Request) {
+	if !h.campaignsEnabled(w) {
+		return
+	}
+	list, err := h.campaigns.List()
+	if err != nil {
+		logError(w, "Failed to list campaigns", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetCampaign kampanyayı mesaj sayılarıyla birlikte döndürür
+// @Summary      Get campaign with aggregate progress
+// @Description  Returns the campaign and counts of its messages per status. There is no delivered count because the provider sends no delivery receipts; sent means the webhook accepted the message.
+// @Tags         campaigns
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Campaign ID"
+// @Success      200        {object}  CampaignResponse
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /campaigns/{id} [get]
+func (h *Handler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	if !h.campaignsEnabled(w) {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	h.writeCampaign(w, id)
+}
+
+// PauseCampaign kampanyanın bekleyen mesajlarının gönderimini durdurur
+// @Summary      Pause campaign
+// @Tags         campaigns
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Campaign ID"
+// @Success      200        {object}  CampaignResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      409        {object}  ErrorResponse
+// @Router       /campaigns/{id}/pause [post]
+func (h *Handler) PauseCampaign(w http.ResponseWriter, r *http.Request) {
+	h.transitionCampaign(w, r, entity.CampaignPaused)
+}
+
+// ResumeCampaign duraklatılmış kampanyayı tekrar kuyruğa alır
+// @Summary      Resume campaign
+// @Tags         campaigns
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Campaign ID"
+// @Success      200        {object}  CampaignResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      409        {object}  ErrorResponse
+// @Router       /campaigns/{id}/resume [post]
+func (h *Handler) ResumeCampaign(w http.ResponseWriter, r *http.Request) {
+	h.transitionCampaign(w, r, entity.CampaignActive)
+}
+
+// CancelCampaign kampanyayı ve bekleyen mesajlarını iptal eder
+// @Summary      Cancel campaign
+// @Description  Cancels the campaign; its pending messages are marked cancelled and never sent
+// @Tags         campaigns
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Campaign ID"
+// @Success      200        {object}  CampaignResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      409        {object}  ErrorResponse
+// @Router       /campaigns/{id}/cancel [post]
+func (h *Handler) CancelCampaign(w http.ResponseWriter, r *http.Request) {
+	h.transitionCampaign(w, r, entity.CampaignCancelled)
+}
+
+// transitionCampaign kampanya durumunu değiştirip güncel halini döner. Durum
+// okunduktan sonra başka bir istekle değiştiyse 409 döner.
+func (h *Handler) transitionCampaign(w http.ResponseWriter, r *http.Request, to entity.CampaignStatus) {
+	if !h.campaignsEnabled(w) {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	c, err := h.campaigns.Get(id)
+	if err != nil {
+		h.campaignError(w, err)
+		return
+	}
+	from := c.Status
+	if err := c.Transition(to); err != nil {
+		writeJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "Invalid campaign status change",
+			Message: err.Error(),
+			Code:    "INVALID_TRANSITION",
+		})
+		return
+	}
+	if from == to {
+		h.writeCampaign(w, id)
+		return
+	}
+	if err := h.campaigns.SetStatus(id, from, to); err != nil {
+		h.campaignError(w, err)
+		return
+	}
+	if to == entity.CampaignActive {
+		h.notify()
+	}
+	h.writeCampaign(w, id)
+}
+
+// writeCampaign kampanyayı ve sayaçlarını yazar
+func (h *Handler) writeCampaign(w http.ResponseWriter, id uint) {
+	c, err := h.campaigns.Get(id)
+	if err != nil {
+		h.campaignError(w, err)
+		return
+	}
+	stats, err := h.campaigns.Stats(id)
+	if err != nil {
+		logError(w, "Failed to read campaign stats", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, CampaignResponse{Campaign: c, Stats: stats})
+}
+
+// campaignsEnabled kampanya repository'si bağlı değilse 404 döner
+func (h *Handler) campaignsEnabled(w http.ResponseWriter) bool {
+	if h.campaigns != nil {
+		return true
+	}
+	writeJSON(w, http.StatusNotFound, ErrorResponse{
+		Error:   "Campaigns are disabled",
+		Message: "No campaign repository is configured",
+		Code:    "CAMPAIGNS_DISABLED",
+	})
+	return false
+}
+
+// campaignError repository hatasını HTTP cevabına çevirir
+func (h *Handler) campaignError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "Campaign not found",
+			Message: err.Error(),
+			Code:    "CAMPAIGN_NOT_FOUND",
+		})
+		return
+	}
+	if errors.Is(err, repository.ErrStatusChanged) {
+		writeJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "Campaign status changed",
+			Message: "campaign status was changed by another request, reload and retry",
+			Code:    "STATUS_CHANGED",
+		})
+		return
+	}
+	logError(w, "Campaign repository error", http.StatusInternalServerError)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+)
+
+// checkPolicy mesajı içerik kurallarına göre kontrol eder. Karantina kuralında
+// mesaj karantinaya alınmış olarak işaretlenir ve nil döner; reddedilmesi
+// gerekiyorsa ihlal döner.
+func (h *Handler) checkPolicy(msg *entity.Message) *application.PolicyViolation {
+	if h.policy == nil {
+		return nil
+	}
+	v := h.policy.Check(msg)
+	if v == nil {
+		return nil
+	}
+	if v.Action == entity.PolicyQuarantine {
+		msg.Status = entity.StatusQuarantined
+		msg.LastError = v.Error()
+		return nil
+	}
+	return v
+}
+
+// GetContentPolicy geçerli içerik kurallarını döndürür
+// @Summary      Get content policy
+// @Description  Returns the content policy rules applied on message creation and again before sending
+// @Tags         admin
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Success      200        {object}  entity.ContentRules
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /admin/content-policy [get]
+func (h *Handler) GetContentPolicy(w http.ResponseWriter, r *http.Request) {
+	if !h.policyEnabled(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.policy.Rules())
+}
+
+// UpdateContentPolicy içerik kurallarını değiştirir, tüm instance'larda yeniden yüklenir
+// @Summary      Replace content policy
+// @Description  Validates and stores the rules. This instance applies them immediately, others on their next reload.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key  header    string               true  "API Key for authentication"
+// @Param        rules      body      entity.ContentRules  true  "Content rules"
+// @Success      200        {object}  entity.ContentRules
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /admin/content-policy [put]
+func (h *Handler) UpdateContentPolicy(w http.ResponseWriter, r *http.Request) {
+	if !h.policyEnabled(w) {
+		return
+	}
+	var in entity.ContentRules
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request payload",
+			Message: "Request body must be valid JSON",
+			Code:    "INVALID_PAYLOAD",
+		})
+		return
+	}
+	rules, err := h.policy.Update(in)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidContentRules) {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "Validation failed",
+				Message: err.Error(),
+				Code:    "INVALID_RULES",
+			})
+			return
+		}
+		logError(w, "Failed to save content policy", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, rules)
+}
+
+// policyEnabled içerik politikası bağlı değilse 404 döner
+func (h *Handler) policyEnabled(w http.ResponseWriter) bool {
+	if h.policy != nil {
+		return true
+	}
+	writeJSON(w, http.StatusNotFound, ErrorResponse{
+		Error:   "Content policy is disabled",
+		Message: "No content policy is configured",
+		Code:    "POLICY_DISABLED",
+	})
+	return false
+}
@@ -4,15 +4,16 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"regexp"
+	"strings"
 
 	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
 	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/phone"
 	"insider-messaging/internal/domain/repository"
-)
 
-var phoneRegex = regexp.MustCompile(`^\+[1-9]\d{1,14}$`)
+	"github.com/gorilla/mux"
+)
 
 // logError hatayı loglar ve HTTP response döner
 func logError(w http.ResponseWriter, message string, statusCode int) {
@@ -30,20 +31,125 @@ type ErrorResponse struct {
 	Code    string `json:"code,omitempty" example:"INVALID_ACTION"`
 }
 
+// CreateMessageRequest content veya templateId alanlarından biri verilmelidir
 type CreateMessageRequest struct {
-	To      string `json:"to" example:"+905551111111" binding:"required"`
-	Content string `json:"content" example:"Hello, this is a test message" binding:"required"`
+	To         string            `json:"to" example:"+905551111111" binding:"required"`
+	Content    string            `json:"content,omitempty" example:"Hello, this is a test message"`
+	TemplateID uint              `json:"templateId,omitempty" example:"1"`
+	Locale     string            `json:"locale,omitempty" example:"tr"`
+	Variables  map[string]string `json:"variables,omitempty"`
+	Priority   string            `json:"priority,omitempty" example:"normal" enums:"low,normal,high"`
+	Category   string            `json:"category,omitempty" example:"general" enums:"general,transactional,marketing"`
+	Metadata   map[string]string `json:"metadata,omitempty"`
+	Tags       []string          `json:"tags,omitempty" example:"vip"`
+	TrackLinks bool              `json:"trackLinks,omitempty" example:"false"`
+}
+
+type RecipientCountersResponse struct {
+	To       string                         `json:"to" example:"+905551111111"`
+	Counters []application.RecipientCounter `json:"counters"`
 }
 
 type Handler struct {
-	sched application.SchedulerController
-	repo  repository.MessageRepository
-	cfg   *config.Config
+	sched     application.SchedulerController
+	repo      repository.MessageRepository
+	cfg       *config.Config
+	breaker   application.CircuitBreakerInspector
+	limiter   application.RecipientLimiter
+	templates repository.TemplateRepository
+	campaigns repository.CampaignRepository
+	policy    application.ContentPolicyManager
+	shortener *application.LinkShortener
+	links     repository.LinkRepository
+	dispatch  application.DispatchSettingsManager
+	audit     repository.SchedulerStateRepository
+	notifier  application.MessageNotifier
+	health    *application.HealthChecker
+}
+
+// HandlerOption handler'a opsiyonel bağımlılık ekler
+type HandlerOption func(*Handler)
+
+// applyLabels metadata ve etiketleri doğrulayıp mesaja ekler, hata olursa cevabı yazar
+func (h *Handler) applyLabels(w http.ResponseWriter, msg *entity.Message, md map[string]string, tags []string) bool {
+	err := msg.SetMetadata(md, h.cfg.MetadataMaxBytes)
+	if err == nil {
+		err = msg.SetTags(tags, h.cfg.MaxTags)
+	}
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Invalid metadata or tags",
+			Message: err.Error(),
+			Code:    "INVALID_LABELS",
+		})
+		return false
+	}
+	return true
+}
+
+// WithCircuitBreaker webhook circuit breaker durumunu API'ye açar
+func WithCircuitBreaker(b application.CircuitBreakerInspector) HandlerOption {
+	return func(h *Handler) { h.breaker = b }
+}
+
+// WithRecipientLimiter numara başına gönderim sayaçlarını API'ye açar
+func WithRecipientLimiter(l application.RecipientLimiter) HandlerOption {
+	return func(h *Handler) { h.limiter = l }
+}
+
+// WithTemplates şablon API'sini ve şablondan mesaj oluşturmayı açar
+func WithTemplates(t repository.TemplateRepository) HandlerOption {
+	return func(h *Handler) { h.templates = t }
+}
+
+// WithCampaigns kampanya API'sini açar
+func WithCampaigns(c repository.CampaignRepository) HandlerOption {
+	return func(h *Handler) { h.campaigns = c }
+}
+
+// WithContentPolicy mesaj oluştururken içerik kurallarını uygular ve kural yönetim API'sini açar
+func WithContentPolicy(p application.ContentPolicyManager) HandlerOption {
+	return func(h *Handler) { h.policy = p }
+}
+
+// WithLinkTracking mesajlardaki linklerin kısa takipli linklere çevrilmesini,
+// yönlendirme endpoint'ini ve tıklama istatistiklerini açar
+func WithLinkTracking(s *application.LinkShortener, links repository.LinkRepository) HandlerOption {
+	return func(h *Handler) { h.shortener, h.links = s, links }
+}
+
+// WithDispatchSettings gönderim aralığı, batch boyutu ve eşzamanlılığın
+// API üzerinden değiştirilmesini açar
+func WithDispatchSettings(d application.DispatchSettingsManager) HandlerOption {
+	return func(h *Handler) { h.dispatch = d }
+}
+
+// WithSchedulerAudit scheduler başlatma/durdurma denetim kayıtlarının listelenmesini açar
+func WithSchedulerAudit(s repository.SchedulerStateRepository) HandlerOption {
+	return func(h *Handler) { h.audit = s }
+}
+
+// WithNotifier yeni mesajlar kaydedildiğinde dispatcher'ı hemen uyandırır
+func WithNotifier(n application.MessageNotifier) HandlerOption {
+	return func(h *Handler) { h.notifier = n }
+}
+
+// notify varsa dispatcher'a yeni mesaj olduğunu bildirir
+func (h *Handler) notify() {
+	if h.notifier != nil {
+		h.notifier.Notify()
+	}
 }
 
 // NewHandler yeni bir handler oluşturur
-func NewHandler(s application.SchedulerController, r repository.MessageRepository, cfg *config.Config) *Handler {
-	return &Handler{sched: s, repo: r, cfg: cfg}
+func NewHandler(s application.SchedulerController, r repository.MessageRepository, cfg *config.Config, opts ...HandlerOption) *Handler {
+	h := &Handler{sched: s, repo: r, cfg: cfg}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 // StartStop scheduler'ı başlatır veya durdurur
@@ -52,11 +158,13 @@ func NewHandler(s application.SchedulerController, r repository.MessageRepositor
 // @Tags         scheduler
 // @Accept       json
 // @Produce      json
-// @Param        X-API-Key  header    string  true  "API Key for authentication"
-// @Param        action     query     string  true  "Action to perform"  Enums(start, stop)
+// @Param        X-API-Key  header    string  true   "API Key for authentication"
+// @Param        X-Actor    header    string  false  "Who performs the action, shown in scheduler status"
+// @Param        action     query     string  true   "Action to perform"  Enums(start, stop)
 // @Success      200        {object}  StatusResponse
 // @Failure      400        {object}  ErrorResponse
 // @Failure      401        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
 // @Router       /auto [post]
 // @Router       /auto [get]
 func (h *Handler) StartStop(w http.ResponseWriter, r *http.Request) {
@@ -64,7 +172,10 @@ func (h *Handler) StartStop(w http.ResponseWriter, r *http.Request) {
 
 	switch action {
 	case "start":
-		h.sched.Start()
+		if err := h.sched.Start(requestActor(r)); err != nil {
+			logError(w, "Failed to save scheduler state", http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(StatusResponse{Status: "started"}); err != nil {
@@ -72,7 +183,10 @@ func (h *Handler) StartStop(w http.ResponseWriter, r *http.Request) {
 		}
 		return
 	case "stop":
-		h.sched.Stop()
+		if err := h.sched.Stop(requestActor(r)); err != nil {
+			logError(w, "Failed to save scheduler state", http.StatusInternalServerError)
+			return
+		}
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
 		if err := json.NewEncoder(w).Encode(StatusResponse{Status: "stopped"}); err != nil {
@@ -97,13 +211,35 @@ func (h *Handler) StartStop(w http.ResponseWriter, r *http.Request) {
 // @Tags         messages
 // @Accept       json
 // @Produce      json
-// @Param        X-API-Key  header    string  true  "API Key for authentication"
-// @Success      200        {array}   entity.Message
-// @Failure      401        {object}  ErrorResponse
-// @Failure      500        {object}  ErrorResponse
+// @Param        X-API-Key      header    string  true   "API Key for authentication"
+// @Param        tag            query     string  false  "Only messages with this tag"
+// @Param        metadataKey    query     string  false  "Only messages whose metadata has this key"
+// @Param        metadataValue  query     string  false  "Value of metadataKey to match"
+// @Success      200            {array}   entity.Message
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
 // @Router       /sent [get]
 func (h *Handler) ListSent(w http.ResponseWriter, r *http.Request) {
-	msgs, err := h.repo.ListSent()
+	q := r.URL.Query()
+	filter := repository.MessageFilter{
+		Tag:           strings.ToLower(strings.TrimSpace(q.Get("tag"))),
+		MetadataKey:   q.Get("metadataKey"),
+		MetadataValue: q.Get("metadataValue"),
+	}
+	if (filter.MetadataKey != "" && !entity.ValidMetadataKey(filter.MetadataKey)) ||
+		(filter.MetadataKey == "" && filter.MetadataValue != "") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Invalid metadata filter",
+			Message: "metadataKey must be 1-64 letters, digits, '_' or '-' and is required with metadataValue",
+			Code:    "INVALID_FILTER",
+		})
+		return
+	}
+
+	msgs, err := h.repo.ListSent(filter)
 	if err != nil {
 		logError(w, "Failed to retrieve sent messages", http.StatusInternalServerError)
 		return
@@ -119,7 +255,7 @@ func (h *Handler) ListSent(w http.ResponseWriter, r *http.Request) {
 
 // CreateMessage yeni bir mesaj oluşturur
 // @Summary      Create a new message
-// @Description  Create a new message that will be sent automatically in the next batch
+// @Description  Create a new message that will be sent automatically in the next batch. Content can be given directly or rendered from a template with templateId, locale and variables.
 // @Tags         messages
 // @Accept       json
 // @Produce      json
@@ -128,6 +264,8 @@ func (h *Handler) ListSent(w http.ResponseWriter, r *http.Request) {
 // @Success      201        {object}  entity.Message
 // @Failure      400        {object}  ErrorResponse
 // @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      422        {object}  ErrorResponse
 // @Failure      500        {object}  ErrorResponse
 // @Router       /messages [post]
 func (h *Handler) CreateMessage(w http.ResponseWriter, r *http.Request) {
@@ -143,29 +281,88 @@ func (h *Handler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !phoneRegex.MatchString(in.To) {
+	number, err := phone.Parse(in.To, h.cfg.PhoneDefaultRegion)
+	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(ErrorResponse{
 			Error:   "Invalid phone number format",
-			Message: "Phone number must be in international format (e.g., +905551111111)",
+			Message: err.Error() + " (e.g., +905551111111)",
 			Code:    "INVALID_PHONE_NUMBER",
 		})
 		return
 	}
 
-	if in.Content == "" {
+	if in.Content != "" && in.TemplateID != 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Content and template are mutually exclusive",
+			Message: "Send either content or templateId, not both",
+			Code:    "CONTENT_AND_TEMPLATE",
+		})
+		return
+	}
+
+	content := in.Content
+	var templateID *uint
+	if in.TemplateID != 0 {
+		rendered, ok := h.renderTemplate(w, in)
+		if !ok {
+			return
+		}
+		content = rendered
+		templateID = &in.TemplateID
+	}
+
+	if content == "" {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
 		json.NewEncoder(w).Encode(ErrorResponse{
 			Error:   "Content cannot be empty",
-			Message: "Message content is required",
+			Message: "Message content or templateId is required",
 			Code:    "EMPTY_CONTENT",
 		})
 		return
 	}
 
-	msg, err := entity.NewMessage(in.To, in.Content, h.cfg.MsgCharLimit)
+	var links []entity.Link
+	if in.TrackLinks {
+		if !h.linkTrackingEnabled(w) {
+			return
+		}
+		// kısaltma karakter limitinden önce yapılır, mesaj uzunluğu gönderilecek metne göre hesaplanır
+		if content, links, err = h.shortener.Shorten(content); err != nil {
+			logError(w, "Failed to generate short links", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	priority, err := entity.ParsePriority(in.Priority)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Invalid priority",
+			Message: err.Error(),
+			Code:    "INVALID_PRIORITY",
+		})
+		return
+	}
+
+	category, err := entity.ParseCategory(in.Category)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Invalid category",
+			Message: err.Error(),
+			Code:    "INVALID_CATEGORY",
+		})
+		return
+	}
+
+	msg, err := entity.NewMessage(number.E164, content, h.cfg.MsgCharLimit)
 	if err != nil {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusBadRequest)
@@ -177,10 +374,30 @@ func (h *Handler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	msg.Priority = priority
+	msg.Category = category
+	msg.TemplateID = templateID
+	msg.Country = number.Region
+	msg.Links = links
+	if !h.applyLabels(w, msg, in.Metadata, in.Tags) {
+		return
+	}
+	if v := h.checkPolicy(msg); v != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Content rejected by policy",
+			Message: v.Error(),
+			Code:    "CONTENT_REJECTED",
+		})
+		return
+	}
+
 	if err := h.repo.Create(msg); err != nil {
 		logError(w, "Failed to create message in database", http.StatusInternalServerError)
 		return
 	}
+	h.notify()
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -189,3 +406,78 @@ func (h *Handler) CreateMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// CircuitStatus webhook circuit breaker durumunu döndürür
+// @Summary      Get webhook circuit breaker state
+// @Description  Returns the current circuit breaker state protecting the webhook provider
+// @Tags         webhook
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Success      200        {object}  application.CircuitBreakerStatus
+// @Failure      401        {object}  ErrorResponse
+// @Router       /webhook/circuit [get]
+func (h *Handler) CircuitStatus(w http.ResponseWriter, r *http.Request) {
+	status := application.CircuitBreakerStatus{State: application.CircuitDisabled}
+	if h.breaker != nil {
+		status = h.breaker.Status()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		logError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// RecipientCounters bir numaranın gönderim limiti sayaçlarını döndürür
+// @Summary      Get per-recipient throttle counters
+// @Description  Returns current send counters and limits for a phone number
+// @Tags         admin
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        to         path      string  true  "Phone number, national numbers use PHONE_DEFAULT_REGION"
+// @Success      200        {object}  RecipientCountersResponse
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /admin/recipients/{to}/throttle [get]
+func (h *Handler) RecipientCounters(w http.ResponseWriter, r *http.Request) {
+	if h.limiter == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Recipient throttling is disabled",
+			Message: "Per-recipient limits require Redis (REDIS_ADDR)",
+			Code:    "THROTTLE_DISABLED",
+		})
+		return
+	}
+
+	// sayaçlar gönderimde E.164 numarayla tutulur
+	number, err := phone.Parse(mux.Vars(r)["to"], h.cfg.PhoneDefaultRegion)
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "Invalid phone number format",
+			Message: err.Error() + " (e.g., +905551111111)",
+			Code:    "INVALID_PHONE_NUMBER",
+		})
+		return
+	}
+	to := number.E164
+	counters, err := h.limiter.Counters(r.Context(), to)
+	if err != nil {
+		logError(w, "Failed to read recipient counters", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(RecipientCountersResponse{To: to, Counters: counters}); err != nil {
+		logError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"insider-messaging/internal/application"
+
+	"github.com/gorilla/mux"
+)
+
+// WithHealth /livez ve /readyz'nin bağımlılık kontrollerini ve açılış/kapanış
+// durumunu yansıtmasını sağlar
+func WithHealth(c *application.HealthChecker) HandlerOption {
+	return func(h *Handler) { h.health = c }
+}
+
+// Livez süreç ve scheduler döngüsü sağlıklıysa 200, değilse 503 döner.
+// Veritabanı gibi dış bağımlılıklara bakmaz. /health gibi API key gerektirmez.
+func (h *Handler) Livez(w http.ResponseWriter, r *http.Request) {
+	h.writeHealth(w, func(c *application.HealthChecker) application.HealthReport { return c.Live(r.Context()) })
+}
+
+// Readyz instance trafik almaya hazırsa bileşen dökümüyle 200 döner; kritik
+// olmayan bir bileşen sorunluysa durum degraded olur. Açılışta, migration'lar
+// sürerken, kapanışta boşaltma sırasında veya kritik bir bileşen down ise 503 döner.
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	h.writeHealth(w, func(c *application.HealthChecker) application.HealthReport { return c.Ready(r.Context()) })
+}
+
+// writeHealth raporu yazar, down ise 503 döner
+func (h *Handler) writeHealth(w http.ResponseWriter, check func(*application.HealthChecker) application.HealthReport) {
+	report := application.HealthReport{Status: application.HealthUp, Components: map[string]application.ComponentHealth{}}
+	if h.health != nil {
+		report = check(h.health)
+	}
+	status := http.StatusOK
+	if report.Status == application.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, report)
+}
+
+// StartupHandler açılış sürerken (veritabanı bağlantısı, migration'lar) sadece
+// sağlık endpoint'lerine cevap verir, diğer isteklere 503 döner. Router hazır
+// olunca Serve ile bütün istekler router'a geçer.
+type StartupHandler struct {
+	boot   http.Handler
+	router atomic.Value
+}
+
+// NewStartupHandler health ile cevap veren bir açılış handler'ı oluşturur
+func NewStartupHandler(health *application.HealthChecker) *StartupHandler {
+	h := &Handler{health: health}
+	r := mux.NewRouter()
+	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
+	r.HandleFunc("/livez", h.Livez).Methods("GET")
+	r.HandleFunc("/readyz", h.Readyz).Methods("GET")
+	r.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+			Error: "Service Unavailable", Message: "server is starting", Code: "STARTING",
+		})
+	})
+	return &StartupHandler{boot: r}
+}
+
+// Serve açılış bittiğinde istekleri router'a yönlendirir
+func (s *StartupHandler) Serve(router http.Handler) {
+	s.router.Store(router)
+}
+
+func (s *StartupHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if router, ok := s.router.Load().(http.Handler); ok {
+		router.ServeHTTP(w, r)
+		return
+	}
+	s.boot.ServeHTTP(w, r)
+}
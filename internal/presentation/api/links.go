@@ -0,0 +1,120 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"github.com/gorilla/mux"
+)
+
+type MessageClicksResponse struct {
+	MessageID uint               `json:"messageId" example:"1"`
+	Clicks    int64              `json:"clicks" example:"3"`
+	Links     []entity.LinkStats `json:"links"`
+}
+
+// FollowLink kısa linki hedefine yönlendirir ve tıklamayı kaydeder
+// @Summary      Follow a tracked short link
+// @Description  Redirects to the original URL and records the click. Does not require an API key.
+// @Tags         links
+// @Param        code  path  string  true  "Short link code"
+// @Success      302
+// @Failure      404
+// @Router       /l/{code} [get]
+func (h *Handler) FollowLink(w http.ResponseWriter, r *http.Request) {
+	if h.links == nil {
+		http.NotFound(w, r)
+		return
+	}
+	link, err := h.links.FindByCode(mux.Vars(r)["code"])
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			http.NotFound(w, r)
+			return
+		}
+		logError(w, "Failed to resolve link", http.StatusInternalServerError)
+		return
+	}
+	// tıklama kaydı başarısız olsa da kullanıcı hedefe gitmeli
+	click := &entity.Click{LinkID: link.ID, MessageID: link.MessageID, UserAgent: r.UserAgent()}
+	if err := h.links.RecordClick(click); err != nil {
+		log.Printf("record click failed code=%s err=%v", link.Code, err)
+	}
+	http.Redirect(w, r, link.TargetURL, http.StatusFound)
+}
+
+// MessageClicks mesajdaki linklerin tıklama istatistiklerini döndürür
+// @Summary      Click stats for a message
+// @Tags         links
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Message ID"
+// @Success      200        {object}  MessageClicksResponse
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /messages/{id}/clicks [get]
+func (h *Handler) MessageClicks(w http.ResponseWriter, r *http.Request) {
+	if !h.linkTrackingEnabled(w) {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.links.MessageClicks(id)
+	if err != nil {
+		logError(w, "Failed to read click stats", http.StatusInternalServerError)
+		return
+	}
+	resp := MessageClicksResponse{MessageID: id, Links: stats}
+	for _, s := range stats {
+		resp.Clicks += s.Clicks
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// CampaignClicks kampanyanın tıklama özetini döndürür
+// @Summary      Click stats for a campaign
+// @Description  Total clicks, messages with at least one click and click-through rate over sent messages
+// @Tags         links
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Campaign ID"
+// @Success      200        {object}  entity.CampaignClickStats
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /campaigns/{id}/clicks [get]
+func (h *Handler) CampaignClicks(w http.ResponseWriter, r *http.Request) {
+	if !h.linkTrackingEnabled(w) {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	stats, err := h.links.CampaignClicks(id)
+	if err != nil {
+		logError(w, "Failed to read click stats", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// linkTrackingEnabled link takibi yapılandırılmamışsa 404 döner
+func (h *Handler) linkTrackingEnabled(w http.ResponseWriter) bool {
+	if h.shortener != nil && h.links != nil {
+		return true
+	}
+	writeJSON(w, http.StatusNotFound, ErrorResponse{
+		Error:   "Link tracking is disabled",
+		Message: "Set LINK_BASE_URL to enable short links",
+		Code:    "LINK_TRACKING_DISABLED",
+	})
+	return false
+}
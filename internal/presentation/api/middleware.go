@@ -1,10 +1,14 @@
 package api
 
 import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"net/http"
 	"strings"
 
+	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
 )
 
@@ -39,7 +43,25 @@ func APIKeyMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			next.ServeHTTP(w, r)
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), actorKey{}, keyFingerprint(apiKey))))
 		})
 	}
 }
+
+type actorKey struct{}
+
+// keyFingerprint API key'in kayıtlarda kullanılan kısa özeti, anahtarın kendisi saklanmaz
+func keyFingerprint(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "api-key:" + hex.EncodeToString(sum[:4])
+}
+
+// requestActor isteği yapanı API key özeti ve varsa X-Actor header'ı ile tanımlar
+func requestActor(r *http.Request) application.Actor {
+	key, _ := r.Context().Value(actorKey{}).(string)
+	name := strings.TrimSpace(r.Header.Get("X-Actor"))
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return application.Actor{Name: name, APIKey: key}
+}
@@ -14,8 +14,8 @@ import (
 )
 
 // NewRouter HTTP router'ı oluşturur ve tüm endpoint'leri tanımlar
-func NewRouter(sched application.SchedulerController, repo repository.MessageRepository, cfg *config.Config) http.Handler {
-	h := NewHandler(sched, repo, cfg)
+func NewRouter(sched application.SchedulerController, repo repository.MessageRepository, cfg *config.Config, opts ...HandlerOption) http.Handler {
+	h := NewHandler(sched, repo, cfg, opts...)
 	r := mux.NewRouter()
 
 	apiKeyMiddleware := APIKeyMiddleware(cfg)
@@ -23,10 +23,37 @@ func NewRouter(sched application.SchedulerController, repo repository.MessageRep
 	api := r.PathPrefix("/api").Subrouter()
 	api.Use(apiKeyMiddleware)
 	api.HandleFunc("/auto", h.StartStop).Methods("POST", "GET")
+	api.HandleFunc("/scheduler/status", h.SchedulerStatus).Methods("GET")
+	api.HandleFunc("/scheduler/schedules", h.ListSchedules).Methods("GET")
+	api.HandleFunc("/scheduler/config", h.GetSchedulerConfig).Methods("GET")
+	api.HandleFunc("/scheduler/config", h.UpdateSchedulerConfig).Methods("PUT")
+	api.HandleFunc("/scheduler/audit", h.SchedulerAudit).Methods("GET")
+	api.HandleFunc("/scheduler/run", h.RunBatch).Methods("POST")
+	api.HandleFunc("/scheduler/runs/{id}", h.GetManualRun).Methods("GET")
 	api.HandleFunc("/sent", h.ListSent).Methods("GET")
 	api.HandleFunc("/messages", h.CreateMessage).Methods("POST")
+	api.HandleFunc("/templates", h.ListTemplates).Methods("GET")
+	api.HandleFunc("/templates", h.CreateTemplate).Methods("POST")
+	api.HandleFunc("/templates/{id}", h.GetTemplate).Methods("GET")
+	api.HandleFunc("/templates/{id}", h.UpdateTemplate).Methods("PUT")
+	api.HandleFunc("/templates/{id}", h.DeleteTemplate).Methods("DELETE")
+	api.HandleFunc("/campaigns", h.ListCampaigns).Methods("GET")
+	api.HandleFunc("/campaigns", h.CreateCampaign).Methods("POST")
+	api.HandleFunc("/campaigns/{id}", h.GetCampaign).Methods("GET")
+	api.HandleFunc("/campaigns/{id}/pause", h.PauseCampaign).Methods("POST")
+	api.HandleFunc("/campaigns/{id}/resume", h.ResumeCampaign).Methods("POST")
+	api.HandleFunc("/campaigns/{id}/cancel", h.CancelCampaign).Methods("POST")
+	api.HandleFunc("/campaigns/{id}/clicks", h.CampaignClicks).Methods("GET")
+	api.HandleFunc("/messages/{id}/clicks", h.MessageClicks).Methods("GET")
+	api.HandleFunc("/admin/content-policy", h.GetContentPolicy).Methods("GET")
+	api.HandleFunc("/admin/content-policy", h.UpdateContentPolicy).Methods("PUT")
+	api.HandleFunc("/webhook/circuit", h.CircuitStatus).Methods("GET")
+	api.HandleFunc("/admin/recipients/{to}/throttle", h.RecipientCounters).Methods("GET")
 
+	r.HandleFunc("/l/{code}", h.FollowLink).Methods("GET")
 	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(200) })
+	r.HandleFunc("/livez", h.Livez).Methods("GET")
+	r.HandleFunc("/readyz", h.Readyz).Methods("GET")
 
 	docs.SwaggerInfo.Host = fmt.Sprintf("localhost:%s", cfg.Port)
 	docs.SwaggerInfo.BasePath = "/api"
@@ -0,0 +1,297 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/gorilla/mux"
+)
+
+// SchedulesResponse scheduler durumu ve zamanlamaları
+// @Description Scheduler running state with configured schedules
+type SchedulesResponse struct {
+	Running   bool                       `json:"running" example:"true"`
+	Schedules []application.ScheduleInfo `json:"schedules"`
+}
+
+// ListSchedules yapılandırılmış zamanlamaları ve sıradaki çalışma zamanlarını döndürür
+// @Summary      List scheduler schedules
+// @Description  Returns configured cron schedules, the job each runs and its next fire time (empty while the scheduler is stopped)
+// @Tags         scheduler
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Success      200        {object}  SchedulesResponse
+// @Failure      401        {object}  ErrorResponse
+// @Router       /scheduler/schedules [get]
+func (h *Handler) ListSchedules(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, SchedulesResponse{Running: h.sched.IsRunning(), Schedules: h.sched.Schedules()})
+}
+
+// GetSchedulerConfig geçerli gönderim ayarlarını döndürür
+// @Summary      Get dispatch settings
+// @Description  Returns the send interval, batch size and concurrency currently applied
+// @Tags         scheduler
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Success      200        {object}  entity.DispatchSettings
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /scheduler/config [get]
+func (h *Handler) GetSchedulerConfig(w http.ResponseWriter, r *http.Request) {
+	if !h.dispatchEnabled(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.dispatch.Current())
+}
+
+// UpdateSchedulerConfig gönderim ayarlarını değiştirir, tüm instance'larda yeniden yüklenir
+// @Summary      Update dispatch settings
+// @Description  Changes only the given fields. This instance applies them immediately (resetting the send-batch timer), others on their next reload. Settings are persisted and survive restarts.
+// @Tags         scheduler
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key  header    string                             true  "API Key for authentication"
+// @Param        settings   body      application.DispatchSettingsPatch  true  "Fields to change"
+// @Success      200        {object}  entity.DispatchSettings
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      409        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /scheduler/config [put]
+func (h *Handler) UpdateSchedulerConfig(w http.ResponseWriter, r *http.Request) {
+	if !h.dispatchEnabled(w) {
+		return
+	}
+	var in application.DispatchSettingsPatch
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request payload",
+			Message: "Request body must be valid JSON",
+			Code:    "INVALID_PAYLOAD",
+		})
+		return
+	}
+	if in.IntervalSeconds != nil && !hasIntervalSchedule(h.cfg) {
+		writeJSON(w, http.StatusConflict, ErrorResponse{
+			Error:   "Interval not applicable",
+			Message: "SCHEDULES defines custom cron schedules; intervalSeconds only applies to the SCHEDULE_SECONDS schedule",
+			Code:    "CUSTOM_SCHEDULES",
+		})
+		return
+	}
+	s, err := h.dispatch.Update(in)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidDispatchSettings) {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "Validation failed",
+				Message: err.Error(),
+				Code:    "INVALID_SETTINGS",
+			})
+			return
+		}
+		logError(w, "Failed to save dispatch settings", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, s)
+}
+
+// hasIntervalSchedule intervalSeconds'ın uygulanacağı SCHEDULE_SECONDS zamanlaması varsa true döner
+func hasIntervalSchedule(cfg *config.Config) bool {
+	for _, sc := range cfg.Schedules {
+		if sc.Interval {
+			return true
+		}
+	}
+	return false
+}
+
+// dispatchEnabled gönderim ayarları bağlı değilse 404 döner
+func (h *Handler) dispatchEnabled(w http.ResponseWriter) bool {
+	if h.dispatch != nil {
+		return true
+	}
+	writeJSON(w, http.StatusNotFound, ErrorResponse{
+		Error:   "Scheduler config is disabled",
+		Message: "Runtime dispatch settings are not configured",
+		Code:    "SCHEDULER_CONFIG_DISABLED",
+	})
+	return false
+}
+
+// SchedulerStatus scheduler durumunu, son çalışmaları ve kuyruk derinliğini döndürür
+// @Summary      Get scheduler status
+// @Description  Returns running state, who started or stopped it, last and recent batch runs with sent/failed counts, the next send-batch run and queue depth
+// @Tags         scheduler
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Success      200        {object}  application.SchedulerStatus
+// @Failure      401        {object}  ErrorResponse
+// @Router       /scheduler/status [get]
+func (h *Handler) SchedulerStatus(w http.ResponseWriter, r *http.Request) {
+	st := h.sched.Status()
+	if q, err := h.repo.QueueStats(); err != nil {
+		log.Printf("queue stats failed: %v", err)
+	} else {
+		st.Queue = &q
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+// RunBatchRequest elle batch çalıştırma isteği, gövde opsiyoneldir
+// @Description Optional body for a manual batch run
+type RunBatchRequest struct {
+	// BatchSize 0 ise geçerli gönderim ayarı kullanılır
+	BatchSize int `json:"batchSize" example:"500"`
+	// Async true ise sonucu beklemeden run ID'si döner
+	Async bool `json:"async" example:"false"`
+}
+
+// RunBatch send-batch'i zamanlamayı beklemeden bir kez çalıştırır
+// @Summary      Run a send batch now
+// @Description  Runs one send batch immediately, whether or not the scheduler is started. Waits for a running scheduled batch to finish first, never overlapping it. By default responds with the result; with async=true, or if the client stops waiting, responds 202 with a run ID to poll.
+// @Tags         scheduler
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key  header    string           true   "API Key for authentication"
+// @Param        X-Actor    header    string           false  "Who performs the action, recorded on the run"
+// @Param        request    body      RunBatchRequest  false  "Batch size and async flag"
+// @Success      200        {object}  application.ManualRun
+// @Success      202        {object}  application.ManualRun
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      409        {object}  ErrorResponse
+// @Failure      503        {object}  ErrorResponse
+// @Router       /scheduler/run [post]
+func (h *Handler) RunBatch(w http.ResponseWriter, r *http.Request) {
+	var in RunBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil && !errors.Is(err, io.EOF) {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request payload",
+			Message: "Request body must be valid JSON",
+			Code:    "INVALID_PAYLOAD",
+		})
+		return
+	}
+	if in.BatchSize < 0 || in.BatchSize > entity.MaxDispatchBatchSize {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: fmt.Sprintf("batchSize must be between 1 and %d", entity.MaxDispatchBatchSize),
+			Code:    "INVALID_BATCH_SIZE",
+		})
+		return
+	}
+
+	run, err := h.sched.TriggerRun(requestActor(r).String(), in.BatchSize)
+	if err != nil {
+		if errors.Is(err, application.ErrNotLeader) {
+			writeJSON(w, http.StatusConflict, ErrorResponse{
+				Error:   "Not the scheduler leader",
+				Message: err.Error(),
+				Code:    "NOT_LEADER",
+			})
+			return
+		}
+		if errors.Is(err, application.ErrShuttingDown) {
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "Instance is shutting down",
+				Message: err.Error(),
+				Code:    "SHUTTING_DOWN",
+			})
+			return
+		}
+		logError(w, "Failed to trigger batch run", http.StatusInternalServerError)
+		return
+	}
+	if !in.Async {
+		run, _ = h.sched.ManualRun(r.Context(), run.ID, true)
+	}
+	status := http.StatusOK
+	if run.Status == application.ManualRunQueued || run.Status == application.ManualRunRunning {
+		status = http.StatusAccepted
+		w.Header().Set("Location", "/api/scheduler/runs/"+run.ID)
+	}
+	writeJSON(w, status, run)
+}
+
+// GetManualRun elle tetiklenen çalışmanın durumunu döndürür
+// @Summary      Get manual run
+// @Description  Returns the state and, once finished, the result of a manual batch run. Runs are kept in memory on the instance that accepted them.
+// @Tags         scheduler
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      string  true  "Run ID"
+// @Success      200        {object}  application.ManualRun
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /scheduler/runs/{id} [get]
+func (h *Handler) GetManualRun(w http.ResponseWriter, r *http.Request) {
+	run, ok := h.sched.ManualRun(r.Context(), mux.Vars(r)["id"], false)
+	if !ok {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "Run not found",
+			Message: "No manual run with this ID on this instance",
+			Code:    "RUN_NOT_FOUND",
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, run)
+}
+
+// Denetim kaydı listesinin varsayılan ve en büyük boyutu
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// SchedulerAudit scheduler'ı kimin hangi API key ile başlatıp durdurduğunu döndürür
+// @Summary      List scheduler state changes
+// @Description  Returns the audit trail of scheduler start/stop requests, newest first, with the X-Actor name, API key fingerprint and the instance that accepted the request. AUTO_START entries record the first-boot state.
+// @Tags         scheduler
+// @Produce      json
+// @Param        X-API-Key  header    string  true   "API Key for authentication"
+// @Param        limit      query     int     false  "Maximum entries (1-500)"  default(50)
+// @Success      200        {array}   entity.SchedulerStateChange
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /scheduler/audit [get]
+func (h *Handler) SchedulerAudit(w http.ResponseWriter, r *http.Request) {
+	if h.audit == nil {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "Scheduler audit is disabled",
+			Message: "Scheduler state is not persisted",
+			Code:    "SCHEDULER_AUDIT_DISABLED",
+		})
+		return
+	}
+	limit := defaultAuditLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxAuditLimit {
+			writeJSON(w, http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid limit",
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxAuditLimit),
+				Code:    "INVALID_LIMIT",
+			})
+			return
+		}
+		limit = n
+	}
+	changes, err := h.audit.Changes(limit)
+	if err != nil {
+		logError(w, "Failed to retrieve scheduler audit", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, changes)
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"github.com/gorilla/mux"
+)
+
+type TemplateRequest struct {
+	Name          string                   `json:"name" example:"order-shipped" binding:"required"`
+	DefaultLocale string                   `json:"defaultLocale,omitempty" example:"tr"`
+	Variants      []entity.TemplateVariant `json:"variants" binding:"required"`
+}
+
+// ListTemplates tüm şablonları listeler
+// @Summary      List templates
+// @Description  Get all message templates with their locale variants
+// @Tags         templates
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Success      200        {array}   entity.Template
+// @Failure      401        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /templates [get]
+func (h *Handler) ListTemplates(w http.ResponseWriter, r *http.Request) {
+	if !h.templatesEnabled(w) {
+		return
+	}
+	list, err := h.templates.List()
+	if err != nil {
+		logError(w, "Failed to list templates", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GetTemplate tek bir şablonu döndürür
+// @Summary      Get template
+// @Tags         templates
+// @Produce      json
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Template ID"
+// @Success      200        {object}  entity.Template
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /templates/{id} [get]
+func (h *Handler) GetTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.templatesEnabled(w) {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	t, err := h.templates.Get(id)
+	if err != nil {
+		h.templateError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// CreateTemplate yeni bir şablon oluşturur
+// @Summary      Create template
+// @Description  Create a named template with per-locale variants. Placeholders use the {{variable}} syntax and are all required when rendering.
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key  header    string           true  "API Key for authentication"
+// @Param        template   body      TemplateRequest  true  "Template data"
+// @Success      201        {object}  entity.Template
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      500        {object}  ErrorResponse
+// @Router       /templates [post]
+func (h *Handler) CreateTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.templatesEnabled(w) {
+		return
+	}
+	t, ok := decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+	if err := h.templates.Create(t); err != nil {
+		logError(w, "Failed to create template", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// UpdateTemplate şablonu ve tüm varyantlarını değiştirir
+// @Summary      Update template
+// @Tags         templates
+// @Accept       json
+// @Produce      json
+// @Param        X-API-Key  header    string           true  "API Key for authentication"
+// @Param        id         path      int              true  "Template ID"
+// @Param        template   body      TemplateRequest  true  "Template data"
+// @Success      200        {object}  entity.Template
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /templates/{id} [put]
+func (h *Handler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.templatesEnabled(w) {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	t, ok := decodeTemplate(w, r)
+	if !ok {
+		return
+	}
+	t.ID = id
+	if err := h.templates.Update(t); err != nil {
+		h.templateError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// DeleteTemplate şablonu siler
+// @Summary      Delete template
+// @Tags         templates
+// @Param        X-API-Key  header    string  true  "API Key for authentication"
+// @Param        id         path      int     true  "Template ID"
+// @Success      204
+// @Failure      400        {object}  ErrorResponse
+// @Failure      401        {object}  ErrorResponse
+// @Failure      404        {object}  ErrorResponse
+// @Router       /templates/{id} [delete]
+func (h *Handler) DeleteTemplate(w http.ResponseWriter, r *http.Request) {
+	if !h.templatesEnabled(w) {
+		return
+	}
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := h.templates.Delete(id); err != nil {
+		h.templateError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// renderTemplate istekteki şablonu dil ve değişkenlerle doldurur, hata olursa cevabı yazar
+func (h *Handler) renderTemplate(w http.ResponseWriter, in CreateMessageRequest) (string, bool) {
+	if !h.templatesEnabled(w) {
+		return "", false
+	}
+	t, err := h.templates.Get(in.TemplateID)
+	if err != nil {
+		h.templateError(w, err)
+		return "", false
+	}
+	content, err := t.Render(in.Locale, in.Variables)
+	if err != nil {
+		code := "TEMPLATE_RENDER_ERROR"
+		var missing *entity.MissingVariablesError
+		if errors.As(err, &missing) {
+			code = "MISSING_VARIABLES"
+		}
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Template could not be rendered",
+			Message: err.Error(),
+			Code:    code,
+		})
+		return "", false
+	}
+	return content, true
+}
+
+// templatesEnabled şablon repository'si bağlı değilse 404 döner
+func (h *Handler) templatesEnabled(w http.ResponseWriter) bool {
+	if h.templates != nil {
+		return true
+	}
+	writeJSON(w, http.StatusNotFound, ErrorResponse{
+		Error:   "Templates are disabled",
+		Message: "No template repository is configured",
+		Code:    "TEMPLATES_DISABLED",
+	})
+	return false
+}
+
+// templateError repository hatasını HTTP cevabına çevirir
+func (h *Handler) templateError(w http.ResponseWriter, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{
+			Error:   "Template not found",
+			Message: err.Error(),
+			Code:    "TEMPLATE_NOT_FOUND",
+		})
+		return
+	}
+	logError(w, "Template repository error", http.StatusInternalServerError)
+}
+
+// pathID path'teki {id} değerini okur
+func pathID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid id",
+			Message: "Id must be a positive integer",
+			Code:    "INVALID_ID",
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// decodeTemplate istek gövdesini okuyup şablonu doğrular
+func decodeTemplate(w http.ResponseWriter, r *http.Request) (*entity.Template, bool) {
+	var in TemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request payload",
+			Message: "Request body must be valid JSON",
+			Code:    "INVALID_PAYLOAD",
+		})
+		return nil, false
+	}
+	t := &entity.Template{Name: in.Name, DefaultLocale: in.DefaultLocale, Variants: in.Variants}
+	if err := t.Validate(); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{
+			Error:   "Validation failed",
+			Message: err.Error(),
+			Code:    "VALIDATION_ERROR",
+		})
+		return nil, false
+	}
+	return t, true
+}
+
+// writeJSON verilen status ile JSON cevap yazar
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("API error: failed to encode response: %v", err)
+	}
+}
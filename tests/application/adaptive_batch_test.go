@@ -0,0 +1,101 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueRepo memRepo'ya ayarlanabilir kuyruk derinliği ekler
+type queueRepo struct {
+	*memRepo
+	due int64
+	err error
+}
+
+func (r *queueRepo) QueueStats() (repository.QueueStats, error) {
+	return repository.QueueStats{Pending: r.due, Due: r.due}, r.err
+}
+
+func adaptiveConfig() *config.Config {
+	cfg := testConfig()
+	cfg.AdaptiveBatchMin, cfg.AdaptiveBatchMax = 5, 500
+	cfg.AdaptiveBatchTarget, cfg.AdaptiveBatchWindow = 0.8, 4
+	return cfg
+}
+
+func TestAdaptiveBatchSizer_Size(t *testing.T) {
+	settings := entity.DispatchSettings{IntervalSeconds: 10, BatchSize: 50, Concurrency: 2}
+	repo := &queueRepo{memRepo: newMemRepo(), due: 1000}
+	sizer := application.NewAdaptiveBatchSizer(repo, adaptiveConfig())
+
+	// örnek yokken ayarlardaki boyuttan başlar, kuyruk küçükse onunla sınırlanır
+	assert.Equal(t, 50, sizer.Size(settings).Size)
+	repo.due = 20
+	assert.Equal(t, 20, sizer.Size(settings).Size)
+	repo.due = 0
+	assert.Equal(t, 5, sizer.Size(settings).Size, "never below min")
+
+	// 10s * 0.8 / 100ms * 2 worker = 160
+	repo.due = 1000
+	for i := 0; i < 4; i++ {
+		sizer.Observe(100*time.Millisecond, false)
+	}
+	sz := sizer.Size(settings)
+	assert.Equal(t, 160, sz.Size)
+	assert.Equal(t, int64(1000), sz.Backlog)
+	assert.Equal(t, int64(100), sz.LatencyMs)
+
+	// hataların yarısı kapasiteyi yarıya indirir, eski örnekler pencereden düşer
+	sizer.Observe(100*time.Millisecond, true)
+	sizer.Observe(100*time.Millisecond, true)
+	sz = sizer.Size(settings)
+	assert.Equal(t, 80, sz.Size)
+	assert.InDelta(t, 0.5, sz.ErrorRate, 0.001)
+
+	// hızlı sağlayıcıda max'ı geçmez
+	for i := 0; i < 4; i++ {
+		sizer.Observe(time.Millisecond, false)
+	}
+	assert.Equal(t, 500, sizer.Size(settings).Size)
+
+	// kuyruk okunamazsa kapasiteye göre karar verir
+	repo.err = errors.New("db down")
+	sz = sizer.Size(settings)
+	assert.Equal(t, 500, sz.Size)
+	assert.Zero(t, sz.Backlog)
+}
+
+func TestRun_AdaptiveSizing(t *testing.T) {
+	repo := &queueRepo{memRepo: newMemRepo(msg("+905551111111"), msg("+905552222222"), msg("+905553333333"))}
+	repo.due = 3
+	cfg := adaptiveConfig()
+	cfg.AdaptiveBatchMin = 1
+	sizer := application.NewAdaptiveBatchSizer(repo, cfg)
+	uc := application.NewSendBatchUseCase(repo, &stubSender{}, nil, cfg, application.WithAdaptiveBatchSizing(sizer))
+
+	res, err := uc.Run(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, config.BatchSizingAdaptive, res.Sizing)
+	assert.Equal(t, 3, res.BatchSize)
+	assert.Equal(t, int64(3), res.Backlog)
+	assert.Equal(t, 3, res.Sent)
+
+	// gönderimler gözlemlenir
+	assert.Greater(t, sizer.Size(entity.DispatchSettings{IntervalSeconds: 10, BatchSize: 1, Concurrency: 1}).Size, 1)
+
+	// elle verilen boyut adaptive hesaplamayı atlar
+	res, err = uc.Run(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, application.BatchSizingManual, res.Sizing)
+	assert.Equal(t, 2, res.BatchSize)
+}
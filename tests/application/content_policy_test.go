@@ -0,0 +1,118 @@
+package application_test
+
+import (
+	"context"
+	"testing"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memPolicyRepo struct {
+	rules *entity.ContentRules
+}
+
+func (r *memPolicyRepo) Load() (*entity.ContentRules, error) {
+	if r.rules == nil {
+		return nil, nil
+	}
+	cp := *r.rules
+	return &cp, nil
+}
+
+func (r *memPolicyRepo) Save(rules *entity.ContentRules) error {
+	version := int64(1)
+	if r.rules != nil {
+		version = r.rules.Version + 1
+	}
+	rules.Version = version
+	cp := *rules
+	r.rules = &cp
+	return nil
+}
+
+func TestContentFilter_Rules(t *testing.T) {
+	f, err := application.NewContentFilter(&memPolicyRepo{})
+	require.NoError(t, err)
+	_, err = f.Update(entity.ContentRules{
+		BlockedKeywords: []string{"Casino"},
+		BlockedPatterns: []string{`(?i)free\s+money`},
+		AllowedDomains:  []string{"insiderone.com"},
+		MaxURLs:         1,
+		OptOutText:      "IPTAL",
+	})
+	require.NoError(t, err)
+
+	cases := []struct {
+		content  string
+		category entity.Category
+		rule     string
+	}{
+		{"Best CASINO in town", entity.CategoryGeneral, "blocked_keyword"},
+		{"Get FREE   money now", entity.CategoryGeneral, "blocked_pattern"},
+		{"see https://a.insiderone.com and www.insiderone.com", entity.CategoryGeneral, "max_urls"},
+		{"see https://evil.example.com/x", entity.CategoryGeneral, "url_domain"},
+		{"Spring sale!", entity.CategoryMarketing, "opt_out"},
+		{"Spring sale! https://shop.insiderone.com. IPTAL yaz", entity.CategoryMarketing, ""},
+		{"Your code is 1234", entity.CategoryTransactional, ""},
+	}
+	for _, c := range cases {
+		v := f.Check(&entity.Message{Content: c.content, Category: c.category})
+		if c.rule == "" {
+			assert.Nil(t, v, c.content)
+			continue
+		}
+		require.NotNil(t, v, c.content)
+		assert.Equal(t, c.rule, v.Rule, c.content)
+		assert.Equal(t, entity.PolicyReject, v.Action)
+	}
+}
+
+func TestContentFilter_InvalidRulesAreNotSaved(t *testing.T) {
+	repo := &memPolicyRepo{}
+	f, err := application.NewContentFilter(repo)
+	require.NoError(t, err)
+
+	_, err = f.Update(entity.ContentRules{BlockedPatterns: []string{"("}})
+	assert.ErrorIs(t, err, application.ErrInvalidContentRules)
+	_, err = f.Update(entity.ContentRules{Action: "drop"})
+	assert.ErrorIs(t, err, application.ErrInvalidContentRules)
+	assert.Nil(t, repo.rules)
+}
+
+func TestContentFilter_ReloadPicksUpOtherInstanceChanges(t *testing.T) {
+	repo := &memPolicyRepo{}
+	f, err := application.NewContentFilter(repo)
+	require.NoError(t, err)
+	m := &entity.Message{Content: "casino night"}
+	assert.Nil(t, f.Check(m))
+
+	// başka bir instance kuralları değiştirdi
+	require.NoError(t, repo.Save(&entity.ContentRules{BlockedKeywords: []string{"casino"}, Action: entity.PolicyQuarantine}))
+	require.NoError(t, f.Reload())
+
+	v := f.Check(m)
+	require.NotNil(t, v)
+	assert.Equal(t, entity.PolicyQuarantine, v.Action)
+}
+
+func TestSendBatch_QuarantinesPolicyViolationsBeforeSend(t *testing.T) {
+	repo := newMemRepo(&entity.Message{To: "+905551111111", Content: "casino night"}, msg("+905552222222"))
+	f, err := application.NewContentFilter(&memPolicyRepo{rules: &entity.ContentRules{
+		BlockedKeywords: []string{"casino"}, Action: entity.PolicyReject,
+	}})
+	require.NoError(t, err)
+	snd := &stubSender{}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig(), application.WithContentPolicy(f))
+
+	require.NoError(t, uc.Execute(context.Background()))
+
+	assert.Equal(t, 1, snd.calls)
+	assert.Equal(t, entity.StatusQuarantined, repo.msgs[1].Status)
+	assert.Contains(t, repo.msgs[1].LastError, "blocked_keyword")
+	assert.Zero(t, repo.msgs[1].Attempts)
+	assert.Equal(t, entity.StatusSent, repo.msgs[2].Status)
+}
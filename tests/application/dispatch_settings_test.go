@@ -0,0 +1,131 @@
+package application_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memSettingsRepo struct {
+	mu sync.Mutex
+	s  *entity.DispatchSettings
+}
+
+func (r *memSettingsRepo) Load() (*entity.DispatchSettings, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.s == nil {
+		return nil, nil
+	}
+	cp := *r.s
+	return &cp, nil
+}
+
+func (r *memSettingsRepo) Save(s *entity.DispatchSettings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	version := int64(1)
+	if r.s != nil {
+		version = r.s.Version + 1
+	}
+	s.Version = version
+	cp := *s
+	r.s = &cp
+	return nil
+}
+
+func intPtr(i int) *int { return &i }
+
+func TestDispatchSettingsStore_DefaultsAndPatch(t *testing.T) {
+	repo := &memSettingsRepo{}
+	store, err := application.NewDispatchSettingsStore(repo, &config.Config{ScheduleSec: 120, MsgPerTick: 2, SendConcurrency: 1})
+	require.NoError(t, err)
+	assert.Equal(t, entity.DispatchSettings{IntervalSeconds: 120, BatchSize: 2, Concurrency: 1}, store.Current())
+
+	var notified []entity.DispatchSettings
+	store.OnChange(func(s entity.DispatchSettings) { notified = append(notified, s) })
+
+	s, err := store.Update(application.DispatchSettingsPatch{BatchSize: intPtr(50)})
+	require.NoError(t, err)
+	assert.Equal(t, 120, s.IntervalSeconds)
+	assert.Equal(t, 50, s.BatchSize)
+	assert.Equal(t, int64(1), s.Version)
+	require.Len(t, notified, 1)
+
+	_, err = store.Update(application.DispatchSettingsPatch{Concurrency: intPtr(0)})
+	assert.ErrorIs(t, err, application.ErrInvalidDispatchSettings)
+	assert.Equal(t, 1, store.Current().Concurrency)
+	assert.Len(t, notified, 1)
+
+	// kayıtlı ayarlar env değerlerine göre önceliklidir
+	restarted, err := application.NewDispatchSettingsStore(repo, &config.Config{ScheduleSec: 30, MsgPerTick: 5, SendConcurrency: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 50, restarted.Current().BatchSize)
+}
+
+func TestDispatchSettingsStore_ReloadFromOtherInstance(t *testing.T) {
+	repo := &memSettingsRepo{}
+	cfg := &config.Config{ScheduleSec: 120, MsgPerTick: 2, SendConcurrency: 1}
+	a, err := application.NewDispatchSettingsStore(repo, cfg)
+	require.NoError(t, err)
+	b, err := application.NewDispatchSettingsStore(repo, cfg)
+	require.NoError(t, err)
+
+	var interval int
+	b.OnChange(func(s entity.DispatchSettings) { interval = s.IntervalSeconds })
+
+	_, err = a.Update(application.DispatchSettingsPatch{IntervalSeconds: intPtr(10)})
+	require.NoError(t, err)
+	require.NoError(t, b.Reload())
+	assert.Equal(t, 10, b.Current().IntervalSeconds)
+	assert.Equal(t, 10, interval)
+}
+
+// slowSender eşzamanlı istek sayısının en yüksek değerini ölçer
+type slowSender struct {
+	inFlight, peak, calls int32
+}
+
+func (s *slowSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	n := atomic.AddInt32(&s.inFlight, 1)
+	defer atomic.AddInt32(&s.inFlight, -1)
+	atomic.AddInt32(&s.calls, 1)
+	for {
+		p := atomic.LoadInt32(&s.peak)
+		if n <= p || atomic.CompareAndSwapInt32(&s.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	return application.SendResult{MessageID: "webhook-ok", MessageIDSource: entity.MessageIDProvider}, nil
+}
+
+func TestExecute_UsesRuntimeBatchSizeAndConcurrency(t *testing.T) {
+	var msgs []*entity.Message
+	for i := 0; i < 10; i++ {
+		msgs = append(msgs, msg("+905551111111"))
+	}
+	repo := newMemRepo(msgs...)
+	store, err := application.NewDispatchSettingsStore(&memSettingsRepo{}, &config.Config{ScheduleSec: 120, MsgPerTick: 2, SendConcurrency: 1})
+	require.NoError(t, err)
+	_, err = store.Update(application.DispatchSettingsPatch{BatchSize: intPtr(8), Concurrency: intPtr(4)})
+	require.NoError(t, err)
+
+	snd := &slowSender{}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig(), application.WithDispatchSettings(store))
+	require.NoError(t, uc.Execute(context.Background()))
+
+	assert.Equal(t, int32(8), snd.calls)
+	assert.Greater(t, snd.peak, int32(1))
+	assert.LessOrEqual(t, snd.peak, int32(4))
+	assert.Len(t, repo.attempts, 8)
+}
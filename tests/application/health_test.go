@@ -0,0 +1,117 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func staticCheck(status application.HealthState) application.HealthCheck {
+	return func(ctx context.Context) application.ComponentHealth {
+		return application.ComponentHealth{Status: status}
+	}
+}
+
+func TestHealthChecker_Ready(t *testing.T) {
+	readiness := application.NewReadiness()
+	c := application.NewHealthChecker(readiness, time.Second)
+	c.Register(application.HealthComponent{Name: "database", Check: staticCheck(application.HealthUp), Critical: true})
+
+	// açılış bitmeden bileşenler sağlıklı olsa da hazır değil
+	r := c.Ready(context.Background())
+	assert.Equal(t, application.HealthDown, r.Status)
+	assert.Equal(t, "starting", r.Reason)
+	assert.Equal(t, application.HealthUp, r.Components["database"].Status)
+
+	readiness.SetReady()
+	r = c.Ready(context.Background())
+	assert.Equal(t, application.HealthUp, r.Status)
+	assert.Empty(t, r.Reason)
+
+	// kritik olmayan bileşenin kesintisi sadece degraded yapar
+	c.Register(application.HealthComponent{Name: "redis", Check: staticCheck(application.HealthDown)})
+	r = c.Ready(context.Background())
+	assert.Equal(t, application.HealthDegraded, r.Status)
+	assert.False(t, r.Components["redis"].Critical)
+
+	c.Register(application.HealthComponent{Name: "queue", Check: staticCheck(application.HealthDown), Critical: true})
+	r = c.Ready(context.Background())
+	assert.Equal(t, application.HealthDown, r.Status)
+	assert.True(t, r.Components["queue"].Critical)
+}
+
+func TestHealthChecker_TimeoutAndLiveness(t *testing.T) {
+	readiness := application.NewReadiness()
+	readiness.SetReady()
+	c := application.NewHealthChecker(readiness, 50*time.Millisecond)
+	hang := make(chan struct{})
+	defer close(hang)
+	c.Register(application.HealthComponent{Name: "database", Critical: true, Check: func(ctx context.Context) application.ComponentHealth {
+		<-hang
+		return application.ComponentHealth{Status: application.HealthUp}
+	}})
+	c.Register(application.HealthComponent{Name: "scheduler", Check: staticCheck(application.HealthUp), Liveness: true})
+
+	start := time.Now()
+	r := c.Ready(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, application.HealthDown, r.Status)
+	assert.Contains(t, r.Components["database"].Message, "timed out")
+
+	// liveness veritabanına bakmaz
+	r = c.Live(context.Background())
+	assert.Equal(t, application.HealthUp, r.Status)
+	assert.Len(t, r.Components, 1)
+	assert.Contains(t, r.Components, "scheduler")
+
+	readiness.SetNotReady("draining")
+	assert.Equal(t, application.HealthUp, c.Live(context.Background()).Status)
+}
+
+// openBreaker her zaman açık circuit breaker gibi davranır
+type openBreaker struct{ stubSender }
+
+func (b *openBreaker) Status() application.CircuitBreakerStatus {
+	return application.CircuitBreakerStatus{State: application.CircuitOpen, FailureRatio: 0.8}
+}
+
+func TestWebhookMonitor_Health(t *testing.T) {
+	ctx := context.Background()
+	msg := &entity.Message{ID: 1}
+	timeout := &application.TimeoutError{Err: errors.New("deadline exceeded")}
+	next := &stubSender{}
+	m := application.NewWebhookMonitor(next, time.Minute)
+
+	h := m.Health(ctx)
+	assert.Equal(t, application.HealthUp, h.Status)
+	assert.Contains(t, h.Message, "no sends")
+
+	// ulaşılamayan webhook, son pencerede başarılı gönderim yok
+	next.errs = []error{timeout}
+	_, _ = m.Send(ctx, msg)
+	assert.Equal(t, application.HealthDown, m.Health(ctx).Status)
+
+	_, _ = m.Send(ctx, msg)
+	assert.Equal(t, application.HealthUp, m.Health(ctx).Status)
+
+	next.errs = []error{timeout}
+	_, _ = m.Send(ctx, msg)
+	h = m.Health(ctx)
+	assert.Equal(t, application.HealthDegraded, h.Status)
+	assert.Contains(t, h.Message, "webhook timeout")
+
+	// kalıcı hata webhook'un cevap verdiğini gösterir
+	next.errs = []error{&application.PermanentError{StatusCode: 400, Err: errors.New("bad request")}}
+	_, _ = m.Send(ctx, msg)
+	assert.Equal(t, application.HealthUp, m.Health(ctx).Status)
+
+	h = application.NewWebhookMonitor(&openBreaker{}, time.Minute).Health(ctx)
+	assert.Equal(t, application.HealthDown, h.Status)
+	assert.Contains(t, h.Message, "circuit breaker is open")
+}
@@ -0,0 +1,45 @@
+package application_test
+
+import (
+	"regexp"
+	"testing"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkShortener_RewritesURLs(t *testing.T) {
+	s := application.NewLinkShortener("https://go.example.com/", 7)
+	out, links, err := s.Shorten("Sale: https://shop.insiderone.com/spring?x=1. Also www.insiderone.com, and https://go.example.com/l/keep")
+	require.NoError(t, err)
+
+	require.Len(t, links, 2)
+	assert.Equal(t, "https://shop.insiderone.com/spring?x=1", links[0].TargetURL)
+	assert.Equal(t, "https://www.insiderone.com", links[1].TargetURL)
+	assert.Len(t, links[0].Code, 7)
+	assert.NotEqual(t, links[0].Code, links[1].Code)
+
+	expected := regexp.MustCompile(`^Sale: https://go\.example\.com/l/\w{7}\. Also https://go\.example\.com/l/\w{7}, and https://go\.example\.com/l/keep$`)
+	assert.Regexp(t, expected, out)
+}
+
+func TestContentFilter_ChecksShortLinkTargets(t *testing.T) {
+	s := application.NewLinkShortener("https://go.example.com", 7)
+	f, err := application.NewContentFilter(&memPolicyRepo{rules: &entity.ContentRules{
+		AllowedDomains: []string{"insiderone.com"}, Action: entity.PolicyReject,
+	}}, s.Host())
+	require.NoError(t, err)
+
+	content, links, err := s.Shorten("see https://shop.insiderone.com")
+	require.NoError(t, err)
+	assert.Nil(t, f.Check(&entity.Message{Content: content, Links: links}))
+
+	content, links, err = s.Shorten("see https://evil.example.org")
+	require.NoError(t, err)
+	v := f.Check(&entity.Message{Content: content, Links: links})
+	require.NotNil(t, v)
+	assert.Equal(t, "url_domain", v.Rule)
+}
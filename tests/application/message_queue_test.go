@@ -0,0 +1,74 @@
+package application_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeQueue süreç içi kuyruk; ack anında mesajın repository'deki durumunu kaydeder
+type fakeQueue struct {
+	repo *memRepo
+	ids  []uint
+
+	mu    sync.Mutex
+	acked map[uint]entity.MessageStatus
+}
+
+func (q *fakeQueue) Claim(ctx context.Context, limit int) ([]*entity.Message, error) {
+	var out []*entity.Message
+	for len(q.ids) > 0 && len(out) < limit {
+		cp := *q.repo.msgs[q.ids[0]]
+		out = append(out, &cp)
+		q.ids = q.ids[1:]
+	}
+	return out, nil
+}
+
+func (q *fakeQueue) Ack(ctx context.Context, id uint) error {
+	q.repo.mu.Lock()
+	status := q.repo.msgs[id].Status
+	q.repo.mu.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.acked[id] = status
+	return nil
+}
+
+func TestRun_ClaimsFromQueueAndAcksAfterMarkSent(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"), msg("+905552222222"), msg("+905553333333"))
+	// kuyruk sadece 3 ve 1'i verir, veritabanında bekleyen 2 alınmaz
+	q := &fakeQueue{repo: repo, ids: []uint{3, 1}, acked: map[uint]entity.MessageStatus{}}
+	uc := application.NewSendBatchUseCase(repo, &stubSender{}, nil, testConfig(), application.WithQueue(q))
+
+	res, err := uc.Run(context.Background(), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, res.Sent)
+	assert.Equal(t, map[uint]entity.MessageStatus{1: entity.StatusSent, 3: entity.StatusSent}, q.acked)
+	assert.Equal(t, entity.StatusPending, repo.msgs[2].Status)
+}
+
+func TestRun_AcksUnsentMessagesWhenHalted(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"), msg("+905552222222"), msg("+905553333333"))
+	q := &fakeQueue{repo: repo, ids: []uint{1, 2, 3}, acked: map[uint]entity.MessageStatus{}}
+	snd := &stubSender{errs: []error{application.ErrCircuitOpen}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig(), application.WithQueue(q))
+
+	res, err := uc.Run(context.Background(), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, res.Fetched)
+	assert.Equal(t, 0, res.Sent)
+	// gönderilmeyen mesajlar da ack edilir ki kuyruğa geri dönebilsinler
+	assert.Len(t, q.acked, 3)
+	for _, status := range q.acked {
+		assert.Equal(t, entity.StatusPending, status)
+	}
+}
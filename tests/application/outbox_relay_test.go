@@ -0,0 +1,167 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memOutbox struct {
+	events    []*entity.OutboxEvent
+	published map[uint]bool
+	failed    map[uint]string
+	dead      map[uint]bool
+}
+
+func newMemOutbox(n int) *memOutbox {
+	o := &memOutbox{published: map[uint]bool{}, failed: map[uint]string{}, dead: map[uint]bool{}}
+	for i := 1; i <= n; i++ {
+		o.events = append(o.events, &entity.OutboxEvent{ID: uint(i), Type: entity.EventMessageCreated, MessageID: uint(i)})
+	}
+	return o
+}
+
+func (o *memOutbox) Pending(limit int) ([]*entity.OutboxEvent, error) {
+	var out []*entity.OutboxEvent
+	for _, e := range o.events {
+		if !o.published[e.ID] && !o.dead[e.ID] && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (o *memOutbox) MarkPublished(ids []uint) error {
+	for _, id := range ids {
+		o.published[id] = true
+	}
+	return nil
+}
+
+func (o *memOutbox) MarkFailed(id uint, reason string) error {
+	o.failed[id] = reason
+	o.events[id-1].Attempts++
+	return nil
+}
+
+func (o *memOutbox) MarkDead(id uint, reason string) error {
+	o.MarkFailed(id, reason)
+	o.dead[id] = true
+	return nil
+}
+
+func (o *memOutbox) DeadCount() (int64, error) { return int64(len(o.dead)), nil }
+
+func (o *memOutbox) PurgePublished(before time.Time) (int64, error) { return 0, nil }
+
+type recordingSink struct {
+	name   string
+	got    []uint
+	failAt uint
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Publish(ctx context.Context, e *entity.OutboxEvent) error {
+	if e.ID == s.failAt {
+		return errors.New("unavailable")
+	}
+	s.got = append(s.got, e.ID)
+	return nil
+}
+
+func TestOutboxRelay_PublishesInOrderToAllSinks(t *testing.T) {
+	outbox := newMemOutbox(5)
+	a, b := &recordingSink{name: "a"}, &recordingSink{name: "b"}
+	cfg := testConfig()
+	cfg.OutboxBatchSize = 3
+	relay := application.NewOutboxRelay(outbox, []application.OutboxSink{a, b}, cfg, nil)
+
+	n, err := relay.Relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	n, err = relay.Relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	assert.Equal(t, []uint{1, 2, 3, 4, 5}, a.got)
+	assert.Equal(t, []uint{1, 2, 3, 4, 5}, b.got)
+	assert.Len(t, outbox.published, 5)
+}
+
+func TestOutboxRelay_StopsAtFailedEventAndRetries(t *testing.T) {
+	outbox := newMemOutbox(4)
+	a, b := &recordingSink{name: "a"}, &recordingSink{name: "b", failAt: 2}
+	cfg := testConfig()
+	cfg.OutboxBatchSize = 10
+	relay := application.NewOutboxRelay(outbox, []application.OutboxSink{a, b}, cfg, nil)
+
+	n, err := relay.Relay(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "b sink: unavailable")
+	assert.Equal(t, 1, n)
+	assert.Equal(t, map[uint]bool{1: true}, outbox.published)
+	assert.Equal(t, 1, outbox.events[1].Attempts)
+	assert.Equal(t, []uint{1}, b.got, "later events wait so order is kept")
+
+	// sink düzelince olay bütün sink'lere tekrar gönderilir (en az bir kez)
+	b.failAt = 0
+	n, err = relay.Relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []uint{1, 2, 2, 3, 4}, a.got)
+	assert.Equal(t, []uint{1, 2, 3, 4}, b.got)
+}
+
+func TestOutboxRelay_DeadLettersPoisonEvent(t *testing.T) {
+	outbox := newMemOutbox(3)
+	sink := &recordingSink{name: "a", failAt: 1}
+	cfg := testConfig()
+	cfg.OutboxBatchSize = 10
+	cfg.OutboxMaxAttempts = 2
+	relay := application.NewOutboxRelay(outbox, []application.OutboxSink{sink}, cfg, nil)
+
+	_, err := relay.Relay(context.Background())
+	require.Error(t, err)
+	assert.Empty(t, sink.got)
+	assert.Equal(t, application.HealthUp, relay.Health(context.Background()).Status)
+
+	// deneme hakkı biten olay ayrılır, sonraki olaylar yayınlanır
+	n, err := relay.Relay(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []uint{2, 3}, sink.got)
+	assert.True(t, outbox.dead[1])
+	assert.Equal(t, 2, outbox.events[0].Attempts)
+
+	h := relay.Health(context.Background())
+	assert.Equal(t, application.HealthDegraded, h.Status)
+	assert.Contains(t, h.Message, "1 outbox events dead-lettered")
+
+	n, err = relay.Relay(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestOutboxRelay_InactiveInstanceDoesNotPublish(t *testing.T) {
+	outbox := newMemOutbox(2)
+	sink := &recordingSink{name: "a"}
+	cfg := testConfig()
+	cfg.OutboxBatchSize = 10
+	cfg.OutboxRetentionHours = 1
+	relay := application.NewOutboxRelay(outbox, []application.OutboxSink{sink}, cfg, func() bool { return false })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	relay.Run(ctx, 5*time.Millisecond)
+
+	assert.Empty(t, sink.got)
+	assert.Empty(t, outbox.published)
+}
@@ -0,0 +1,71 @@
+package application_test
+
+import (
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func quietConfig(t *testing.T) *config.Config {
+	byCategory, err := config.ParseQuietWindows("marketing=21:00-09:00")
+	require.NoError(t, err)
+	return &config.Config{QuietHoursByCategory: byCategory, QuietHoursDefaultTZ: "Europe/Istanbul"}
+}
+
+func TestQuietHours_DefersMarketingUntilLocalMorning(t *testing.T) {
+	q, err := application.NewQuietHours(quietConfig(t))
+	require.NoError(t, err)
+	m := &entity.Message{To: "+905551111111", Priority: entity.PriorityNormal, Category: entity.CategoryMarketing}
+
+	// 23:30 İstanbul = 20:30 UTC
+	now := time.Date(2024, 1, 1, 20, 30, 0, 0, time.UTC)
+	ok, until := q.Check(m, now)
+
+	assert.False(t, ok)
+	assert.Equal(t, time.Date(2024, 1, 2, 6, 0, 0, 0, time.UTC), until.UTC())
+}
+
+func TestQuietHours_UsesRecipientTimeZone(t *testing.T) {
+	q, err := application.NewQuietHours(quietConfig(t))
+	require.NoError(t, err)
+	m := &entity.Message{To: "+819012345678", Priority: entity.PriorityNormal, Category: entity.CategoryMarketing}
+
+	// 12:00 UTC İstanbul'da öğlen ama Tokyo'da 21:00
+	ok, until := q.Check(m, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC))
+
+	assert.False(t, ok)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), until.UTC())
+}
+
+func TestQuietHours_MultiZoneCountryWaitsForAllZones(t *testing.T) {
+	q, err := application.NewQuietHours(quietConfig(t))
+	require.NoError(t, err)
+	m := &entity.Message{To: "+12125551234", Priority: entity.PriorityNormal, Category: entity.CategoryMarketing}
+
+	// 14:00 UTC: New York 09:00 ama Los Angeles 06:00
+	ok, until := q.Check(m, time.Date(2024, 1, 10, 14, 0, 0, 0, time.UTC))
+
+	assert.False(t, ok)
+	assert.Equal(t, time.Date(2024, 1, 10, 17, 0, 0, 0, time.UTC), until.UTC())
+}
+
+func TestQuietHours_TransactionalAndHighPriorityBypass(t *testing.T) {
+	cfg := quietConfig(t)
+	cfg.QuietHours = &config.QuietWindow{Start: 0, End: 23*60 + 59}
+	q, err := application.NewQuietHours(cfg)
+	require.NoError(t, err)
+	night := time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC)
+
+	ok, _ := q.Check(&entity.Message{To: "+905551111111", Priority: entity.PriorityNormal, Category: entity.CategoryTransactional}, night)
+	assert.True(t, ok)
+	ok, _ = q.Check(&entity.Message{To: "+905551111111", Priority: entity.PriorityHigh, Category: entity.CategoryMarketing}, night)
+	assert.True(t, ok)
+	ok, _ = q.Check(&entity.Message{To: "+905551111111", Priority: entity.PriorityNormal, Category: entity.CategoryGeneral}, night)
+	assert.False(t, ok, "global rule applies to general messages")
+}
@@ -0,0 +1,327 @@
+package application_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+/*
+	------------------------------
+	  IN-MEMORY REPOSITORY
+
+--------------------------------
+*/
+type memRepo struct {
+	mu       sync.Mutex
+	msgs     map[uint]*entity.Message
+	attempts []*entity.Attempt
+}
+
+func newMemRepo(msgs ...*entity.Message) *memRepo {
+	r := &memRepo{msgs: map[uint]*entity.Message{}}
+	for i, m := range msgs {
+		m.ID = uint(i + 1)
+		m.Status = entity.StatusPending
+		r.msgs[m.ID] = m
+	}
+	return r
+}
+
+func (r *memRepo) Create(msg *entity.Message) error {
+	msg.ID = uint(len(r.msgs) + 1)
+	r.msgs[msg.ID] = msg
+	return nil
+}
+
+func (r *memRepo) GetUnsent(limit int) ([]*entity.Message, error) {
+	var out []*entity.Message
+	now := time.Now()
+	for id := uint(1); id <= uint(len(r.msgs)) && len(out) < limit; id++ {
+		m := r.msgs[id]
+		if m.Status != entity.StatusPending || (m.NextAttemptAt != nil && m.NextAttemptAt.After(now)) {
+			continue
+		}
+		cp := *m
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (r *memRepo) GetDue(ids []uint) ([]*entity.Message, error) {
+	var out []*entity.Message
+	now := time.Now()
+	for _, id := range ids {
+		m, ok := r.msgs[id]
+		if !ok || m.Status != entity.StatusPending || (m.NextAttemptAt != nil && m.NextAttemptAt.After(now)) {
+			continue
+		}
+		cp := *m
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (r *memRepo) MarkSent(id uint, wid string, source entity.MessageIDSource) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.msgs[id].MarkSent(wid, source)
+	return nil
+}
+
+func (r *memRepo) ListSent(f repository.MessageFilter) ([]*entity.Message, error) { return nil, nil }
+
+func (r *memRepo) RecordAttempt(a *entity.Attempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts = append(r.attempts, a)
+	r.msgs[a.MessageID].Attempts++
+	r.msgs[a.MessageID].LastError = a.Error
+	return nil
+}
+
+func (r *memRepo) Defer(id uint, until time.Time) error {
+	r.msgs[id].NextAttemptAt = &until
+	return nil
+}
+
+func (r *memRepo) MarkFailed(id uint, reason string) error {
+	r.msgs[id].Status = entity.StatusFailed
+	r.msgs[id].LastError = reason
+	return nil
+}
+func (r *memRepo) SentSince(since time.Time) ([]*entity.Message, error) { return nil, nil }
+func (r *memRepo) PurgeBefore(before time.Time) (int64, error)          { return 0, nil }
+func (r *memRepo) QueueStats() (repository.QueueStats, error)           { return repository.QueueStats{}, nil }
+
+func (r *memRepo) Quarantine(id uint, reason string) error {
+	r.msgs[id].Status = entity.StatusQuarantined
+	r.msgs[id].LastError = reason
+	return nil
+}
+
+/*
+	------------------------------
+	  STUB SENDER
+
+--------------------------------
+*/
+type stubSender struct {
+	errs  []error
+	calls int
+}
+
+func (s *stubSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		if err != nil {
+			return application.SendResult{}, err
+		}
+	}
+	return application.SendResult{MessageID: "webhook-ok", MessageIDSource: entity.MessageIDProvider}, nil
+}
+
+func testConfig() *config.Config {
+	return &config.Config{
+		MsgCharLimit:     160,
+		MsgPerTick:       10,
+		MaxSendAttempts:  3,
+		RetryBaseSeconds: 30,
+		RetryMaxSeconds:  600,
+	}
+}
+
+func msg(to string) *entity.Message {
+	return &entity.Message{To: to, Content: "hi"}
+}
+
+/* ------------------------------
+     TESTS
+--------------------------------*/
+
+func TestExecute_PermanentErrorFailsImmediately(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"))
+	snd := &stubSender{errs: []error{&application.PermanentError{StatusCode: 400, Body: "invalid number", Err: errors.New("bad status: 400")}}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig())
+
+	require.NoError(t, uc.Execute(context.Background()))
+
+	assert.Equal(t, entity.StatusFailed, repo.msgs[1].Status)
+	require.Len(t, repo.attempts, 1)
+	assert.Equal(t, entity.AttemptFailed, repo.attempts[0].Outcome)
+	assert.Equal(t, 400, repo.attempts[0].StatusCode)
+	assert.Equal(t, "invalid number", repo.attempts[0].ResponseBody)
+}
+
+func TestExecute_RetryableErrorDefers(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"))
+	snd := &stubSender{errs: []error{&application.RetryableError{StatusCode: 503, Err: errors.New("bad status: 503")}}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig())
+
+	before := time.Now()
+	require.NoError(t, uc.Execute(context.Background()))
+
+	m := repo.msgs[1]
+	assert.Equal(t, entity.StatusPending, m.Status)
+	require.NotNil(t, m.NextAttemptAt)
+	assert.True(t, m.NextAttemptAt.After(before.Add(29*time.Second)))
+	assert.Equal(t, entity.AttemptRetry, repo.attempts[0].Outcome)
+}
+
+func TestExecute_RetryAfterOverridesBackoff(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"), msg("+905552222222"))
+	snd := &stubSender{errs: []error{&application.RetryableError{StatusCode: 429, RetryAfter: 5 * time.Minute, Err: errors.New("bad status: 429")}}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig())
+
+	before := time.Now()
+	require.NoError(t, uc.Execute(context.Background()))
+
+	assert.True(t, repo.msgs[1].NextAttemptAt.After(before.Add(4*time.Minute)))
+	assert.Equal(t, 1, snd.calls, "rate limited batch must stop")
+	assert.Equal(t, 0, repo.msgs[2].Attempts)
+}
+
+func TestExecute_FailsAfterMaxAttempts(t *testing.T) {
+	m := msg("+905551111111")
+	repo := newMemRepo(m)
+	repo.msgs[1].Attempts = 2
+	snd := &stubSender{errs: []error{&application.TimeoutError{Err: context.DeadlineExceeded}}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig())
+
+	require.NoError(t, uc.Execute(context.Background()))
+
+	assert.Equal(t, entity.StatusFailed, repo.msgs[1].Status)
+	assert.Equal(t, 3, repo.attempts[0].Number)
+}
+
+func TestExecute_CircuitOpenDoesNotCountAttempt(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"))
+	snd := &stubSender{errs: []error{application.ErrCircuitOpen}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig())
+
+	require.NoError(t, uc.Execute(context.Background()))
+
+	assert.Equal(t, entity.StatusPending, repo.msgs[1].Status)
+	assert.Equal(t, 0, repo.msgs[1].Attempts)
+	assert.Empty(t, repo.attempts)
+}
+
+type fakeLimiter struct {
+	blocked  map[string]bool
+	recorded []string
+	released []string
+}
+
+func (l *fakeLimiter) Allow(ctx context.Context, m *entity.Message) (bool, time.Time, error) {
+	if l.blocked[m.To] {
+		return false, time.Now().Add(time.Hour), nil
+	}
+	return true, time.Time{}, nil
+}
+
+func (l *fakeLimiter) Record(ctx context.Context, m *entity.Message) error {
+	l.recorded = append(l.recorded, m.To)
+	return nil
+}
+
+func (l *fakeLimiter) Release(ctx context.Context, m *entity.Message) error {
+	l.released = append(l.released, m.To)
+	return nil
+}
+
+func (l *fakeLimiter) Counters(ctx context.Context, to string) ([]application.RecipientCounter, error) {
+	return nil, nil
+}
+
+func TestExecute_RecipientLimitDefersWithoutAttempt(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"), msg("+905552222222"))
+	limiter := &fakeLimiter{blocked: map[string]bool{"+905551111111": true}}
+	snd := &stubSender{}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig(), application.WithRecipientLimiter(limiter))
+
+	require.NoError(t, uc.Execute(context.Background()))
+
+	throttled := repo.msgs[1]
+	assert.Equal(t, entity.StatusPending, throttled.Status)
+	assert.Equal(t, 0, throttled.Attempts)
+	require.NotNil(t, throttled.NextAttemptAt)
+	assert.True(t, throttled.NextAttemptAt.After(time.Now().Add(59*time.Minute)))
+
+	assert.Equal(t, entity.StatusSent, repo.msgs[2].Status)
+	assert.Equal(t, 1, snd.calls)
+	assert.Equal(t, []string{"+905552222222"}, limiter.recorded)
+	assert.Empty(t, limiter.released)
+}
+
+func TestExecute_FailedSendReleasesRecipientSlot(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"), msg("+905552222222"))
+	limiter := &fakeLimiter{}
+	snd := &stubSender{errs: []error{&application.RetryableError{StatusCode: 503, Err: errors.New("unavailable")}}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig(), application.WithRecipientLimiter(limiter))
+
+	require.NoError(t, uc.Execute(context.Background()))
+
+	assert.Equal(t, []string{"+905551111111"}, limiter.released)
+	assert.Equal(t, []string{"+905552222222"}, limiter.recorded)
+}
+
+func TestRun_ReportsOutcomes(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"), msg("+905552222222"), msg("+905553333333"))
+	snd := &stubSender{errs: []error{
+		nil,
+		&application.PermanentError{StatusCode: 400, Err: errors.New("bad status: 400")},
+		&application.RetryableError{StatusCode: 503, Err: errors.New("bad status: 503")},
+	}}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig())
+
+	res, err := uc.Run(context.Background(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, application.BatchResult{BatchSize: 2, Sizing: application.BatchSizingManual, Fetched: 2, Sent: 1, Failed: 1}, res)
+
+	res, err = uc.Run(context.Background(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, application.BatchResult{BatchSize: 10, Sizing: config.BatchSizingFixed, Fetched: 1, Retried: 1}, res)
+}
+
+// cancellingSender ilk gönderimde batch'i iptal eder, kapanışta dolan boşaltma süresi gibi
+type cancellingSender struct {
+	cancel context.CancelFunc
+	calls  int
+}
+
+func (s *cancellingSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	s.calls++
+	s.cancel()
+	return application.SendResult{}, &application.TimeoutError{Err: ctx.Err()}
+}
+
+func TestRun_CancelledBatchLeavesMessagesPending(t *testing.T) {
+	repo := newMemRepo(msg("+905551111111"), msg("+905552222222"))
+	ctx, cancel := context.WithCancel(context.Background())
+	snd := &cancellingSender{cancel: cancel}
+	uc := application.NewSendBatchUseCase(repo, snd, nil, testConfig())
+
+	res, err := uc.Run(ctx, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, res.Fetched)
+	assert.Zero(t, res.Sent+res.Failed+res.Retried+res.Skipped)
+	assert.Equal(t, 1, snd.calls)
+	assert.Empty(t, repo.attempts, "a cancelled send is not an attempt")
+	for _, m := range repo.msgs {
+		assert.Equal(t, entity.StatusPending, m.Status)
+		assert.Nil(t, m.NextAttemptAt)
+	}
+}
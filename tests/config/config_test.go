@@ -0,0 +1,40 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"insider-messaging/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	limits, err := config.ParseRateLimits("low=5/1h, normal=20/30m,high=0/1m")
+	require.NoError(t, err)
+	assert.Equal(t, config.RateLimit{Max: 5, Window: time.Hour}, limits["low"])
+	assert.Equal(t, config.RateLimit{Max: 20, Window: 30 * time.Minute}, limits["normal"])
+	assert.Equal(t, 0, limits["high"].Max)
+}
+
+func TestParseRateLimits_Invalid(t *testing.T) {
+	for _, spec := range []string{"low", "low=5", "low=x/1h", "low=5/soon", "low=5/10ms"} {
+		_, err := config.ParseRateLimits(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestParseSchedules(t *testing.T) {
+	got, err := config.ParseSchedules("send=send-batch:*/30 * * * * *; cleanup = retention : CRON_TZ=Europe/Istanbul 0 3 * * *;")
+	require.NoError(t, err)
+	assert.Equal(t, []config.ScheduleSpec{
+		{Name: "send", Job: "send-batch", Spec: "*/30 * * * * *"},
+		{Name: "cleanup", Job: "retention", Spec: "CRON_TZ=Europe/Istanbul 0 3 * * *"},
+	}, got)
+
+	for _, spec := range []string{";", "send", "send=send-batch", "=send-batch:@daily", "a=x:@daily;a=y:@hourly"} {
+		_, err := config.ParseSchedules(spec)
+		assert.Error(t, err, spec)
+	}
+}
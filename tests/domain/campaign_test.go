@@ -0,0 +1,24 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"insider-messaging/internal/domain/entity"
+)
+
+func TestCampaign_Transition(t *testing.T) {
+	c, err := entity.NewCampaign("  spring  ", "team", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "spring", c.Name)
+	assert.Equal(t, entity.CampaignActive, c.Status)
+
+	require.NoError(t, c.Transition(entity.CampaignPaused))
+	require.NoError(t, c.Transition(entity.CampaignActive))
+	require.NoError(t, c.Transition(entity.CampaignCancelled))
+	assert.ErrorIs(t, c.Transition(entity.CampaignActive), entity.ErrInvalidTransition)
+
+	_, err = entity.NewCampaign(" ", "", nil, nil)
+	assert.Error(t, err)
+}
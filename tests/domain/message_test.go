@@ -12,3 +12,17 @@ func TestNewMessage_Truncate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "hello", m.Content)
 }
+
+func TestMessage_MetadataAndTags(t *testing.T) {
+	m, err := entity.NewMessage("+905551111111", "hello", 160)
+	assert.NoError(t, err)
+
+	assert.NoError(t, m.SetTags([]string{" VIP ", "vip", "order"}, 2))
+	assert.Equal(t, []string{"order", "vip"}, m.Tags)
+	assert.Error(t, m.SetTags([]string{"a", "b", "c"}, 2))
+	assert.Error(t, m.SetTags([]string{"has space"}, 2))
+
+	assert.NoError(t, m.SetMetadata(map[string]string{"orderId": "42"}, 64))
+	assert.Error(t, m.SetMetadata(map[string]string{"bad key": "x"}, 64))
+	assert.Error(t, m.SetMetadata(map[string]string{"note": string(make([]byte, 100))}, 64))
+}
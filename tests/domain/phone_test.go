@@ -0,0 +1,64 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"insider-messaging/internal/domain/phone"
+)
+
+func TestPhoneParse_NormalizesNationalFormats(t *testing.T) {
+	cases := []struct {
+		raw, region, e164, country string
+		typ                        phone.Type
+	}{
+		{"0555 111 11 11", "TR", "+905551111111", "TR", phone.TypeMobile},
+		{"(0212) 555-12-34", "TR", "+902125551234", "TR", phone.TypeFixedLine},
+		{"+90 555 111 11 11", "", "+905551111111", "TR", phone.TypeMobile},
+		{"0090 555 111 11 11", "DE", "+905551111111", "TR", phone.TypeMobile},
+		{"07700 900123", "GB", "+447700900123", "GB", phone.TypeMobile},
+		{"1 (212) 555-1234", "US", "+12125551234", "US", phone.TypeFixedLineOrMobile},
+		{"+1 416 555 1234", "", "+14165551234", "CA", phone.TypeFixedLineOrMobile},
+		{"0800 123 45 67", "TR", "+908001234567", "TR", phone.TypeTollFree},
+		{"+7 916 123 45 67", "", "+79161234567", "RU", phone.TypeMobile},
+		{"+7 701 123 45 67", "", "+77011234567", "KZ", phone.TypeMobile},
+		{"8 727 123 45 67", "KZ", "+77271234567", "KZ", phone.TypeFixedLine},
+		{"8 701 123 45 67", "RU", "+77011234567", "KZ", phone.TypeMobile},
+		{"8 800 123 45 67", "RU", "+78001234567", "RU", phone.TypeTollFree},
+		// önek rakamıyla başlayan numara önek olmadan yazılmış
+		{"800 123 45 67", "RU", "+78001234567", "RU", phone.TypeTollFree},
+	}
+	for _, c := range cases {
+		n, err := phone.Parse(c.raw, c.region)
+		require.NoError(t, err, c.raw)
+		assert.Equal(t, c.e164, n.E164, c.raw)
+		assert.Equal(t, c.country, n.Region, c.raw)
+		assert.Equal(t, c.typ, n.Type, c.raw)
+	}
+}
+
+func TestPhoneParse_RejectsInvalidNumbers(t *testing.T) {
+	for _, raw := range []string{
+		"+900000000000",
+		"+9055511111",
+		"+90555111111122",
+		"+12125551234x",
+		"+1 012 555 1234",
+		"abc",
+	} {
+		_, err := phone.Parse(raw, "TR")
+		assert.Error(t, err, raw)
+	}
+
+	_, err := phone.Parse("0555 111 11 11", "")
+	assert.ErrorIs(t, err, phone.ErrNoRegion)
+}
+
+func TestPhoneParse_UnknownCountryKeepsE164(t *testing.T) {
+	n, err := phone.Parse("+2348031234567", "TR")
+	require.NoError(t, err)
+	assert.Equal(t, "+2348031234567", n.E164)
+	assert.Empty(t, n.Region)
+	assert.Equal(t, phone.TypeUnknown, n.Type)
+}
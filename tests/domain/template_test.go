@@ -0,0 +1,59 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"insider-messaging/internal/domain/entity"
+)
+
+func shippedTemplate() *entity.Template {
+	return &entity.Template{
+		Name:          "order-shipped",
+		DefaultLocale: "en",
+		Variants: []entity.TemplateVariant{
+			{Locale: "en", Body: "Hi {{name}}, order {{ orderId }} has shipped"},
+			{Locale: "tr", Body: "Merhaba {{name}}, {{orderId}} numaralı siparişiniz yola çıktı"},
+		},
+	}
+}
+
+func TestTemplate_RenderLocaleFallback(t *testing.T) {
+	tpl := shippedTemplate()
+	require.NoError(t, tpl.Validate())
+	vars := map[string]string{"name": "Ayşe", "orderId": "42"}
+
+	out, err := tpl.Render("tr_TR", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "Merhaba Ayşe, 42 numaralı siparişiniz yola çıktı", out)
+
+	out, err = tpl.Render("de", vars)
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Ayşe, order 42 has shipped", out)
+}
+
+func TestTemplate_RenderMissingVariables(t *testing.T) {
+	tpl := shippedTemplate()
+	require.NoError(t, tpl.Validate())
+
+	_, err := tpl.Render("en", map[string]string{"name": "Ayşe"})
+	var missing *entity.MissingVariablesError
+	require.ErrorAs(t, err, &missing)
+	assert.Equal(t, []string{"orderId"}, missing.Names)
+}
+
+func TestTemplate_Validate(t *testing.T) {
+	tpl := shippedTemplate()
+	tpl.DefaultLocale = "fr"
+	assert.Error(t, tpl.Validate())
+
+	tpl = shippedTemplate()
+	tpl.Variants[1].Locale = "EN"
+	assert.Error(t, tpl.Validate(), "duplicate locale after normalization")
+
+	tpl = shippedTemplate()
+	tpl.DefaultLocale = ""
+	require.NoError(t, tpl.Validate())
+	assert.Equal(t, "en", tpl.DefaultLocale)
+}
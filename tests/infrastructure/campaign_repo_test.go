@@ -0,0 +1,86 @@
+package infra_test
+
+import (
+	"testing"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func campaignMessages(t *testing.T, n int) []*entity.Message {
+	msgs := make([]*entity.Message, n)
+	for i := range msgs {
+		m, err := entity.NewMessage("+905551111111", "campaign message", 160)
+		require.NoError(t, err)
+		msgs[i] = m
+	}
+	return msgs
+}
+
+func TestMySQLCampaignRepository_PauseSkipsMessages(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	repo := db.NewMySQLCampaignRepository(testDB)
+
+	single, err := entity.NewMessage("+905552222222", "standalone", 160)
+	require.NoError(t, err)
+	require.NoError(t, msgRepo.Create(single))
+
+	c, err := entity.NewCampaign("spring", "tester", nil, nil)
+	require.NoError(t, err)
+	msgs := campaignMessages(t, 2)
+	require.NoError(t, repo.Create(c, msgs))
+	require.NotZero(t, c.ID)
+	assert.Equal(t, c.ID, *msgs[0].CampaignID)
+	assert.NotZero(t, msgs[1].ID)
+
+	unsent, err := msgRepo.GetUnsent(10)
+	require.NoError(t, err)
+	assert.Len(t, unsent, 3)
+
+	require.NoError(t, repo.SetStatus(c.ID, entity.CampaignActive, entity.CampaignPaused))
+	unsent, err = msgRepo.GetUnsent(10)
+	require.NoError(t, err)
+	require.Len(t, unsent, 1)
+	assert.Equal(t, single.ID, unsent[0].ID)
+
+	require.NoError(t, repo.SetStatus(c.ID, entity.CampaignPaused, entity.CampaignActive))
+	unsent, err = msgRepo.GetUnsent(10)
+	require.NoError(t, err)
+	assert.Len(t, unsent, 3)
+
+	// durum okunduktan sonra değiştiyse geçiş uygulanmaz
+	assert.ErrorIs(t, repo.SetStatus(c.ID, entity.CampaignPaused, entity.CampaignCancelled), repository.ErrStatusChanged)
+	got, err := repo.Get(c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.CampaignActive, got.Status)
+	assert.ErrorIs(t, repo.SetStatus(c.ID+1, entity.CampaignActive, entity.CampaignPaused), repository.ErrNotFound)
+}
+
+func TestMySQLCampaignRepository_ScheduleAndCancel(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	repo := db.NewMySQLCampaignRepository(testDB)
+
+	later := time.Now().Add(time.Hour)
+	c, err := entity.NewCampaign("later", "", &later, nil)
+	require.NoError(t, err)
+	msgs := campaignMessages(t, 3)
+	require.NoError(t, repo.Create(c, msgs))
+
+	unsent, err := msgRepo.GetUnsent(10)
+	require.NoError(t, err)
+	assert.Empty(t, unsent, "scheduled campaign must wait")
+
+	require.NoError(t, msgRepo.MarkSent(msgs[0].ID, "wh-1", entity.MessageIDProvider))
+	require.NoError(t, repo.SetStatus(c.ID, entity.CampaignActive, entity.CampaignCancelled))
+
+	stats, err := repo.Stats(c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.CampaignStats{Total: 3, Sent: 1, Cancelled: 2}, stats)
+}
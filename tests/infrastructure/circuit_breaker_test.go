@@ -0,0 +1,171 @@
+package infra_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/sender"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSender struct {
+	calls int
+	err   error
+}
+
+func (s *stubSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	s.calls++
+	if s.err != nil {
+		return application.SendResult{}, s.err
+	}
+	return application.SendResult{MessageID: "webhook-1", MessageIDSource: entity.MessageIDProvider}, nil
+}
+
+func breakerConfig() *config.Config {
+	return &config.Config{
+		CircuitFailureRatio:    0.5,
+		CircuitMinRequests:     2,
+		CircuitWindowSize:      4,
+		CircuitCooldownSeconds: 1,
+		CircuitHalfOpenProbes:  1,
+	}
+}
+
+func TestCircuitBreaker_OpensAfterFailures(t *testing.T) {
+	stub := &stubSender{err: errors.New("bad status: 503")}
+	cb := sender.NewCircuitBreakerSender(stub, breakerConfig())
+	msg := &entity.Message{ID: 1, To: "+905551111111", Content: "hi"}
+
+	_, _ = cb.Send(context.Background(), msg)
+	_, _ = cb.Send(context.Background(), msg)
+	assert.Equal(t, application.CircuitOpen, cb.Status().State)
+
+	_, err := cb.Send(context.Background(), msg)
+	assert.ErrorIs(t, err, application.ErrCircuitOpen)
+	assert.Equal(t, 2, stub.calls, "open breaker must not reach the webhook")
+}
+
+func TestCircuitBreaker_HalfOpenRecovers(t *testing.T) {
+	stub := &stubSender{err: errors.New("bad status: 503")}
+	cb := sender.NewCircuitBreakerSender(stub, breakerConfig())
+	msg := &entity.Message{ID: 1, To: "+905551111111", Content: "hi"}
+
+	_, _ = cb.Send(context.Background(), msg)
+	_, _ = cb.Send(context.Background(), msg)
+	assert.Equal(t, application.CircuitOpen, cb.Status().State)
+
+	time.Sleep(1100 * time.Millisecond)
+	stub.err = nil
+
+	res, err := cb.Send(context.Background(), msg)
+	assert.NoError(t, err)
+	assert.Equal(t, "webhook-1", res.MessageID)
+	assert.Equal(t, application.CircuitClosed, cb.Status().State)
+	assert.Equal(t, 0, cb.Status().Requests)
+}
+
+func TestCircuitBreaker_IgnoresCanceledContext(t *testing.T) {
+	stub := &stubSender{err: context.Canceled}
+	cb := sender.NewCircuitBreakerSender(stub, breakerConfig())
+	msg := &entity.Message{ID: 1, To: "+905551111111", Content: "hi"}
+
+	for i := 0; i < 4; i++ {
+		_, _ = cb.Send(context.Background(), msg)
+	}
+	assert.Equal(t, application.CircuitClosed, cb.Status().State)
+	assert.Equal(t, 0, cb.Status().Failures)
+}
+
+func TestCircuitBreaker_HalfOpenNeutralProbeDoesNotClose(t *testing.T) {
+	stub := &stubSender{err: errors.New("bad status: 503")}
+	cb := sender.NewCircuitBreakerSender(stub, breakerConfig())
+	msg := &entity.Message{ID: 1, To: "+905551111111", Content: "hi"}
+
+	_, _ = cb.Send(context.Background(), msg)
+	_, _ = cb.Send(context.Background(), msg)
+	require.Equal(t, application.CircuitOpen, cb.Status().State)
+	time.Sleep(1100 * time.Millisecond)
+
+	// iptal edilen veya kalıcı reddedilen deneme webhook'un düzeldiğini göstermez,
+	// deneme hakkı geri verilir
+	stub.err = context.Canceled
+	_, _ = cb.Send(context.Background(), msg)
+	assert.Equal(t, application.CircuitHalfOpen, cb.Status().State)
+	stub.err = &application.PermanentError{StatusCode: 400, Err: errors.New("bad request")}
+	_, _ = cb.Send(context.Background(), msg)
+	assert.Equal(t, application.CircuitHalfOpen, cb.Status().State)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stub.err = &application.TimeoutError{Err: context.DeadlineExceeded}
+	_, _ = cb.Send(ctx, msg)
+	assert.Equal(t, application.CircuitHalfOpen, cb.Status().State)
+
+	stub.err = nil
+	_, err := cb.Send(context.Background(), msg)
+	assert.NoError(t, err)
+	assert.Equal(t, application.CircuitClosed, cb.Status().State)
+	assert.Equal(t, 6, stub.calls)
+}
+
+// heldSender held'deki mesajları sonucu kanala yazılana kadar bekletir, diğerlerini 503 ile reddeder
+type heldSender struct {
+	held    map[uint]chan error
+	started chan uint
+}
+
+func (s *heldSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	ch, ok := s.held[m.ID]
+	if !ok {
+		return application.SendResult{}, errors.New("bad status: 503")
+	}
+	s.started <- m.ID
+	return application.SendResult{}, <-ch
+}
+
+func TestCircuitBreaker_IgnoresStragglersFromEarlierState(t *testing.T) {
+	stub := &heldSender{held: map[uint]chan error{1: make(chan error), 2: make(chan error), 3: make(chan error)}, started: make(chan uint, 3)}
+	cb := sender.NewCircuitBreakerSender(stub, breakerConfig())
+	send := func(id uint) chan struct{} {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_, _ = cb.Send(context.Background(), &entity.Message{ID: id, To: "+905551111111", Content: "hi"})
+		}()
+		return done
+	}
+
+	// 1 ve 2 breaker açılmadan başlar, sonuçları açıldıktan sonra gelir
+	first, second := send(1), send(2)
+	<-stub.started
+	<-stub.started
+	_, _ = cb.Send(context.Background(), &entity.Message{ID: 4})
+	_, _ = cb.Send(context.Background(), &entity.Message{ID: 5})
+	require.Equal(t, application.CircuitOpen, cb.Status().State)
+	openedAt := *cb.Status().OpenedAt
+
+	time.Sleep(10 * time.Millisecond)
+	stub.held[1] <- errors.New("bad status: 503")
+	<-first
+	assert.Equal(t, application.CircuitOpen, cb.Status().State)
+	assert.Equal(t, openedAt, *cb.Status().OpenedAt, "straggler must not push the cooldown out")
+
+	// yarı açık durumda eski isteğin başarısı deneme sonucu sayılmaz
+	time.Sleep(1100 * time.Millisecond)
+	probe := send(3)
+	<-stub.started
+	stub.held[2] <- nil
+	<-second
+	assert.Equal(t, application.CircuitHalfOpen, cb.Status().State)
+
+	stub.held[3] <- nil
+	<-probe
+	assert.Equal(t, application.CircuitClosed, cb.Status().State)
+}
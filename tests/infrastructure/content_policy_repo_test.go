@@ -0,0 +1,32 @@
+package infra_test
+
+import (
+	"testing"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLContentPolicyRepository_SaveIncrementsVersion(t *testing.T) {
+	repo := db.NewMySQLContentPolicyRepository(setupTestDB(t))
+
+	rules, err := repo.Load()
+	require.NoError(t, err)
+	assert.Nil(t, rules)
+
+	in := &entity.ContentRules{BlockedKeywords: []string{"casino"}, MaxURLs: 2, Action: entity.PolicyQuarantine}
+	require.NoError(t, repo.Save(in))
+	assert.Equal(t, int64(1), in.Version)
+	require.NoError(t, repo.Save(in))
+	assert.Equal(t, int64(2), in.Version)
+
+	rules, err = repo.Load()
+	require.NoError(t, err)
+	require.NotNil(t, rules)
+	assert.Equal(t, []string{"casino"}, rules.BlockedKeywords)
+	assert.Equal(t, entity.PolicyQuarantine, rules.Action)
+	assert.Equal(t, int64(2), rules.Version)
+}
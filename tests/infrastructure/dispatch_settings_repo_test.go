@@ -0,0 +1,32 @@
+package infra_test
+
+import (
+	"testing"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLDispatchSettingsRepository_SaveIncrementsVersion(t *testing.T) {
+	repo := db.NewMySQLDispatchSettingsRepository(setupTestDB(t))
+
+	s, err := repo.Load()
+	require.NoError(t, err)
+	assert.Nil(t, s)
+
+	in := &entity.DispatchSettings{IntervalSeconds: 60, BatchSize: 20, Concurrency: 2}
+	require.NoError(t, repo.Save(in))
+	assert.Equal(t, int64(1), in.Version)
+	in.BatchSize = 40
+	require.NoError(t, repo.Save(in))
+
+	got, err := repo.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.Version)
+	assert.Equal(t, 40, got.BatchSize)
+	assert.Equal(t, 60, got.IntervalSeconds)
+	assert.False(t, got.UpdatedAt.IsZero())
+}
@@ -0,0 +1,326 @@
+package infra_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+	"insider-messaging/internal/infrastructure/db"
+	"insider-messaging/internal/infrastructure/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memLock süreli liderlik kilidinin bellekteki karşılığı
+type memLock struct {
+	mu      sync.Mutex
+	owner   string
+	expires time.Time
+}
+
+func (l *memLock) Acquire(ctx context.Context, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owner != "" && l.owner != owner && time.Now().Before(l.expires) {
+		return false, nil
+	}
+	l.owner, l.expires = owner, time.Now().Add(ttl)
+	return true, nil
+}
+
+func (l *memLock) Release(ctx context.Context, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owner == owner {
+		l.owner = ""
+	}
+	return nil
+}
+
+func TestCluster_SingleLeaderAndHandover(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	state := db.NewMySQLSchedulerStateRepository(testDB)
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1h"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, &stubSender{}, nil, cfg)
+	lock := &memLock{}
+
+	newNode := func() (*scheduler.Scheduler, *scheduler.Cluster, context.CancelFunc, chan struct{}) {
+		s, err := scheduler.NewScheduler(uc, cfg)
+		require.NoError(t, err)
+		c := scheduler.NewCluster(s, lock, state, 3*time.Second)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Run(ctx)
+		}()
+		return s, c, cancel, done
+	}
+	sA, cA, cancelA, doneA := newNode()
+	sB, cB, cancelB, doneB := newNode()
+	defer func() {
+		cancelB()
+		<-doneB
+	}()
+
+	require.Eventually(t, func() bool { return cA.IsLeader() != cB.IsLeader() }, 3*time.Second, 20*time.Millisecond)
+	leaderS, leaderC, cancelLeader, doneLeader := sA, cA, cancelA, doneA
+	followerS, followerC := sB, cB
+	if cB.IsLeader() {
+		leaderS, leaderC, cancelLeader, doneLeader = sB, cB, cancelB, doneB
+		followerS, followerC = sA, cA
+		defer func() {
+			cancelA()
+			<-doneA
+		}()
+	}
+
+	// başlatma hangi instance'a gelirse gelsin sadece lider çalıştırır
+	require.NoError(t, followerC.Start(application.Actor{Name: "ops"}))
+	assert.True(t, followerC.IsRunning())
+	require.Eventually(t, leaderS.IsRunning, 3*time.Second, 20*time.Millisecond)
+	assert.False(t, followerS.IsRunning())
+
+	st := followerC.Status()
+	assert.True(t, st.Running)
+	assert.Equal(t, "ops", st.StartedBy)
+	assert.Equal(t, leaderC.Status().Instance, st.Leader)
+	assert.NotEqual(t, st.Instance, st.Leader)
+
+	_, err = followerC.TriggerRun("ops", 0)
+	assert.ErrorIs(t, err, application.ErrNotLeader)
+
+	// lider kapanınca kilidi bırakır, diğeri devralıp istenen durumu uygular
+	cancelLeader()
+	<-doneLeader
+	assert.False(t, leaderS.IsRunning())
+	require.Eventually(t, followerC.IsLeader, 3*time.Second, 20*time.Millisecond)
+	require.Eventually(t, followerS.IsRunning, 3*time.Second, 20*time.Millisecond)
+
+	require.NoError(t, followerC.Stop(application.Actor{Name: "ops"}))
+	require.Eventually(t, func() bool { return !followerS.IsRunning() }, 3*time.Second, 20*time.Millisecond)
+	assert.Equal(t, "ops", followerC.Status().StoppedBy)
+}
+
+func TestCluster_LeaderExpiryHandsOver(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	state := db.NewMySQLSchedulerStateRepository(testDB)
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1h"}}}
+	uc := application.NewSendBatchUseCase(db.NewMySQLMessageRepository(testDB), &stubSender{}, nil, cfg)
+
+	// ölen bir liderin bıraktığı, süresi dolmak üzere olan kilit
+	lock := &memLock{owner: "dead-pod/000000", expires: time.Now().Add(1500 * time.Millisecond)}
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+	c := scheduler.NewCluster(s, lock, state, 3*time.Second)
+	require.NoError(t, c.Start(application.Actor{Name: "ops"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	assert.Never(t, c.IsLeader, time.Second, 50*time.Millisecond)
+	require.Eventually(t, c.IsLeader, 3*time.Second, 50*time.Millisecond)
+	require.Eventually(t, s.IsRunning, time.Second, 20*time.Millisecond)
+	loaded, err := state.Load()
+	require.NoError(t, err)
+	assert.True(t, loaded.Running)
+	assert.Equal(t, c.Status().Instance, loaded.Leader)
+}
+
+func TestCluster_RestoresStateAndAudits(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	state := db.NewMySQLSchedulerStateRepository(testDB)
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1h"}}}
+	uc := application.NewSendBatchUseCase(db.NewMySQLMessageRepository(testDB), &stubSender{}, nil, cfg)
+
+	// kilit verilmeyen tek instance her zaman liderdir
+	boot := func(autoStart bool) (*scheduler.Scheduler, *scheduler.Cluster, func()) {
+		s, err := scheduler.NewScheduler(uc, cfg)
+		require.NoError(t, err)
+		c := scheduler.NewCluster(s, nil, state, 3*time.Second)
+		require.NoError(t, c.Bootstrap(autoStart))
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			c.Run(ctx)
+		}()
+		return s, c, func() {
+			cancel()
+			<-done
+		}
+	}
+
+	// ilk açılışta AUTO_START uygulanır
+	s, c, shutdown := boot(true)
+	require.Eventually(t, s.IsRunning, time.Second, 20*time.Millisecond)
+	assert.True(t, c.IsLeader())
+	require.NoError(t, c.Stop(application.Actor{Name: "ops", APIKey: "api-key:1a2b3c4d"}))
+	require.Eventually(t, func() bool { return !s.IsRunning() }, time.Second, 20*time.Millisecond)
+	shutdown()
+
+	// restart sonrası kayıtlı durum AUTO_START'a göre önceliklidir
+	s, c, shutdown = boot(true)
+	defer shutdown()
+	assert.Never(t, s.IsRunning, 500*time.Millisecond, 50*time.Millisecond)
+	assert.Equal(t, "ops (api-key:1a2b3c4d)", c.Status().StoppedBy)
+	require.NoError(t, c.Start(application.Actor{Name: "deploy-bot", APIKey: "api-key:1a2b3c4d"}))
+	require.Eventually(t, s.IsRunning, time.Second, 20*time.Millisecond)
+
+	changes, err := state.Changes(10)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	assert.True(t, changes[0].Running)
+	assert.Equal(t, "deploy-bot", changes[0].Actor)
+	assert.Equal(t, "api-key:1a2b3c4d", changes[0].APIKey)
+	assert.False(t, changes[1].Running)
+	assert.Equal(t, "ops", changes[1].Actor)
+	assert.Equal(t, "AUTO_START", changes[2].Actor)
+	assert.NotEmpty(t, changes[2].Instance)
+}
+
+func TestCluster_HealthReportsStalledLoop(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	state := &stallingState{SchedulerStateRepository: db.NewMySQLSchedulerStateRepository(testDB), release: make(chan struct{})}
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1h"}}}
+	uc := application.NewSendBatchUseCase(db.NewMySQLMessageRepository(testDB), &stubSender{}, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+	c := scheduler.NewCluster(s, nil, state, 90*time.Millisecond)
+
+	// döngü başlamadan up sayılır
+	assert.Equal(t, application.HealthUp, c.Health(context.Background()).Status)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+	require.Eventually(t, c.IsLeader, time.Second, 10*time.Millisecond)
+	h := c.Health(context.Background())
+	assert.Equal(t, application.HealthUp, h.Status)
+	assert.Contains(t, h.Message, "leader=true")
+
+	// döngü takılınca 3 ttl sonra down olur
+	state.stall.Store(true)
+	require.Eventually(t, func() bool {
+		return c.Health(context.Background()).Status == application.HealthDown
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.Contains(t, c.Health(context.Background()).Message, "has not run")
+
+	// kapanışta boşaltma için duran döngü liveness'ı düşürmez
+	state.stall.Store(false)
+	close(state.release)
+	cancel()
+	<-done
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, application.HealthUp, c.Health(context.Background()).Status)
+}
+
+// stallingState stall açıkken Load'da release kapanana kadar takılır
+type stallingState struct {
+	repository.SchedulerStateRepository
+	stall   atomic.Bool
+	release chan struct{}
+}
+
+func (s *stallingState) Load() (*entity.SchedulerState, error) {
+	if s.stall.Load() {
+		<-s.release
+	}
+	return s.SchedulerStateRepository.Load()
+}
+
+func TestCluster_StopRenewsLockAndLossAbortsBatch(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	m, err := entity.NewMessage("+905551111111", "hi", 160)
+	require.NoError(t, err)
+	require.NoError(t, msgRepo.Create(m))
+	state := db.NewMySQLSchedulerStateRepository(testDB)
+	snd := &blockingSender{started: make(chan struct{})}
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 30,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1s"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, snd, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+	lock := &memLock{}
+	ttl := 300 * time.Millisecond
+	c := scheduler.NewCluster(s, lock, state, ttl)
+	require.NoError(t, c.Start(application.Actor{Name: "ops"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.Run(ctx)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+	select {
+	case <-snd.started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("scheduled batch did not start")
+	}
+
+	// durdurma süren batch'i beklerken döngü kilidi yenilemeye devam eder
+	require.NoError(t, c.Stop(application.Actor{Name: "ops"}))
+	require.Eventually(t, func() bool { return !s.IsRunning() }, time.Second, 20*time.Millisecond)
+	time.Sleep(3 * ttl)
+	lock.mu.Lock()
+	owner, expires := lock.owner, lock.expires
+	lock.mu.Unlock()
+	assert.Equal(t, c.Status().Instance, owner)
+	assert.True(t, expires.After(time.Now()))
+	assert.Empty(t, s.Status().RecentRuns, "batch is still in flight")
+	assert.Equal(t, application.HealthUp, c.Health(context.Background()).Status)
+
+	// kilit başka bir instance'a geçince süren batch beklenmeden iptal edilir
+	lock.mu.Lock()
+	lock.owner, lock.expires = "other-pod/000000", time.Now().Add(time.Hour)
+	lock.mu.Unlock()
+	require.Eventually(t, func() bool { return !c.IsLeader() }, 2*time.Second, 20*time.Millisecond)
+	require.Eventually(t, func() bool { return len(s.Status().RecentRuns) == 1 }, 2*time.Second, 20*time.Millisecond)
+	unsent, err := msgRepo.GetUnsent(10)
+	require.NoError(t, err)
+	require.Len(t, unsent, 1)
+	assert.Equal(t, int32(1), snd.calls.Load())
+}
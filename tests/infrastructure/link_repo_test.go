@@ -0,0 +1,55 @@
+package infra_test
+
+import (
+	"testing"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLLinkRepository_ClicksPerMessageAndCampaign(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	campaigns := db.NewMySQLCampaignRepository(testDB)
+	links := db.NewMySQLLinkRepository(testDB)
+
+	c, err := entity.NewCampaign("spring", "", nil, nil)
+	require.NoError(t, err)
+	msgs := campaignMessages(t, 2)
+	msgs[0].Links = []entity.Link{{Code: "abc1234", TargetURL: "https://insiderone.com/a"}}
+	msgs[1].Links = []entity.Link{{Code: "def5678", TargetURL: "https://insiderone.com/a"}}
+	require.NoError(t, campaigns.Create(c, msgs))
+	require.NotZero(t, msgs[0].Links[0].ID)
+	require.NoError(t, msgRepo.MarkSent(msgs[0].ID, "wh-1", entity.MessageIDProvider))
+	require.NoError(t, msgRepo.MarkSent(msgs[1].ID, "wh-2", entity.MessageIDProvider))
+
+	link, err := links.FindByCode("abc1234")
+	require.NoError(t, err)
+	assert.Equal(t, msgs[0].ID, link.MessageID)
+	_, err = links.FindByCode("nope")
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, links.RecordClick(&entity.Click{LinkID: link.ID, MessageID: link.MessageID, UserAgent: "test"}))
+	}
+
+	stats, err := links.MessageClicks(msgs[0].ID)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, int64(3), stats[0].Clicks)
+	assert.NotNil(t, stats[0].FirstClickAt)
+
+	cs, err := links.CampaignClicks(c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.CampaignClickStats{
+		CampaignID: c.ID, Clicks: 3, ClickedMessages: 1, SentMessages: 2, ClickThroughRate: 0.5,
+	}, cs)
+
+	unsent, err := msgRepo.GetUnsent(10)
+	require.NoError(t, err)
+	assert.Empty(t, unsent)
+}
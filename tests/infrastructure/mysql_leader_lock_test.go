@@ -0,0 +1,67 @@
+package infra_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+)
+
+// TestMySQLLeaderLock_CancelledCallsDoNotLeakLock gerçek bir MySQL ister:
+// TEST_MYSQL_DSN="user:pass@tcp(localhost:3306)/test" go test ./tests/infrastructure/...
+func TestMySQLLeaderLock_CancelledCallsDoNotLeakLock(t *testing.T) {
+	dsn := os.Getenv("TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("TEST_MYSQL_DSN not set")
+	}
+	open := func() *gorm.DB {
+		gdb, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+		require.NoError(t, err)
+		sqlDB, err := gdb.DB()
+		require.NoError(t, err)
+		t.Cleanup(func() { sqlDB.Close() })
+		return gdb
+	}
+	name := fmt.Sprintf("test-leader-%d", time.Now().UnixNano())
+	// ayrı havuzlar: a'nın havuza iade ettiği oturum kilidi tutuyorsa b alamaz
+	a := db.NewMySQLLeaderLock(open(), name)
+	b := db.NewMySQLLeaderLock(open(), name)
+	ctx := context.Background()
+
+	takeOver := func(msg string) {
+		require.Eventually(t, func() bool {
+			ok, err := b.Acquire(ctx, "b", time.Minute)
+			return err == nil && ok
+		}, 5*time.Second, 20*time.Millisecond, msg)
+		require.NoError(t, b.Release(ctx, "b"))
+	}
+
+	// ctx, GET_LOCK'un herhangi bir aşamasında iptal edilir
+	for d := 50 * time.Microsecond; d < 100*time.Millisecond; d *= 2 {
+		cctx, cancel := context.WithTimeout(ctx, d)
+		ok, err := a.Acquire(cctx, "a", time.Minute)
+		cancel()
+		if err == nil && ok {
+			require.NoError(t, a.Release(ctx, "a"))
+			continue
+		}
+		takeOver(fmt.Sprintf("acquire cancelled after %s left the lock held", d))
+	}
+
+	// kapanışta RELEASE_LOCK başarısız olsa da kilit bırakılır
+	ok, err := a.Acquire(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	require.True(t, ok)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, a.Release(cancelled, "a"))
+	takeOver("failed release left the lock held")
+}
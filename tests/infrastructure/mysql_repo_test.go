@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
 	"insider-messaging/internal/infrastructure/db"
 
 	"github.com/stretchr/testify/assert"
@@ -88,7 +89,7 @@ func TestMySQLMessageRepository_MarkSent(t *testing.T) {
 	msg, _ := entity.NewMessage("+905551111111", "Test message", 160)
 	require.NoError(t, repo.Create(msg))
 
-	err := repo.MarkSent(msg.ID, "webhook-123")
+	err := repo.MarkSent(msg.ID, "webhook-123", entity.MessageIDProvider)
 	assert.NoError(t, err)
 
 	// Verify it's marked as sent
@@ -97,11 +98,12 @@ func TestMySQLMessageRepository_MarkSent(t *testing.T) {
 	assert.Len(t, unsent, 0)
 
 	// Check sent messages
-	sent, err := repo.ListSent()
+	sent, err := repo.ListSent(repository.MessageFilter{})
 	require.NoError(t, err)
 	assert.Len(t, sent, 1)
 	assert.True(t, sent[0].Sent)
 	assert.Equal(t, "webhook-123", sent[0].WebhookMsgID)
+	assert.Equal(t, entity.MessageIDProvider, sent[0].WebhookMsgIDSource)
 	assert.NotNil(t, sent[0].SentAt)
 }
 
@@ -115,11 +117,11 @@ func TestMySQLMessageRepository_ListSent(t *testing.T) {
 	require.NoError(t, repo.Create(msg1))
 	require.NoError(t, repo.Create(msg2))
 
-	require.NoError(t, repo.MarkSent(msg1.ID, "webhook-1"))
+	require.NoError(t, repo.MarkSent(msg1.ID, "webhook-1", entity.MessageIDProvider))
 	time.Sleep(10 * time.Millisecond) // Ensure different timestamps
-	require.NoError(t, repo.MarkSent(msg2.ID, "webhook-2"))
+	require.NoError(t, repo.MarkSent(msg2.ID, "webhook-2", entity.MessageIDProvider))
 
-	sent, err := repo.ListSent()
+	sent, err := repo.ListSent(repository.MessageFilter{})
 	require.NoError(t, err)
 	assert.Len(t, sent, 2)
 	assert.True(t, sent[0].Sent)
@@ -134,3 +136,75 @@ func TestMySQLMessageRepository_Create_EmptyMessage(t *testing.T) {
 	require.Error(t, err)
 	assert.Nil(t, msg)
 }
+
+func TestMySQLMessageRepository_ListSentFilters(t *testing.T) {
+	testDB := setupTestDB(t)
+	repo := db.NewMySQLMessageRepository(testDB)
+
+	vip, err := entity.NewMessage("+905551111111", "vip order", 160)
+	require.NoError(t, err)
+	require.NoError(t, vip.SetMetadata(map[string]string{"orderId": "42", "userId": "7"}, 1024))
+	require.NoError(t, vip.SetTags([]string{"VIP", "order"}, 10))
+	require.NoError(t, repo.Create(vip))
+
+	plain, err := entity.NewMessage("+905552222222", "plain", 160)
+	require.NoError(t, err)
+	require.NoError(t, plain.SetMetadata(map[string]string{"orderId": "43"}, 1024))
+	require.NoError(t, repo.Create(plain))
+
+	require.NoError(t, repo.MarkSent(vip.ID, "wh-1", entity.MessageIDProvider))
+	require.NoError(t, repo.MarkSent(plain.ID, "wh-2", entity.MessageIDProvider))
+
+	byTag, err := repo.ListSent(repository.MessageFilter{Tag: "vip"})
+	require.NoError(t, err)
+	require.Len(t, byTag, 1)
+	assert.Equal(t, []string{"order", "vip"}, byTag[0].Tags)
+	assert.Equal(t, "7", byTag[0].Metadata["userId"])
+
+	byKey, err := repo.ListSent(repository.MessageFilter{MetadataKey: "orderId"})
+	require.NoError(t, err)
+	assert.Len(t, byKey, 2)
+
+	byValue, err := repo.ListSent(repository.MessageFilter{MetadataKey: "orderId", MetadataValue: "43"})
+	require.NoError(t, err)
+	require.Len(t, byValue, 1)
+	assert.Equal(t, plain.ID, byValue[0].ID)
+}
+
+func TestMySQLMessageRepository_PurgeBefore(t *testing.T) {
+	testDB := setupTestDB(t)
+	repo := db.NewMySQLMessageRepository(testDB)
+
+	old, _ := entity.NewMessage("+905551111111", "old", 160)
+	recent, _ := entity.NewMessage("+905552222222", "recent", 160)
+	pending, _ := entity.NewMessage("+905553333333", "pending", 160)
+	old.Tags = []string{"vip"}
+	for _, m := range []*entity.Message{old, recent, pending} {
+		require.NoError(t, repo.Create(m))
+	}
+	require.NoError(t, repo.MarkSent(old.ID, "wh-1", entity.MessageIDProvider))
+	require.NoError(t, repo.MarkSent(recent.ID, "wh-2", entity.MessageIDProvider))
+	require.NoError(t, repo.RecordAttempt(&entity.Attempt{MessageID: old.ID, Number: 1, Outcome: entity.AttemptSent}))
+	require.NoError(t, testDB.Table("message_models").Where("id = ?", old.ID).
+		Update("sent_at", time.Now().Add(-100*24*time.Hour)).Error)
+
+	since, err := repo.SentSince(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, since, 1)
+	assert.Equal(t, recent.ID, since[0].ID)
+
+	n, err := repo.PurgeBefore(time.Now().Add(-90 * 24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	sent, err := repo.ListSent(repository.MessageFilter{})
+	require.NoError(t, err)
+	require.Len(t, sent, 1)
+	assert.Equal(t, recent.ID, sent[0].ID)
+	var attempts int64
+	testDB.Table("message_attempt_models").Count(&attempts)
+	assert.Zero(t, attempts)
+	unsent, err := repo.GetUnsent(10)
+	require.NoError(t, err)
+	assert.Len(t, unsent, 1)
+}
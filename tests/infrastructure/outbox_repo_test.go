@@ -0,0 +1,155 @@
+package infra_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func eventTypes(events []*entity.OutboxEvent) []entity.OutboxEventType {
+	types := make([]entity.OutboxEventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestOutbox_WrittenWithMessageChanges(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB, db.WithOutbox())
+	outbox := db.NewMySQLOutboxRepository(testDB)
+
+	for _, to := range []string{"+905551111111", "+905552222222", "+905553333333"} {
+		m, _ := entity.NewMessage(to, "hi", 160)
+		m.Tags = []string{"otp"}
+		m.Metadata = map[string]string{"orderId": to[len(to)-1:]}
+		require.NoError(t, msgRepo.Create(m))
+	}
+	require.NoError(t, msgRepo.MarkSent(1, "wh-1", entity.MessageIDProvider))
+	require.NoError(t, msgRepo.MarkFailed(2, "invalid number"))
+	require.NoError(t, msgRepo.Quarantine(3, "blocked word"))
+	// olmayan mesaj için olay yazılmaz
+	require.NoError(t, msgRepo.MarkSent(99, "wh-99", entity.MessageIDProvider))
+
+	events, err := outbox.Pending(10)
+	require.NoError(t, err)
+	assert.Equal(t, []entity.OutboxEventType{
+		entity.EventMessageCreated, entity.EventMessageCreated, entity.EventMessageCreated,
+		entity.EventMessageSent, entity.EventMessageFailed, entity.EventMessageQuarantined,
+	}, eventTypes(events))
+
+	var created entity.MessageEvent
+	require.NoError(t, json.Unmarshal(events[0].Payload, &created))
+	assert.Equal(t, uint(1), created.MessageID)
+	assert.Equal(t, "+905551111111", created.To)
+	assert.Equal(t, entity.StatusPending, created.Status)
+	assert.Equal(t, []string{"otp"}, created.Tags)
+
+	var sent entity.MessageEvent
+	require.NoError(t, json.Unmarshal(events[3].Payload, &sent))
+	assert.Equal(t, entity.StatusSent, sent.Status)
+	assert.Equal(t, "wh-1", sent.WebhookMsgID)
+	assert.Equal(t, uint(2), events[4].MessageID)
+
+	// durum olayları tüketicinin eşleştirebilmesi için mesajın alanlarını taşır
+	for i, to := range []string{"+905551111111", "+905552222222", "+905553333333"} {
+		var e entity.MessageEvent
+		require.NoError(t, json.Unmarshal(events[3+i].Payload, &e))
+		assert.Equal(t, uint(i+1), e.MessageID)
+		assert.Equal(t, to, e.To)
+		assert.Equal(t, []string{"otp"}, e.Tags)
+		assert.Equal(t, map[string]string{"orderId": to[len(to)-1:]}, e.Metadata)
+		assert.Equal(t, entity.CategoryGeneral, e.Category)
+	}
+}
+
+func TestOutbox_CampaignCreateAndCancel(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB, db.WithOutbox())
+	repo := db.NewMySQLCampaignRepository(testDB, db.WithOutbox())
+	outbox := db.NewMySQLOutboxRepository(testDB)
+
+	c, err := entity.NewCampaign("spring", "tester", nil, nil)
+	require.NoError(t, err)
+	msgs := campaignMessages(t, 3)
+	require.NoError(t, repo.Create(c, msgs))
+	require.NoError(t, msgRepo.MarkSent(msgs[0].ID, "wh-1", entity.MessageIDProvider))
+	require.NoError(t, repo.SetStatus(c.ID, entity.CampaignActive, entity.CampaignCancelled))
+
+	events, err := outbox.Pending(10)
+	require.NoError(t, err)
+	require.Equal(t, []entity.OutboxEventType{
+		entity.EventMessageCreated, entity.EventMessageCreated, entity.EventMessageCreated,
+		entity.EventMessageSent, entity.EventMessageCancelled, entity.EventMessageCancelled,
+	}, eventTypes(events))
+	assert.Equal(t, msgs[1].ID, events[4].MessageID)
+	assert.Equal(t, msgs[2].ID, events[5].MessageID)
+	var cancelled entity.MessageEvent
+	require.NoError(t, json.Unmarshal(events[5].Payload, &cancelled))
+	assert.Equal(t, entity.StatusCancelled, cancelled.Status)
+	assert.Equal(t, &c.ID, cancelled.CampaignID)
+	assert.Equal(t, "+905551111111", cancelled.To)
+
+	stats, err := repo.Stats(c.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Cancelled)
+}
+
+func TestOutbox_DisabledWritesNothing(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	outbox := db.NewMySQLOutboxRepository(testDB)
+
+	m, _ := entity.NewMessage("+905551111111", "hi", 160)
+	require.NoError(t, msgRepo.Create(m))
+	require.NoError(t, msgRepo.MarkSent(m.ID, "wh-1", entity.MessageIDProvider))
+
+	events, err := outbox.Pending(10)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestOutbox_PublishFailAndPurge(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB, db.WithOutbox())
+	outbox := db.NewMySQLOutboxRepository(testDB)
+	for i := 0; i < 3; i++ {
+		m, _ := entity.NewMessage("+905551111111", "hi", 160)
+		require.NoError(t, msgRepo.Create(m))
+	}
+	events, err := outbox.Pending(10)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	require.NoError(t, outbox.MarkPublished([]uint{events[0].ID, events[1].ID}))
+	require.NoError(t, outbox.MarkFailed(events[2].ID, "sink down"))
+	pending, err := outbox.Pending(10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, 1, pending[0].Attempts)
+	assert.Equal(t, "sink down", pending[0].LastError)
+
+	n, err := outbox.PurgePublished(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = outbox.PurgePublished(time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	pending, err = outbox.Pending(10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 1, "unpublished events are never purged")
+
+	require.NoError(t, outbox.MarkDead(events[2].ID, "rejected"))
+	pending, err = outbox.Pending(10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	dead, err := outbox.DeadCount()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), dead)
+}
@@ -0,0 +1,79 @@
+package infra_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/cache"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecipientThrottle_ConcurrentReservations gerçek bir Redis ister:
+// TEST_REDIS_ADDR=localhost:6379 go test ./tests/infrastructure/...
+func TestRecipientThrottle_ConcurrentReservations(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR not set")
+	}
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	require.NoError(t, rdb.Ping(ctx).Err())
+	to := fmt.Sprintf("+90555%07d", time.Now().UnixNano()%10000000)
+	t.Cleanup(func() {
+		keys, _ := rdb.Keys(ctx, "throttle:recipient:"+to+":*").Result()
+		rdb.Del(ctx, keys...)
+		rdb.Close()
+	})
+
+	throttle := cache.NewRecipientThrottle(rdb, map[string]config.RateLimit{
+		string(entity.PriorityNormal): {Max: 3, Window: time.Hour},
+	})
+	// aynı anda çalışan worker'lar limitten fazla yer alamaz
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 1; i <= 20; i++ {
+		wg.Add(1)
+		go func(id uint) {
+			defer wg.Done()
+			ok, _, err := throttle.Allow(ctx, &entity.Message{ID: id, To: to, Priority: entity.PriorityNormal})
+			assert.NoError(t, err)
+			if ok {
+				allowed.Add(1)
+			}
+		}(uint(i))
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), allowed.Load())
+
+	counters, err := throttle.Counters(ctx, to)
+	require.NoError(t, err)
+	require.Len(t, counters, 1)
+	assert.Equal(t, int64(3), counters[0].Count)
+
+	// gönderilmeyen mesajın yeri geri verilir
+	m := &entity.Message{ID: 100, To: to, Priority: entity.PriorityNormal}
+	ok, resetsAt, err := throttle.Allow(ctx, m)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.True(t, resetsAt.After(time.Now()))
+	for i := uint(1); i <= 20; i++ {
+		require.NoError(t, throttle.Release(ctx, &entity.Message{ID: i, To: to}))
+	}
+	ok, _, err = throttle.Allow(ctx, m)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	require.NoError(t, throttle.Record(ctx, m))
+	counters, err = throttle.Counters(ctx, to)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), counters[0].Count)
+}
@@ -0,0 +1,468 @@
+package infra_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/db"
+	"insider-messaging/internal/infrastructure/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustTime(t *testing.T, layout, value string, loc *time.Location) time.Time {
+	tm, err := time.ParseInLocation(layout, value, loc)
+	require.NoError(t, err)
+	return tm
+}
+
+func TestParseCron_Next(t *testing.T) {
+	const layout = "2006-01-02 15:04:05"
+	from := mustTime(t, layout, "2024-01-31 10:00:30", time.UTC)
+	cases := []struct {
+		spec, want string
+	}{
+		{"*/15 * * * * *", "2024-01-31 10:00:45"},
+		{"0 */5 * * * *", "2024-01-31 10:05:00"},
+		{"30 9 * * *", "2024-02-01 09:30:00"},
+		{"0 0 1 * *", "2024-02-01 00:00:00"},
+		{"0 12 * * MON-FRI", "2024-01-31 12:00:00"},
+		{"0 0 * * 7", "2024-02-04 00:00:00"},
+		{"0 0 29 FEB *", "2024-02-29 00:00:00"},
+		{"0 0 13 * 5", "2024-02-02 00:00:00"},
+		{"@hourly", "2024-01-31 11:00:00"},
+		{"@every 90s", "2024-01-31 10:02:00"},
+	}
+	for _, c := range cases {
+		s, err := scheduler.ParseCron(c.spec)
+		require.NoError(t, err, c.spec)
+		assert.Equal(t, c.want, s.Next(from).UTC().Format(layout), c.spec)
+	}
+}
+
+func TestParseCron_TimeZone(t *testing.T) {
+	ist, err := time.LoadLocation("Europe/Istanbul")
+	require.NoError(t, err)
+	s, err := scheduler.ParseCron("CRON_TZ=Europe/Istanbul 0 3 * * *")
+	require.NoError(t, err)
+
+	next := s.Next(time.Date(2024, 6, 1, 1, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2024, 6, 2, 3, 0, 0, 0, ist).Unix(), next.Unix())
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "61 * * * *", "* * 0 * *", "5-1 * * * *", "*/0 * * * *", "@often", "@every 10ms", "CRON_TZ=Mars/Base * * * * *"} {
+		_, err := scheduler.ParseCron(spec)
+		assert.Error(t, err, spec)
+	}
+	s, err := scheduler.ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestScheduler_RunsNamedSchedules(t *testing.T) {
+	var runs int32
+	cfg := &config.Config{Schedules: []config.ScheduleSpec{
+		{Name: "fast-cleanup", Job: "cleanup", Spec: "@every 1s"},
+		{Name: "nightly", Job: "cleanup", Spec: "0 0 3 * * *"},
+	}}
+	s, err := scheduler.NewScheduler(nil, cfg, scheduler.WithJob("cleanup", time.Second, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}))
+	require.NoError(t, err)
+
+	infos := s.Schedules()
+	require.Len(t, infos, 2)
+	assert.Nil(t, infos[0].NextRunAt)
+
+	s.Start(application.Actor{Name: "test"})
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 1 }, 3*time.Second, 50*time.Millisecond)
+	infos = s.Schedules()
+	require.NotNil(t, infos[1].NextRunAt)
+	assert.Equal(t, 3, infos[1].NextRunAt.Hour())
+	assert.NotNil(t, infos[0].LastRunAt)
+	s.Stop(application.Actor{Name: "test"})
+
+	assert.False(t, s.IsRunning())
+	assert.Nil(t, s.Schedules()[1].NextRunAt)
+}
+
+func TestScheduler_RejectsUnknownJobAndBadSpec(t *testing.T) {
+	_, err := scheduler.NewScheduler(nil, &config.Config{Schedules: []config.ScheduleSpec{{Name: "x", Job: "missing", Spec: "@daily"}}})
+	assert.ErrorContains(t, err, "unknown job")
+
+	_, err = scheduler.NewScheduler(nil, &config.Config{Schedules: []config.ScheduleSpec{{Name: "x", Job: scheduler.JobSendBatch, Spec: "* *"}}})
+	assert.ErrorContains(t, err, `schedule "x"`)
+}
+
+func TestScheduler_RescheduleResetsTimer(t *testing.T) {
+	var runs int32
+	cfg := &config.Config{Schedules: []config.ScheduleSpec{{Name: "send", Job: "tick", Spec: "@every 1h"}}}
+	s, err := scheduler.NewScheduler(nil, cfg, scheduler.WithJob("tick", time.Second, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	}))
+	require.NoError(t, err)
+	s.Start(application.Actor{Name: "test"})
+	defer s.Stop(application.Actor{Name: "test"})
+
+	require.NoError(t, s.Reschedule("send", "@every 1s"))
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 1 }, 3*time.Second, 50*time.Millisecond)
+	assert.Equal(t, "@every 1s", s.Schedules()[0].Spec)
+
+	assert.ErrorIs(t, s.Reschedule("missing", "@daily"), scheduler.ErrUnknownSchedule)
+	assert.Error(t, s.Reschedule("send", "bad"))
+}
+
+func TestScheduler_SetIntervalKeepsCustomSchedules(t *testing.T) {
+	// SCHEDULES ile verilen cron ifadesi gönderim aralığıyla ezilmez
+	custom := &config.Config{Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "0 */5 * * * *"}}}
+	s, err := scheduler.NewScheduler(nil, custom)
+	require.NoError(t, err)
+	assert.ErrorIs(t, s.SetInterval(30), scheduler.ErrNoIntervalSchedule)
+	assert.Equal(t, "0 */5 * * * *", s.Schedules()[0].Spec)
+
+	interval := &config.Config{Schedules: []config.ScheduleSpec{
+		{Name: "cleanup", Job: scheduler.JobRetention, Spec: "@daily"},
+		{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 120s", Interval: true},
+	}}
+	s, err = scheduler.NewScheduler(nil, interval, scheduler.WithJob(scheduler.JobRetention, time.Second, func(ctx context.Context) error { return nil }))
+	require.NoError(t, err)
+	require.NoError(t, s.SetInterval(30))
+	specs := map[string]string{}
+	for _, sc := range s.Schedules() {
+		specs[sc.Name] = sc.Spec
+	}
+	assert.Equal(t, map[string]string{"cleanup": "@daily", "send-batch": "@every 30s"}, specs)
+}
+
+func TestScheduler_StatusAndRunHistory(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	runs := db.NewMySQLSchedulerRunRepository(testDB)
+	for _, to := range []string{"+905551111111", "+905552222222"} {
+		m, err := entity.NewMessage(to, "hi", 160)
+		require.NoError(t, err)
+		require.NoError(t, msgRepo.Create(m))
+	}
+
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1s"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, &stubSender{}, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg, scheduler.WithRunHistory(runs, 3))
+	require.NoError(t, err)
+
+	s.Start(application.Actor{Name: "alice"})
+	assert.Eventually(t, func() bool { return s.Status().LastRun != nil }, 3*time.Second, 50*time.Millisecond)
+	st := s.Status()
+	assert.True(t, st.Running)
+	assert.Equal(t, "alice", st.StartedBy)
+	assert.NotNil(t, st.StartedAt)
+	assert.NotNil(t, st.NextRunAt)
+	assert.Equal(t, 2, st.LastRun.Sent)
+	assert.Equal(t, "send-batch", st.LastRun.Trigger)
+	assert.Equal(t, config.BatchSizingFixed, st.LastRun.Sizing)
+	s.Stop(application.Actor{Name: "bob"})
+
+	st = s.Status()
+	assert.False(t, st.Running)
+	assert.Equal(t, "bob", st.StoppedBy)
+	assert.Nil(t, st.NextRunAt)
+
+	// yeni instance geçmişi veritabanından yükler
+	restarted, err := scheduler.NewScheduler(uc, cfg, scheduler.WithRunHistory(runs, 3))
+	require.NoError(t, err)
+	require.NotNil(t, restarted.Status().LastRun)
+	assert.Equal(t, st.LastRun.ID, restarted.Status().LastRun.ID)
+	assert.Equal(t, config.BatchSizingFixed, restarted.Status().LastRun.Sizing)
+}
+
+func TestMySQLSchedulerRunRepository_KeepsRing(t *testing.T) {
+	runs := db.NewMySQLSchedulerRunRepository(setupTestDB(t))
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, runs.Record(&entity.SchedulerRun{Trigger: "send-batch", Sent: i, StartedAt: time.Now()}, 3))
+	}
+	recent, err := runs.Recent(10)
+	require.NoError(t, err)
+	require.Len(t, recent, 3)
+	assert.Equal(t, 5, recent[0].Sent)
+	assert.Equal(t, 3, recent[2].Sent)
+}
+
+// gateSender release kapanana kadar gönderimi bekletir
+type gateSender struct {
+	started chan struct{}
+	release chan struct{}
+	once    atomic.Bool
+}
+
+func (g *gateSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	if g.once.CompareAndSwap(false, true) {
+		close(g.started)
+	}
+	<-g.release
+	return application.SendResult{MessageID: "webhook-1", MessageIDSource: entity.MessageIDProvider}, nil
+}
+
+func TestScheduler_ManualRun(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	for _, to := range []string{"+905551111111", "+905552222222", "+905553333333"} {
+		m, err := entity.NewMessage(to, "hi", 160)
+		require.NoError(t, err)
+		require.NoError(t, msgRepo.Create(m))
+	}
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1h"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, &stubSender{}, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+
+	// scheduler durmuşken de çalışır ve istenen batch boyutunu kullanır
+	run, err := s.TriggerRun("alice", 2)
+	require.NoError(t, err)
+	assert.NotEmpty(t, run.ID)
+	run, ok := s.ManualRun(context.Background(), run.ID, true)
+	require.True(t, ok)
+	assert.Equal(t, application.ManualRunSucceeded, run.Status)
+	require.NotNil(t, run.Run)
+	assert.Equal(t, "manual", run.Run.Trigger)
+	assert.Equal(t, "alice", run.Run.RequestedBy)
+	assert.Equal(t, 2, run.Run.BatchSize)
+	assert.Equal(t, 2, run.Run.Sent)
+	assert.False(t, s.IsRunning())
+
+	_, ok = s.ManualRun(context.Background(), "missing", false)
+	assert.False(t, ok)
+}
+
+func TestScheduler_ManualRunWaitsForScheduledBatch(t *testing.T) {
+	testDB := setupTestDB(t)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	m, err := entity.NewMessage("+905551111111", "hi", 160)
+	require.NoError(t, err)
+	require.NoError(t, msgRepo.Create(m))
+
+	gate := &gateSender{started: make(chan struct{}), release: make(chan struct{})}
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1s"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, gate, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+
+	s.Start(application.Actor{Name: "test"})
+	defer s.Stop(application.Actor{Name: "test"})
+	select {
+	case <-gate.started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("scheduled batch did not start")
+	}
+
+	run, err := s.TriggerRun("bob", 0)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+	run, _ = s.ManualRun(context.Background(), run.ID, false)
+	assert.Equal(t, application.ManualRunQueued, run.Status)
+
+	close(gate.release)
+	run, _ = s.ManualRun(context.Background(), run.ID, true)
+	assert.Equal(t, application.ManualRunSucceeded, run.Status)
+	// zamanlanmış batch mesajı gönderdiği için elle çalışma boş kuyruk görür
+	assert.Equal(t, 0, run.Run.Fetched)
+}
+
+func TestScheduler_StreamDrainsOnSignal(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	create := func(n int) {
+		for i := 0; i < n; i++ {
+			m, err := entity.NewMessage("+905551111111", "otp", 160)
+			require.NoError(t, err)
+			require.NoError(t, msgRepo.Create(m))
+		}
+	}
+	create(5)
+
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 2, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1h"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, &stubSender{}, nil, cfg)
+	signal := application.NewDispatchSignal()
+	s, err := scheduler.NewScheduler(uc, cfg, scheduler.WithStream(signal.C()))
+	require.NoError(t, err)
+	assert.Equal(t, config.DispatchModeStream, s.Status().DispatchMode)
+
+	// başlarken birikmiş kuyruk dolu batch'lerle boşaltılır
+	s.Start(application.Actor{Name: "test"})
+	defer s.Stop(application.Actor{Name: "test"})
+	sentCount := func() int {
+		sent, err := msgRepo.SentSince(time.Time{})
+		require.NoError(t, err)
+		return len(sent)
+	}
+	require.Eventually(t, func() bool { return sentCount() == 5 }, 2*time.Second, 20*time.Millisecond)
+
+	// yeni mesaj zamanlamayı beklemeden gönderilir
+	create(1)
+	signal.Notify()
+	require.Eventually(t, func() bool { return sentCount() == 6 }, 2*time.Second, 20*time.Millisecond)
+
+	// boş kuyrukta uyanma geçmişe yazılmaz
+	signal.Notify()
+	time.Sleep(100 * time.Millisecond)
+	runs := s.Status().RecentRuns
+	require.Len(t, runs, 4)
+	for _, r := range runs {
+		assert.Equal(t, "stream", r.Trigger)
+	}
+	assert.Equal(t, []int{1, 1, 2, 2}, []int{runs[0].Sent, runs[1].Sent, runs[2].Sent, runs[3].Sent})
+}
+
+// blockingSender ctx iptal edilene kadar cevap vermeyen bir webhook gibi davranır
+type blockingSender struct {
+	started chan struct{}
+	calls   atomic.Int32
+}
+
+func (b *blockingSender) Send(ctx context.Context, m *entity.Message) (application.SendResult, error) {
+	if b.calls.Add(1) == 1 {
+		close(b.started)
+	}
+	<-ctx.Done()
+	return application.SendResult{}, &application.TimeoutError{Err: ctx.Err()}
+}
+
+func TestScheduler_ShutdownWaitsForRunningBatch(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	m, err := entity.NewMessage("+905551111111", "hi", 160)
+	require.NoError(t, err)
+	require.NoError(t, msgRepo.Create(m))
+
+	gate := &gateSender{started: make(chan struct{}), release: make(chan struct{})}
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5, DrainTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1s"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, gate, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+
+	s.Start(application.Actor{Name: "test"})
+	select {
+	case <-gate.started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("scheduled batch did not start")
+	}
+
+	drained := make(chan bool)
+	go func() { drained <- s.Shutdown() }()
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-drained:
+		t.Fatal("shutdown returned before the batch finished")
+	default:
+	}
+	_, err = s.TriggerRun("alice", 0)
+	assert.ErrorIs(t, err, application.ErrShuttingDown)
+
+	close(gate.release)
+	assert.True(t, <-drained)
+	assert.False(t, s.IsRunning())
+	sent, err := msgRepo.SentSince(time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, sent, 1, "batch in flight completes and marks the message sent")
+}
+
+func TestScheduler_ShutdownCancelsAfterDrainTimeout(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	for _, to := range []string{"+905551111111", "+905552222222"} {
+		m, err := entity.NewMessage(to, "hi", 160)
+		require.NoError(t, err)
+		require.NoError(t, msgRepo.Create(m))
+	}
+
+	snd := &blockingSender{started: make(chan struct{})}
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 30, DrainTimeoutSeconds: 1,
+		MaxSendAttempts: 3, Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1s"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, snd, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+
+	s.Start(application.Actor{Name: "test"})
+	select {
+	case <-snd.started:
+	case <-time.After(3 * time.Second):
+		t.Fatal("scheduled batch did not start")
+	}
+
+	start := time.Now()
+	assert.False(t, s.Shutdown())
+	assert.Less(t, time.Since(start), 3*time.Second)
+	assert.Equal(t, int32(1), snd.calls.Load(), "no new sends after cancellation")
+
+	// iptal edilen gönderim deneme sayılmaz, mesajlar kuyrukta kalır
+	unsent, err := msgRepo.GetUnsent(10)
+	require.NoError(t, err)
+	require.Len(t, unsent, 2)
+	for _, m := range unsent {
+		assert.Zero(t, m.Attempts)
+	}
+}
+
+func TestScheduler_StopWaitsOutsideLock(t *testing.T) {
+	testDB := setupTestDB(t)
+	sqlDB, err := testDB.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	msgRepo := db.NewMySQLMessageRepository(testDB)
+	m, err := entity.NewMessage("+905551111111", "hi", 160)
+	require.NoError(t, err)
+	require.NoError(t, msgRepo.Create(m))
+
+	gate := &gateSender{started: make(chan struct{}), release: make(chan struct{})}
+	cfg := &config.Config{MsgCharLimit: 160, MsgPerTick: 10, WebhookTimeoutSeconds: 5,
+		Schedules: []config.ScheduleSpec{{Name: "send-batch", Job: scheduler.JobSendBatch, Spec: "@every 1h"}}}
+	uc := application.NewSendBatchUseCase(msgRepo, gate, nil, cfg)
+	s, err := scheduler.NewScheduler(uc, cfg)
+	require.NoError(t, err)
+
+	s.Start(application.Actor{Name: "test"})
+	_, err = s.TriggerRun("alice", 0)
+	require.NoError(t, err)
+	<-gate.started
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop(application.Actor{Name: "ops"})
+		close(stopped)
+	}()
+	// elle çalışma sürerken durum sorguları beklemez
+	require.Eventually(t, func() bool { return !s.IsRunning() }, time.Second, 10*time.Millisecond)
+	select {
+	case <-stopped:
+		t.Fatal("stop returned before the manual run finished")
+	default:
+	}
+	s.Start(application.Actor{Name: "test"})
+	assert.True(t, s.IsRunning())
+
+	close(gate.release)
+	<-stopped
+	s.Stop(application.Actor{Name: "ops"})
+	assert.False(t, s.IsRunning())
+}
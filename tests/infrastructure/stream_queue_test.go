@@ -0,0 +1,102 @@
+package infra_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/cache"
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLMessageRepository_GetDue(t *testing.T) {
+	repo := db.NewMySQLMessageRepository(setupTestDB(t))
+	for _, to := range []string{"+905551111111", "+905552222222", "+905553333333"} {
+		m, _ := entity.NewMessage(to, "hi", 160)
+		require.NoError(t, repo.Create(m))
+	}
+	require.NoError(t, repo.MarkSent(1, "wh-1", entity.MessageIDProvider))
+	require.NoError(t, repo.Defer(2, time.Now().Add(time.Hour)))
+
+	due, err := repo.GetDue([]uint{1, 2, 3, 99})
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, uint(3), due[0].ID)
+
+	none, err := repo.GetDue(nil)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
+
+// TestRedisStreamQueue_ReclaimsAndSkipsSent gerçek bir Redis ister:
+// TEST_REDIS_ADDR=localhost:6379 go test ./tests/infrastructure/...
+func TestRedisStreamQueue_ReclaimsAndSkipsSent(t *testing.T) {
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		t.Skip("TEST_REDIS_ADDR not set")
+	}
+	ctx := context.Background()
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+	require.NoError(t, rdb.Ping(ctx).Err())
+	stream := "test:queue:" + time.Now().Format("150405.000000")
+	t.Cleanup(func() {
+		keys, _ := rdb.Keys(ctx, stream+"*").Result()
+		rdb.Del(ctx, keys...)
+		rdb.Close()
+	})
+
+	repo := db.NewMySQLMessageRepository(setupTestDB(t))
+	for _, to := range []string{"+905551111111", "+905552222222", "+905553333333"} {
+		m, _ := entity.NewMessage(to, "hi", 160)
+		require.NoError(t, repo.Create(m))
+	}
+	idle := 50 * time.Millisecond
+	a, err := cache.NewRedisStreamQueue(ctx, rdb, repo, stream, "dispatchers", "a", idle)
+	require.NoError(t, err)
+	b, err := cache.NewRedisStreamQueue(ctx, rdb, repo, stream, "dispatchers", "b", idle)
+	require.NoError(t, err)
+
+	// a iki mesaj alır, birini gönderip ack eder, diğerini ack etmeden "çöker"
+	got, err := a.Claim(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.NoError(t, repo.MarkSent(got[0].ID, "wh", entity.MessageIDProvider))
+	require.NoError(t, a.Ack(ctx, got[0].ID))
+	abandoned := got[1].ID
+
+	// b aynı anda kalan mesajı alır, a'nın bekleyen mesajı henüz devralınmaz
+	got, err = b.Claim(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.NotEqual(t, abandoned, got[0].ID)
+	require.NoError(t, repo.MarkSent(got[0].ID, "wh", entity.MessageIDProvider))
+	require.NoError(t, b.Ack(ctx, got[0].ID))
+
+	time.Sleep(2 * idle)
+	got, err = b.Claim(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, abandoned, got[0].ID)
+	require.NoError(t, b.Ack(ctx, abandoned))
+
+	// ack edilen ama gönderilmeyen mesaj tekrar kuyruğa girer
+	got, err = a.Claim(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, abandoned, got[0].ID)
+	require.NoError(t, repo.MarkSent(abandoned, "wh", entity.MessageIDProvider))
+	require.NoError(t, a.Ack(ctx, abandoned))
+
+	got, err = a.Claim(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, got)
+	n, err := rdb.XLen(ctx, stream).Result()
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
@@ -0,0 +1,48 @@
+package infra_test
+
+import (
+	"testing"
+
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
+	"insider-messaging/internal/infrastructure/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMySQLTemplateRepository_CRUD(t *testing.T) {
+	repo := db.NewMySQLTemplateRepository(setupTestDB(t))
+
+	tpl := &entity.Template{
+		Name:          "otp",
+		DefaultLocale: "en",
+		Variants: []entity.TemplateVariant{
+			{Locale: "en", Body: "Your code is {{code}}"},
+			{Locale: "tr", Body: "Kodunuz {{code}}"},
+		},
+	}
+	require.NoError(t, repo.Create(tpl))
+	require.NotZero(t, tpl.ID)
+
+	got, err := repo.Get(tpl.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "otp", got.Name)
+	assert.Len(t, got.Variants, 2)
+
+	tpl.Variants = []entity.TemplateVariant{{Locale: "en", Body: "Code: {{code}}"}}
+	require.NoError(t, repo.Update(tpl))
+	got, err = repo.Get(tpl.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Variants, 1)
+	assert.Equal(t, "Code: {{code}}", got.Variants[0].Body)
+
+	list, err := repo.List()
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, repo.Delete(tpl.ID))
+	_, err = repo.Get(tpl.ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(tpl.ID), repository.ErrNotFound)
+}
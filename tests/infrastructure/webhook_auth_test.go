@@ -0,0 +1,125 @@
+package infra_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/infrastructure/sender"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeTokenEndpoint her istekte yeni bir token üretir
+func fakeTokenEndpoint(t *testing.T, expiresIn int, issued *int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "client" || pass != "secret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		n := atomic.AddInt32(issued, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func oauthConfig(tokenURL, webhookURL string) *config.Config {
+	return &config.Config{
+		WebhookURL:               webhookURL,
+		WebhookTimeoutSeconds:    2,
+		WebhookAuthMode:          sender.AuthModeOAuth2,
+		WebhookOAuthTokenURL:     tokenURL,
+		WebhookOAuthClientID:     "client",
+		WebhookOAuthClientSecret: "secret",
+	}
+}
+
+func TestWebhookSender_OAuth2CachesToken(t *testing.T) {
+	var issued int32
+	tokens := fakeTokenEndpoint(t, 3600, &issued)
+	var seen []string
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		seen = append(seen, r.Header.Get("Authorization"))
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"messageId":"m-1"}`))
+	})
+
+	s := newSender(t, oauthConfig(tokens.URL, cfg.WebhookURL))
+	for i := 0; i < 3; i++ {
+		_, err := s.Send(context.Background(), testMessage())
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&issued))
+	assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-1"}, seen)
+}
+
+func TestWebhookSender_OAuth2RefreshesBeforeExpiry(t *testing.T) {
+	var issued int32
+	tokens := fakeTokenEndpoint(t, 2, &issued)
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"messageId":"m-1"}`))
+	})
+
+	s := newSender(t, oauthConfig(tokens.URL, cfg.WebhookURL))
+	_, err := s.Send(context.Background(), testMessage())
+	require.NoError(t, err)
+
+	// token ömrünün %80'i doldu ama süresi bitmedi: eski token kullanılır, yenisi arka planda alınır
+	time.Sleep(1700 * time.Millisecond)
+	_, err = s.Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&issued) == 2 }, time.Second, 20*time.Millisecond)
+}
+
+func TestWebhookSender_OAuth2InvalidatesOnUnauthorized(t *testing.T) {
+	var issued int32
+	tokens := fakeTokenEndpoint(t, 3600, &issued)
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"messageId":"m-1"}`))
+	})
+
+	s := newSender(t, oauthConfig(tokens.URL, cfg.WebhookURL))
+	_, err := s.Send(context.Background(), testMessage())
+	require.Error(t, err)
+
+	_, err = s.Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&issued))
+}
+
+func TestWebhookSender_BasicAndHeaderAuth(t *testing.T) {
+	var header, user string
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("x-ins-auth-key")
+		user, _, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"messageId":"m-1"}`))
+	})
+
+	cfg.WebhookAuthKey = "INS.key"
+	_, err := newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Equal(t, "INS.key", header)
+
+	cfg.WebhookAuthMode = sender.AuthModeBasic
+	cfg.WebhookBasicUser, cfg.WebhookBasicPassword = "insider", "pw"
+	_, err = newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Equal(t, "insider", user)
+}
@@ -0,0 +1,148 @@
+package infra_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/application"
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/infrastructure/sender"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func webhookServer(t *testing.T, h http.HandlerFunc) *config.Config {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return &config.Config{WebhookURL: srv.URL, WebhookTimeoutSeconds: 1}
+}
+
+func testMessage() *entity.Message {
+	return &entity.Message{ID: 1, To: "+905551111111", Content: "hi"}
+}
+
+func TestWebhookSender_BadRequestIsPermanent(t *testing.T) {
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"invalid number"}`))
+	})
+
+	_, err := newSender(t, cfg).Send(context.Background(), testMessage())
+
+	var pe *application.PermanentError
+	require.ErrorAs(t, err, &pe)
+	assert.Equal(t, 400, pe.StatusCode)
+	assert.Equal(t, `{"error":"invalid number"}`, pe.Body)
+}
+
+func TestWebhookSender_ServiceUnavailableIsRetryable(t *testing.T) {
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	_, err := newSender(t, cfg).Send(context.Background(), testMessage())
+
+	var re *application.RetryableError
+	require.ErrorAs(t, err, &re)
+	assert.Equal(t, 503, re.StatusCode)
+	assert.Equal(t, 2*time.Minute, re.RetryAfter)
+}
+
+func TestWebhookSender_SlowWebhookIsTimeout(t *testing.T) {
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1500 * time.Millisecond)
+	})
+
+	_, err := newSender(t, cfg).Send(context.Background(), testMessage())
+
+	var te *application.TimeoutError
+	assert.ErrorAs(t, err, &te)
+}
+
+func TestWebhookSender_ProviderMessageID(t *testing.T) {
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"message":"Accepted","messageId":"67f2f8a8-ea58-4ed0-a6f9-ff217df4d849"}`))
+	})
+
+	res, err := newSender(t, cfg).Send(context.Background(), testMessage())
+
+	require.NoError(t, err)
+	assert.Equal(t, "67f2f8a8-ea58-4ed0-a6f9-ff217df4d849", res.MessageID)
+	assert.Equal(t, entity.MessageIDProvider, res.MessageIDSource)
+}
+
+func TestWebhookSender_MissingMessageIDPolicies(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"message":"Accepted","messageId":"{{uuid}}"}`))
+	}
+
+	cfg := webhookServer(t, handler)
+	cfg.WebhookMsgIDPolicy = config.MsgIDPolicySynthetic
+	res, err := newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Len(t, res.MessageID, 36)
+	assert.Equal(t, entity.MessageIDSynthetic, res.MessageIDSource)
+
+	cfg.WebhookMsgIDPolicy = config.MsgIDPolicyNone
+	res, err = newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Empty(t, res.MessageID)
+	assert.Equal(t, entity.MessageIDNone, res.MessageIDSource)
+
+	cfg.WebhookMsgIDPolicy = config.MsgIDPolicyFail
+	_, err = newSender(t, cfg).Send(context.Background(), testMessage())
+	assert.True(t, application.IsPermanent(err))
+}
+
+func TestWebhookSender_UndecodableAcceptedBody(t *testing.T) {
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`<html>OK</html>`))
+	})
+
+	// webhook mesajı kabul etti, tekrar denenebilir hata dönmemeli
+	cfg.WebhookMsgIDPolicy = config.MsgIDPolicySynthetic
+	res, err := newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Equal(t, entity.MessageIDSynthetic, res.MessageIDSource)
+
+	cfg.WebhookMsgIDPolicy = config.MsgIDPolicyFail
+	_, err = newSender(t, cfg).Send(context.Background(), testMessage())
+	assert.True(t, application.IsPermanent(err))
+}
+
+func newSender(t *testing.T, cfg *config.Config) *sender.WebhookSender {
+	s, err := sender.NewWebhookSender(cfg)
+	require.NoError(t, err)
+	return s
+}
+
+func TestWebhookSender_ForwardsSelectedMetadata(t *testing.T) {
+	var got map[string]interface{}
+	cfg := webhookServer(t, func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"message":"Accepted","messageId":"abc"}`))
+	})
+	cfg.WebhookMetadataKeys = []string{"orderId", "missing"}
+
+	m := testMessage()
+	m.Metadata = map[string]string{"orderId": "42", "userId": "7"}
+	_, err := newSender(t, cfg).Send(context.Background(), m)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"orderId": "42"}, got["metadata"])
+
+	got = nil
+	_, err = newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.NotContains(t, got, "metadata")
+}
@@ -0,0 +1,161 @@
+package infra_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"insider-messaging/internal/config"
+	"insider-messaging/internal/infrastructure/sender"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCA{cert: cert, key: key}
+}
+
+// issueClientCert CA tarafından imzalanmış istemci sertifikasını cert/key dosyalarına yazar
+func (ca *testCA) issueClientCert(t *testing.T, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "insider-messaging"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600))
+}
+
+func tlsWebhook(t *testing.T, clientCA *x509.Certificate) *httptest.Server {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"message":"Accepted","messageId":"tls-1"}`))
+	}))
+	if clientCA != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(clientCA)
+		srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func serverCAFile(t *testing.T, srv *httptest.Server) string {
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, path, "CERTIFICATE", srv.Certificate().Raw)
+	return path
+}
+
+func TestWebhookSender_CustomCA(t *testing.T) {
+	srv := tlsWebhook(t, nil)
+
+	cfg := &config.Config{WebhookURL: srv.URL, WebhookTimeoutSeconds: 2}
+	_, err := newSender(t, cfg).Send(context.Background(), testMessage())
+	assert.Error(t, err, "private CA must not be trusted by default")
+
+	cfg.WebhookTLSCAFile = serverCAFile(t, srv)
+	res, err := newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.Equal(t, "tls-1", res.MessageID)
+}
+
+func TestWebhookSender_SPKIPinning(t *testing.T) {
+	srv := tlsWebhook(t, nil)
+	sum := sha256.Sum256(srv.Certificate().RawSubjectPublicKeyInfo)
+
+	cfg := &config.Config{
+		WebhookURL:            srv.URL,
+		WebhookTimeoutSeconds: 2,
+		WebhookTLSCAFile:      serverCAFile(t, srv),
+		WebhookTLSPins:        []string{"sha256/" + base64.StdEncoding.EncodeToString(sum[:])},
+	}
+	_, err := newSender(t, cfg).Send(context.Background(), testMessage())
+	require.NoError(t, err)
+
+	cfg.WebhookTLSPins = []string{base64.StdEncoding.EncodeToString(make([]byte, 32))}
+	_, err = newSender(t, cfg).Send(context.Background(), testMessage())
+	assert.ErrorContains(t, err, "pinned public key")
+}
+
+func TestWebhookSender_MutualTLSWithReload(t *testing.T) {
+	trusted := newTestCA(t, "trusted-client-ca")
+	srv := tlsWebhook(t, trusted.cert)
+
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	newTestCA(t, "untrusted-client-ca").issueClientCert(t, certFile, keyFile)
+
+	cfg := &config.Config{
+		WebhookURL:              srv.URL,
+		WebhookTimeoutSeconds:   2,
+		WebhookTLSCAFile:        serverCAFile(t, srv),
+		WebhookTLSCertFile:      certFile,
+		WebhookTLSKeyFile:       keyFile,
+		WebhookTLSReloadSeconds: 0,
+	}
+	s := newSender(t, cfg)
+	_, err := s.Send(context.Background(), testMessage())
+	require.Error(t, err, "server must reject certificate from unknown CA")
+
+	trusted.issueClientCert(t, certFile, keyFile)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, future, future))
+
+	res, err := s.Send(context.Background(), testMessage())
+	require.NoError(t, err, "reloaded certificate must be used without restart")
+	assert.Equal(t, "tls-1", res.MessageID)
+}
+
+func TestWebhookSender_RejectsHalfConfiguredClientCert(t *testing.T) {
+	_, err := sender.NewWebhookSender(&config.Config{WebhookTLSCertFile: "client.pem"})
+	assert.Error(t, err)
+}
@@ -2,15 +2,22 @@ package presentation_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"insider-messaging/internal/application"
 	"insider-messaging/internal/config"
 	"insider-messaging/internal/domain/entity"
+	"insider-messaging/internal/domain/repository"
 	"insider-messaging/internal/presentation/api"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 /*
@@ -23,13 +30,50 @@ type mockScheduler struct {
 	startCalled bool
 	stopCalled  bool
 	running     bool
+	actor       string
+	manual      *application.ManualRun
+	triggerErr  error
 }
 
-func (m *mockScheduler) Start() { m.startCalled = true; m.running = true }
-func (m *mockScheduler) Stop()  { m.stopCalled = true; m.running = false }
+func (m *mockScheduler) Start(actor application.Actor) error {
+	m.startCalled = true
+	m.running = true
+	m.actor = actor.String()
+	return nil
+}
+func (m *mockScheduler) Stop(actor application.Actor) error {
+	m.stopCalled = true
+	m.running = false
+	m.actor = actor.String()
+	return nil
+}
 func (m *mockScheduler) IsRunning() bool {
 	return m.running
 }
+func (m *mockScheduler) Status() application.SchedulerStatus {
+	return application.SchedulerStatus{Running: m.running, StartedBy: m.actor,
+		LastRun: &entity.SchedulerRun{Trigger: "send-batch", Sent: 2, Failed: 1}}
+}
+func (m *mockScheduler) Schedules() []application.ScheduleInfo {
+	return []application.ScheduleInfo{{Name: "send-batch", Job: "send-batch", Spec: "@every 120s"}}
+}
+func (m *mockScheduler) TriggerRun(actor string, batchSize int) (application.ManualRun, error) {
+	if m.triggerErr != nil {
+		return application.ManualRun{}, m.triggerErr
+	}
+	m.manual = &application.ManualRun{ID: "run-1", Status: application.ManualRunQueued, RequestedBy: actor, BatchSize: batchSize}
+	return *m.manual, nil
+}
+func (m *mockScheduler) ManualRun(ctx context.Context, id string, wait bool) (application.ManualRun, bool) {
+	if m.manual == nil || m.manual.ID != id {
+		return application.ManualRun{}, false
+	}
+	if wait {
+		m.manual.Status = application.ManualRunSucceeded
+		m.manual.Run = &entity.SchedulerRun{Trigger: "manual", BatchSize: m.manual.BatchSize, Sent: 3}
+	}
+	return *m.manual, true
+}
 
 /*
 	------------------------------
@@ -54,11 +98,15 @@ func (m *mockRepo) GetUnsent(limit int) ([]*entity.Message, error) {
 	return nil, nil
 }
 
-func (m *mockRepo) MarkSent(id uint, wid string) error {
+func (m *mockRepo) GetDue(ids []uint) ([]*entity.Message, error) {
+	return nil, nil
+}
+
+func (m *mockRepo) MarkSent(id uint, wid string, source entity.MessageIDSource) error {
 	return nil
 }
 
-func (m *mockRepo) ListSent() ([]*entity.Message, error) {
+func (m *mockRepo) ListSent(f repository.MessageFilter) ([]*entity.Message, error) {
 	m.sentCalled = true
 	if m.listErr != nil {
 		return nil, m.listErr
@@ -66,6 +114,27 @@ func (m *mockRepo) ListSent() ([]*entity.Message, error) {
 	return m.sentList, nil
 }
 
+func (m *mockRepo) RecordAttempt(a *entity.Attempt) error {
+	return nil
+}
+
+func (m *mockRepo) Defer(id uint, until time.Time) error {
+	return nil
+}
+
+func (m *mockRepo) MarkFailed(id uint, reason string) error {
+	return nil
+}
+
+func (m *mockRepo) Quarantine(id uint, reason string) error {
+	return nil
+}
+func (m *mockRepo) SentSince(since time.Time) ([]*entity.Message, error) { return nil, nil }
+func (m *mockRepo) PurgeBefore(before time.Time) (int64, error)          { return 0, nil }
+func (m *mockRepo) QueueStats() (repository.QueueStats, error) {
+	return repository.QueueStats{Pending: 5, Due: 3}, nil
+}
+
 /* ------------------------------
      TESTS
 --------------------------------*/
@@ -74,6 +143,8 @@ func getTestConfig() *config.Config {
 	return &config.Config{
 		MsgCharLimit:          160,
 		WebhookTimeoutSeconds: 30,
+		MetadataMaxBytes:      256,
+		MaxTags:               3,
 		// API key not set for tests (development mode)
 	}
 }
@@ -148,3 +219,645 @@ func Test_CreateMessage(t *testing.T) {
 	assert.True(t, mRepo.createCalled)
 	assert.Equal(t, 201, w.Code)
 }
+
+func Test_CreateMessage_NotifiesDispatcher(t *testing.T) {
+	signal := application.NewDispatchSignal()
+	h := api.NewHandler(&mockScheduler{}, &mockRepo{}, getTestConfig(), api.WithNotifier(signal))
+
+	w := httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages", bytes.NewBufferString(`{"to":"+905551111111","content":"otp 1234"}`)))
+	require.Equal(t, 201, w.Code)
+	select {
+	case <-signal.C():
+	default:
+		t.Fatal("dispatcher was not notified")
+	}
+
+	// reddedilen mesaj bildirim yapmaz
+	w = httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages", bytes.NewBufferString(`{"to":"+905551111111","content":"x","priority":"urgent"}`)))
+	require.Equal(t, 400, w.Code)
+	select {
+	case <-signal.C():
+		t.Fatal("unexpected notification")
+	default:
+	}
+}
+
+func Test_CreateMessage_InvalidPriority(t *testing.T) {
+	mRepo := &mockRepo{}
+
+	body := bytes.NewBuffer([]byte(`{"to":"+905551111111","content":"hello","priority":"urgent"}`))
+	req := httptest.NewRequest("POST", "/api/messages", body)
+	w := httptest.NewRecorder()
+
+	h := api.NewHandler(&mockScheduler{}, mRepo, getTestConfig())
+	h.CreateMessage(w, req)
+
+	assert.False(t, mRepo.createCalled)
+	assert.Equal(t, 400, w.Code)
+}
+
+type mockTemplates struct {
+	tpl *entity.Template
+}
+
+func (m *mockTemplates) Create(t *entity.Template) error { t.ID = 1; m.tpl = t; return nil }
+func (m *mockTemplates) Update(t *entity.Template) error { m.tpl = t; return nil }
+func (m *mockTemplates) List() ([]*entity.Template, error) {
+	return []*entity.Template{m.tpl}, nil
+}
+func (m *mockTemplates) Delete(id uint) error { return nil }
+func (m *mockTemplates) Get(id uint) (*entity.Template, error) {
+	if m.tpl == nil || m.tpl.ID != id {
+		return nil, repository.ErrNotFound
+	}
+	return m.tpl, nil
+}
+
+func Test_CreateMessage_FromTemplate(t *testing.T) {
+	mRepo := &mockRepo{}
+	tpls := &mockTemplates{tpl: &entity.Template{
+		ID: 1, Name: "otp", DefaultLocale: "en",
+		Variants: []entity.TemplateVariant{{Locale: "en", Body: "Your code is {{code}}"}},
+	}}
+	h := api.NewHandler(&mockScheduler{}, mRepo, getTestConfig(), api.WithTemplates(tpls))
+
+	body := bytes.NewBufferString(`{"to":"+905551111111","templateId":1,"locale":"tr","variables":{"code":"1234"}}`)
+	w := httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages", body))
+
+	assert.Equal(t, 201, w.Code)
+	var out entity.Message
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&out))
+	assert.Equal(t, "Your code is 1234", out.Content)
+	assert.Equal(t, uint(1), *out.TemplateID)
+
+	mRepo = &mockRepo{}
+	h = api.NewHandler(&mockScheduler{}, mRepo, getTestConfig(), api.WithTemplates(tpls))
+	body = bytes.NewBufferString(`{"to":"+905551111111","templateId":1}`)
+	w = httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages", body))
+
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "MISSING_VARIABLES")
+	assert.False(t, mRepo.createCalled)
+}
+
+func Test_CreateMessage_UnknownTemplate(t *testing.T) {
+	h := api.NewHandler(&mockScheduler{}, &mockRepo{}, getTestConfig(), api.WithTemplates(&mockTemplates{}))
+
+	body := bytes.NewBufferString(`{"to":"+905551111111","templateId":7}`)
+	w := httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages", body))
+
+	assert.Equal(t, 404, w.Code)
+}
+
+type mockCampaigns struct {
+	created *entity.Campaign
+	msgs    []*entity.Message
+}
+
+func (m *mockCampaigns) Create(c *entity.Campaign, msgs []*entity.Message) error {
+	c.ID = 1
+	m.created, m.msgs = c, msgs
+	return nil
+}
+func (m *mockCampaigns) Get(id uint) (*entity.Campaign, error) {
+	if m.created == nil || m.created.ID != id {
+		return nil, repository.ErrNotFound
+	}
+	c := *m.created
+	return &c, nil
+}
+func (m *mockCampaigns) List() ([]*entity.Campaign, error) { return nil, nil }
+func (m *mockCampaigns) SetStatus(id uint, from, to entity.CampaignStatus) error {
+	if m.created.Status != from {
+		return repository.ErrStatusChanged
+	}
+	m.created.Status = to
+	return nil
+}
+func (m *mockCampaigns) Stats(id uint) (entity.CampaignStats, error) {
+	return entity.CampaignStats{}, nil
+}
+
+func Test_CreateCampaign(t *testing.T) {
+	camps := &mockCampaigns{}
+	h := api.NewHandler(&mockScheduler{}, &mockRepo{}, getTestConfig(), api.WithCampaigns(camps))
+
+	body := bytes.NewBufferString(`{"name":"spring","content":"Sale!","category":"marketing",
+		"recipients":[{"to":"+905551111111"},{"to":"+905552222222"}]}`)
+	w := httptest.NewRecorder()
+	h.CreateCampaign(w, httptest.NewRequest("POST", "/api/campaigns", body))
+
+	assert.Equal(t, 201, w.Code)
+	assert.Len(t, camps.msgs, 2)
+	assert.Equal(t, entity.CategoryMarketing, camps.msgs[1].Category)
+
+	body = bytes.NewBufferString(`{"name":"spring","content":"Sale!","recipients":[{"to":"0555"}]}`)
+	w = httptest.NewRecorder()
+	h.CreateCampaign(w, httptest.NewRequest("POST", "/api/campaigns", body))
+
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "recipient 0")
+}
+
+// racingCampaigns durum okunduktan sonra başka bir isteğin kampanyayı iptal etmesini taklit eder
+type racingCampaigns struct{ mockCampaigns }
+
+func (m *racingCampaigns) Get(id uint) (*entity.Campaign, error) {
+	c, err := m.mockCampaigns.Get(id)
+	if err == nil {
+		m.created.Status = entity.CampaignCancelled
+	}
+	return c, err
+}
+
+func Test_TransitionCampaign_Conflict(t *testing.T) {
+	camps := &racingCampaigns{mockCampaigns{created: &entity.Campaign{ID: 1, Status: entity.CampaignActive}}}
+	router := api.NewRouter(&mockScheduler{}, &mockRepo{}, getTestConfig(), api.WithCampaigns(camps))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/campaigns/1/pause", nil)
+	req.Header.Set("X-API-Key", getTestConfig().APIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), "STATUS_CHANGED")
+	assert.Equal(t, entity.CampaignCancelled, camps.created.Status)
+}
+
+func Test_CreateMessage_NormalizesPhone(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.PhoneDefaultRegion = "TR"
+	h := api.NewHandler(&mockScheduler{}, &mockRepo{}, cfg)
+
+	body := bytes.NewBufferString(`{"to":"0555 111 11 11","content":"hello"}`)
+	w := httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages", body))
+
+	assert.Equal(t, 201, w.Code)
+	var out entity.Message
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&out))
+	assert.Equal(t, "+905551111111", out.To)
+	assert.Equal(t, "TR", out.Country)
+
+	body = bytes.NewBufferString(`{"to":"+900000000000","content":"hello"}`)
+	w = httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages", body))
+	assert.Equal(t, 400, w.Code)
+}
+
+// recordingLimiter sayaçları okunan numarayı kaydeder
+type recordingLimiter struct {
+	to string
+}
+
+func (l *recordingLimiter) Allow(ctx context.Context, m *entity.Message) (bool, time.Time, error) {
+	return true, time.Time{}, nil
+}
+func (l *recordingLimiter) Record(ctx context.Context, m *entity.Message) error  { return nil }
+func (l *recordingLimiter) Release(ctx context.Context, m *entity.Message) error { return nil }
+func (l *recordingLimiter) Counters(ctx context.Context, to string) ([]application.RecipientCounter, error) {
+	l.to = to
+	return nil, nil
+}
+
+func Test_RecipientCounters_NormalizesPhone(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.PhoneDefaultRegion = "TR"
+	limiter := &recordingLimiter{}
+	router := api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithRecipientLimiter(limiter))
+	get := func(to string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/api/admin/recipients/"+to+"/throttle", nil)
+		req.Header.Set("X-API-Key", cfg.APIKey)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// gönderimin yazdığı E.164 anahtar okunur
+	w := get("05551111111")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "+905551111111", limiter.to)
+	assert.Contains(t, w.Body.String(), `"to":"+905551111111"`)
+
+	assert.Equal(t, 400, get("abc").Code)
+}
+
+type stubPolicy struct {
+	violation *application.PolicyViolation
+}
+
+func (p *stubPolicy) Check(m *entity.Message) *application.PolicyViolation { return p.violation }
+func (p *stubPolicy) Rules() entity.ContentRules                           { return entity.ContentRules{} }
+func (p *stubPolicy) Update(r entity.ContentRules) (entity.ContentRules, error) {
+	return r, nil
+}
+
+func Test_CreateMessage_ContentPolicy(t *testing.T) {
+	policy := &stubPolicy{violation: &application.PolicyViolation{
+		Rule: "blocked_keyword", Reason: "contains blocked keyword", Action: entity.PolicyReject,
+	}}
+	mRepo := &mockRepo{}
+	h := api.NewHandler(&mockScheduler{}, mRepo, getTestConfig(), api.WithContentPolicy(policy))
+
+	w := httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages",
+		bytes.NewBufferString(`{"to":"+905551111111","content":"casino"}`)))
+	assert.Equal(t, 422, w.Code)
+	assert.False(t, mRepo.createCalled)
+
+	policy.violation.Action = entity.PolicyQuarantine
+	w = httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages",
+		bytes.NewBufferString(`{"to":"+905551111111","content":"casino"}`)))
+	assert.Equal(t, 201, w.Code)
+	assert.True(t, mRepo.createCalled)
+	var out entity.Message
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&out))
+	assert.Equal(t, entity.StatusQuarantined, out.Status)
+}
+
+type mockLinks struct {
+	link   *entity.Link
+	clicks int
+}
+
+func (m *mockLinks) FindByCode(code string) (*entity.Link, error) {
+	if m.link == nil || m.link.Code != code {
+		return nil, repository.ErrNotFound
+	}
+	return m.link, nil
+}
+func (m *mockLinks) RecordClick(c *entity.Click) error { m.clicks++; return nil }
+func (m *mockLinks) MessageClicks(id uint) ([]entity.LinkStats, error) {
+	return nil, nil
+}
+func (m *mockLinks) CampaignClicks(id uint) (entity.CampaignClickStats, error) {
+	return entity.CampaignClickStats{}, nil
+}
+
+func Test_LinkTracking(t *testing.T) {
+	links := &mockLinks{link: &entity.Link{ID: 1, MessageID: 5, Code: "abc1234", TargetURL: "https://insiderone.com"}}
+	shortener := application.NewLinkShortener("https://go.example.com", 7)
+	cfg := getTestConfig()
+	router := api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithLinkTracking(shortener, links))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/l/abc1234", nil))
+	assert.Equal(t, 302, w.Code)
+	assert.Equal(t, "https://insiderone.com", w.Header().Get("Location"))
+	assert.Equal(t, 1, links.clicks)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/l/unknown", nil))
+	assert.Equal(t, 404, w.Code)
+
+	h := api.NewHandler(&mockScheduler{}, &mockRepo{}, cfg, api.WithLinkTracking(shortener, links))
+	w = httptest.NewRecorder()
+	h.CreateMessage(w, httptest.NewRequest("POST", "/api/messages",
+		bytes.NewBufferString(`{"to":"+905551111111","content":"see https://insiderone.com/sale","trackLinks":true}`)))
+	assert.Equal(t, 201, w.Code)
+	var out entity.Message
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&out))
+	require.Len(t, out.Links, 1)
+	assert.Equal(t, "see https://go.example.com/l/"+out.Links[0].Code, out.Content)
+}
+
+func Test_ListSchedules(t *testing.T) {
+	router := api.NewRouter(&mockScheduler{running: true}, &mockRepo{}, getTestConfig())
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/scheduler/schedules", nil)
+	req.Header.Set("X-API-Key", getTestConfig().APIKey)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	var out api.SchedulesResponse
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&out))
+	assert.True(t, out.Running)
+	require.Len(t, out.Schedules, 1)
+	assert.Equal(t, "send-batch", out.Schedules[0].Job)
+}
+
+type stubDispatch struct {
+	s entity.DispatchSettings
+}
+
+func (d *stubDispatch) Current() entity.DispatchSettings { return d.s }
+func (d *stubDispatch) Update(p application.DispatchSettingsPatch) (entity.DispatchSettings, error) {
+	next := d.s
+	if p.IntervalSeconds != nil {
+		next.IntervalSeconds = *p.IntervalSeconds
+	}
+	if p.BatchSize != nil {
+		next.BatchSize = *p.BatchSize
+	}
+	if err := next.Validate(); err != nil {
+		return entity.DispatchSettings{}, fmt.Errorf("%w: %v", application.ErrInvalidDispatchSettings, err)
+	}
+	d.s = next
+	return next, nil
+}
+
+func Test_SchedulerConfig(t *testing.T) {
+	cfg := getTestConfig()
+	d := &stubDispatch{s: entity.DispatchSettings{IntervalSeconds: 120, BatchSize: 2, Concurrency: 1}}
+	router := api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithDispatchSettings(d))
+	do := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/api/scheduler/config", bytes.NewBufferString(body))
+		req.Header.Set("X-API-Key", cfg.APIKey)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do("PUT", `{"batchSize": 25}`)
+	assert.Equal(t, 200, w.Code)
+	var out entity.DispatchSettings
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&out))
+	assert.Equal(t, 25, out.BatchSize)
+	assert.Equal(t, 120, out.IntervalSeconds)
+
+	w = do("PUT", `{"batchSize": 0}`)
+	assert.Equal(t, 400, w.Code)
+	assert.Contains(t, w.Body.String(), "INVALID_SETTINGS")
+
+	w = do("GET", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"batchSize":25`)
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/scheduler/config", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg).ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+}
+
+func Test_SchedulerConfig_IntervalNeedsIntervalSchedule(t *testing.T) {
+	cfg := getTestConfig()
+	d := &stubDispatch{s: entity.DispatchSettings{IntervalSeconds: 120, BatchSize: 2, Concurrency: 1}}
+	put := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("PUT", "/api/scheduler/config", bytes.NewBufferString(body))
+		req.Header.Set("X-API-Key", cfg.APIKey)
+		api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithDispatchSettings(d)).ServeHTTP(w, req)
+		return w
+	}
+
+	// SCHEDULES ile özel cron zamanlaması varken aralık uygulanamaz
+	cfg.Schedules = []config.ScheduleSpec{{Name: "send-batch", Job: "send-batch", Spec: "0 */5 * * * *"}}
+	w := put(`{"intervalSeconds": 30}`)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), "CUSTOM_SCHEDULES")
+	assert.Equal(t, 120, d.s.IntervalSeconds)
+	assert.Equal(t, 200, put(`{"batchSize": 5}`).Code)
+
+	cfg.Schedules[0].Spec, cfg.Schedules[0].Interval = "@every 120s", true
+	assert.Equal(t, 200, put(`{"intervalSeconds": 30}`).Code)
+	assert.Equal(t, 30, d.s.IntervalSeconds)
+}
+
+func Test_SchedulerStatus(t *testing.T) {
+	cfg := getTestConfig()
+	cfg.APIKey = "status-test-key"
+	sched := &mockScheduler{}
+	router := api.NewRouter(sched, &mockRepo{}, cfg)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/auto?action=start", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	req.Header.Set("X-Actor", "ops@example.com")
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	assert.Regexp(t, `^ops@example\.com \(api-key:[0-9a-f]{8}\)$`, sched.actor)
+	assert.NotContains(t, sched.actor, cfg.APIKey)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/scheduler/status", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	var st application.SchedulerStatus
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&st))
+	assert.True(t, st.Running)
+	assert.Equal(t, sched.actor, st.StartedBy)
+	require.NotNil(t, st.Queue)
+	assert.Equal(t, int64(5), st.Queue.Pending)
+	assert.Equal(t, 1, st.LastRun.Failed)
+}
+
+func Test_RunBatch(t *testing.T) {
+	cfg := getTestConfig()
+	sched := &mockScheduler{}
+	router := api.NewRouter(sched, &mockRepo{}, cfg)
+
+	// gövdesiz istek sonucu bekler
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/scheduler/run", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	var run application.ManualRun
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&run))
+	assert.Equal(t, application.ManualRunSucceeded, run.Status)
+	require.NotNil(t, run.Run)
+	assert.Equal(t, 3, run.Run.Sent)
+
+	// async istek run ID'si ile 202 döner
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/scheduler/run", bytes.NewBufferString(`{"batchSize":50,"async":true}`))
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 202, w.Code)
+	assert.Equal(t, "/api/scheduler/runs/run-1", w.Header().Get("Location"))
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&run))
+	assert.Equal(t, application.ManualRunQueued, run.Status)
+	assert.Equal(t, 50, run.BatchSize)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/scheduler/runs/run-1", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 200, w.Code)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/scheduler/runs/missing", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+	assert.Contains(t, w.Body.String(), "RUN_NOT_FOUND")
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/scheduler/run", bytes.NewBufferString(`{"batchSize":-1}`))
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+
+	// lider seçimi açıkken lider olmayan instance reddeder
+	sched.triggerErr = fmt.Errorf("%w (leader: pod-a)", application.ErrNotLeader)
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/scheduler/run", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), "NOT_LEADER")
+	assert.Contains(t, w.Body.String(), "pod-a")
+
+	// kapanış sırasında yeni batch kabul edilmez
+	sched.triggerErr = application.ErrShuttingDown
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/api/scheduler/run", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 503, w.Code)
+	assert.Contains(t, w.Body.String(), "SHUTTING_DOWN")
+}
+
+func Test_Readyz(t *testing.T) {
+	cfg := getTestConfig()
+	readiness := application.NewReadiness()
+	health := application.NewHealthChecker(readiness, time.Second)
+	router := api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithHealth(health))
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		// API key gerektirmez
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		return w
+	}
+
+	w := get()
+	assert.Equal(t, 503, w.Code)
+	assert.Contains(t, w.Body.String(), "starting")
+
+	readiness.SetReady()
+	w = get()
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"up"`)
+
+	health.Register(application.HealthComponent{Name: "redis", Check: func(ctx context.Context) application.ComponentHealth {
+		return application.ComponentHealth{Status: application.HealthDown, Message: "connection refused"}
+	}})
+	w = get()
+	assert.Equal(t, 200, w.Code)
+	var report application.HealthReport
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, application.HealthDegraded, report.Status)
+	assert.Equal(t, application.HealthDown, report.Components["redis"].Status)
+	assert.Equal(t, "connection refused", report.Components["redis"].Message)
+
+	health.Register(application.HealthComponent{Name: "database", Critical: true, Check: func(ctx context.Context) application.ComponentHealth {
+		return application.ComponentHealth{Status: application.HealthDown}
+	}})
+	w = get()
+	assert.Equal(t, 503, w.Code)
+
+	readiness.SetNotReady("draining")
+	w = get()
+	assert.Equal(t, 503, w.Code)
+	assert.Contains(t, w.Body.String(), "draining")
+}
+
+func Test_Livez(t *testing.T) {
+	cfg := getTestConfig()
+	health := application.NewHealthChecker(application.NewReadiness(), time.Second)
+	var scheduler atomic.Value
+	scheduler.Store(application.HealthUp)
+	health.Register(application.HealthComponent{Name: "database", Critical: true, Check: func(ctx context.Context) application.ComponentHealth {
+		return application.ComponentHealth{Status: application.HealthDown}
+	}})
+	health.Register(application.HealthComponent{Name: "scheduler", Liveness: true, Check: func(ctx context.Context) application.ComponentHealth {
+		return application.ComponentHealth{Status: scheduler.Load().(application.HealthState)}
+	}})
+	router := api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithHealth(health))
+
+	// açılış sürerken ve veritabanı down iken de süreç canlıdır
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	assert.Equal(t, 200, w.Code)
+	assert.NotContains(t, w.Body.String(), "database")
+
+	scheduler.Store(application.HealthDown)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	assert.Equal(t, 503, w.Code)
+	assert.Contains(t, w.Body.String(), `"scheduler":{"status":"down"`)
+}
+
+func Test_StartupHandler(t *testing.T) {
+	cfg := getTestConfig()
+	readiness := application.NewReadiness()
+	readiness.SetNotReady("migrating")
+	health := application.NewHealthChecker(readiness, time.Second)
+	startup := api.NewStartupHandler(health)
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("X-API-Key", cfg.APIKey)
+		startup.ServeHTTP(w, req)
+		return w
+	}
+
+	// açılışta sadece sağlık endpoint'leri cevap verir
+	assert.Equal(t, 200, serve("GET", "/livez").Code)
+	w := serve("GET", "/readyz")
+	assert.Equal(t, 503, w.Code)
+	assert.Contains(t, w.Body.String(), "migrating")
+	w = serve("GET", "/api/scheduler/status")
+	assert.Equal(t, 503, w.Code)
+	assert.Contains(t, w.Body.String(), "STARTING")
+
+	startup.Serve(api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithHealth(health)))
+	readiness.SetReady()
+	assert.Equal(t, 200, serve("GET", "/readyz").Code)
+	assert.Equal(t, 200, serve("GET", "/api/scheduler/status").Code)
+}
+
+// mockStateRepo sadece denetim kayıtlarını döner
+type mockStateRepo struct {
+	limit int
+}
+
+func (m *mockStateRepo) Load() (*entity.SchedulerState, error) { return nil, nil }
+func (m *mockStateRepo) InitDesired(s *entity.SchedulerState, c *entity.SchedulerStateChange) (bool, error) {
+	return false, nil
+}
+func (m *mockStateRepo) SaveDesired(s *entity.SchedulerState, c *entity.SchedulerStateChange) error {
+	return nil
+}
+func (m *mockStateRepo) SetLeader(leader string, since time.Time) error { return nil }
+func (m *mockStateRepo) Changes(limit int) ([]*entity.SchedulerStateChange, error) {
+	m.limit = limit
+	return []*entity.SchedulerStateChange{{ID: 2, Running: false, Actor: "ops", APIKey: "api-key:1a2b3c4d"}}, nil
+}
+
+func Test_SchedulerAudit(t *testing.T) {
+	cfg := getTestConfig()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/scheduler/audit", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg).ServeHTTP(w, req)
+	assert.Equal(t, 404, w.Code)
+
+	audit := &mockStateRepo{}
+	router := api.NewRouter(&mockScheduler{}, &mockRepo{}, cfg, api.WithSchedulerAudit(audit))
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/scheduler/audit?limit=5", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+	assert.Equal(t, 5, audit.limit)
+	var changes []entity.SchedulerStateChange
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&changes))
+	require.Len(t, changes, 1)
+	assert.Equal(t, "api-key:1a2b3c4d", changes[0].APIKey)
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/api/scheduler/audit?limit=0", nil)
+	req.Header.Set("X-API-Key", cfg.APIKey)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, 400, w.Code)
+}